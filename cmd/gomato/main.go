@@ -5,20 +5,60 @@ import (
 	"fmt"
 	"os"
 
+	"gomato/pkg/cli"
+	"gomato/pkg/common"
 	"gomato/pkg/gomato"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
 	"gomato/pkg/logging"
+	"gomato/pkg/paths"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	// 读取设置之前的提示使用环境变量中的语言
+	i18n.SetLanguage(i18n.Detect())
+	dataDir, args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if dataDir != "" {
+		paths.SetDir(dataDir)
+	}
+	// 旧版本把所有文件放在 ~/.gomato，首次运行时迁移到 XDG 目录
+	moved, migrateErr := paths.Migrate()
+	// 之后的提示使用设置中的界面语言，设置文件损坏由 cli.Run 或 TUI 提示
+	settings, _ := common.LoadSettings()
+	i18n.SetLanguage(settings.Language)
 	if err := logging.Init(); err != nil {
-		fmt.Println("日志系统初始化失败:", err)
+		fmt.Println(i18n.T("main.logFailed", err))
+		os.Exit(1)
+	}
+	if migrateErr != nil {
+		logging.Log(fmt.Sprintf("[Paths] 迁移 ~/.gomato 失败，继续使用原目录: %v", migrateErr))
+		fmt.Fprintln(os.Stderr, i18n.T("main.migrateFailed", migrateErr))
+	} else if moved > 0 {
+		logging.Log(fmt.Sprintf("[Paths] 已把 ~/.gomato 中的 %d 个文件迁移到 XDG 目录", moved))
+		fmt.Fprintln(os.Stderr, i18n.T("main.migrated"))
+	}
+	// 带参数时执行子命令，不进入 TUI
+	if len(args) > 0 {
+		os.Exit(cli.Run(args, os.Stdout, os.Stderr))
+	}
+	// 按键文件有误时直接退出，避免带着冲突的按键进入界面
+	path, err := keymap.DefaultPath()
+	if err == nil {
+		err = keymap.Load(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	app := gomato.NewApp()
 	if _, err := tea.NewProgram(app, tea.WithAltScreen()).Run(); err != nil {
-		fmt.Println("运行程序时出错:", err)
+		fmt.Println(i18n.T("main.runFailed", err))
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,150 @@
+// Package cli 实现 gomato 的非交互式子命令。
+//
+// 子命令与 TUI 读写同一份任务、设置和历史文件，便于在脚本或编辑器中使用。
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// usageError 表示命令行参数错误，退出码为 2
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string { return e.msg }
+
+// Run executes the subcommand in args and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	// 输出使用设置中的界面语言；设置无法读取时 LoadSettings 返回默认设置
+	settings, err := common.LoadSettings()
+	i18n.SetLanguage(settings.Language)
+	usage := i18n.T("cli.usage")
+	var corrupt *common.SettingsCorruptError
+	if errors.As(err, &corrupt) {
+		fmt.Fprintln(stderr, i18n.T("cli.settingsCorrupt", corrupt.Path))
+	}
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "add":
+		err = runAdd(args[1:], stdout)
+	case "list", "ls":
+		err = runList(args[1:], stdout)
+	case "lists":
+		err = runLists(args[1:], stdout)
+	case "rm", "remove":
+		err = runRemove(args[1:], stdout)
+	case "done":
+		err = runDone(args[1:], stdout)
+	case "start":
+		err = runStart(args[1:], stdout)
+	case "pause", "resume", "skip", "reset":
+		err = runControl(args[0], args[1:], stdout)
+	case "daemon":
+		err = runDaemon(args[1:], stdout)
+	case "status":
+		err = runStatus(args[1:], stdout)
+	case "stats":
+		err = runStats(args[1:], stdout)
+	case "config":
+		err = runConfig(args[1:], stdout)
+	case "paths":
+		err = runPaths(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		err = usageError{i18n.T("cli.unknownCommand", args[0])}
+	}
+
+	if err == nil {
+		return 0
+	}
+	var ue usageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(stderr, "%v\n\n%s", err, usage)
+		return 2
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	fmt.Fprintln(stderr, i18n.T("cli.error"), err)
+	return 1
+}
+
+// loadSettings 读取设置。设置文件损坏时使用默认设置且不修改文件，
+// Run 已经提示过用户，只读的命令不因此失败
+func loadSettings() (common.Settings, error) {
+	settings, err := common.LoadSettings()
+	var corrupt *common.SettingsCorruptError
+	if errors.As(err, &corrupt) {
+		return settings, nil
+	}
+	return settings, err
+}
+
+// parseFlags parses fs allowing flags and positional arguments to be mixed,
+// so that "gomato add 标题 --json" works as well as "gomato add --json 标题".
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError{err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// pad 在 s 后补空格，使它在终端中占 width 列，用于对齐中文和英文的标签
+func pad(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// labelWidth 返回 labels 中最宽的一个所占的列数加上两列间隔
+func labelWidth(labels ...string) int {
+	width := 0
+	for _, l := range labels {
+		width = max(width, lipgloss.Width(l))
+	}
+	return width + 2
+}
+
+// joinArgs 把剩余的位置参数拼成一个字符串，方便不加引号输入标题
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFlagsMixedPositional(t *testing.T) {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	desc := fs.String("d", "", "")
+	asJSON := fs.Bool("json", false, "")
+
+	rest, err := parseFlags(fs, []string{"写", "报告", "--json", "-d", "周五前", "--", "-x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"写", "报告", "-x"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("positional = %q, want %q", rest, want)
+	}
+	if *desc != "周五前" || !*asJSON {
+		t.Errorf("flags = %q, %v", *desc, *asJSON)
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"bogus"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if stderr.Len() == 0 {
+		t.Error("expected usage on stderr")
+	}
+}
+
+func TestConfigGetSet(t *testing.T) {
+	s := common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4, TimeDisplayMode: "ansi", Language: "zh", Theme: "default", DigitFont: "standard"}
+
+	v, err := getSetting(s, "pomodoro")
+	if err != nil || v != float64(25) {
+		t.Fatalf("get pomodoro = %v, %v", v, err)
+	}
+	if _, err := getSetting(s, "nope"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+
+	s, err = setSetting(s, "pomodoro", "50")
+	if err != nil || s.Pomodoro != 50 {
+		t.Fatalf("set pomodoro: %+v, %v", s, err)
+	}
+	s, err = setSetting(s, "timeDisplayMode", "normal")
+	if err != nil || s.TimeDisplayMode != "normal" {
+		t.Fatalf("set timeDisplayMode: %+v, %v", s, err)
+	}
+	if s.Cycle != 4 || s.Language != "zh" {
+		t.Errorf("other settings changed: %+v", s)
+	}
+	if _, err := setSetting(s, "cycle", "many"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+	if _, err := setSetting(s, "nope", "1"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+	// 能解析但超出范围的值也不能写入
+	for _, kv := range [][2]string{{"pomodoro", "0"}, {"cycle", "0"}, {"timeDisplayMode", "foo"}, {"theme", "nope"}, {"notifications.osc", "8"}} {
+		if _, err := setSetting(s, kv[0], kv[1]); err == nil {
+			t.Errorf("config set %s %s should fail", kv[0], kv[1])
+		}
+	}
+
+	// 嵌套的设置用点分隔，数字形式的字符串值也能写入
+	s, err = setSetting(s, "notifications.osc", "9")
+	if err != nil || s.Notifications.OSC != "9" {
+		t.Fatalf("set notifications.osc: %+v, %v", s.Notifications, err)
+	}
+	s, err = setSetting(s, "notifications.bell", "true")
+	if err != nil || !s.Notifications.Bell {
+		t.Fatalf("set notifications.bell: %+v, %v", s.Notifications, err)
+	}
+	if v, err := getSetting(s, "notifications.bell"); err != nil || v != true {
+		t.Errorf("get notifications.bell = %v, %v", v, err)
+	}
+	if _, err := getSetting(s, "pomodoro.x"); err == nil {
+		t.Error("expected error for key below a scalar")
+	}
+}
+
+func TestStatusFormat(t *testing.T) {
+	i18n.SetLanguage("zh")
+	t.Cleanup(func() { i18n.SetLanguage(i18n.Default) })
+	st := statusJSON{Task: "写报告", Type: "work", State: "running", Remaining: 754, Duration: 1500, Cycle: 1, Cycles: 4}
+
+	render, err := newStatusFormatter("{{.Label}} {{.Clock}} {{.Cycle}}/{{.Cycles}}{{if .Paused}} ⏸{{end}} {{.Percent}}%\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := render(st); got != "工作 12:34 1/4 49%" {
+		t.Errorf("template output = %q", got)
+	}
+
+	render, _ = newStatusFormatter("json")
+	got, _ := render(st)
+	want := `{"task":"写报告","type":"work","state":"running","remaining":754,"duration":1500,"cycle":1,"cycles":4}`
+	if got != want {
+		t.Errorf("json output = %s", got)
+	}
+
+	if _, err := newStatusFormatter("{{.Clock"); err == nil {
+		t.Error("expected error for invalid template")
+	}
+	render, _ = newStatusFormatter("{{.Nope}}")
+	if _, err := render(st); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	// 状态栏读取的名称使用设置的界面语言
+	i18n.SetLanguage("en")
+	render, _ = newStatusFormatter("{{.Label}} {{.StateLabel}}")
+	if got, _ := render(st); got != "Work running" {
+		t.Errorf("en template output = %q", got)
+	}
+}
+
+// TestRunLanguage 测试命令行输出使用设置中的界面语言
+func TestRunLanguage(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	t.Cleanup(func() { i18n.SetLanguage(i18n.Default) })
+	run := func(args ...string) string {
+		var stdout, stderr bytes.Buffer
+		Run(args, &stdout, &stderr)
+		return stdout.String() + stderr.String()
+	}
+	run("config", "set", "language", "en")
+	if out := run("list"); out != "No tasks\n" {
+		t.Errorf("list = %q", out)
+	}
+	if out := run("status"); out != "No pomodoro in progress\n" {
+		t.Errorf("status = %q", out)
+	}
+	if out := run("bogus"); !strings.Contains(out, "unknown command: bogus") || !strings.Contains(out, "Usage: gomato") {
+		t.Errorf("bogus = %q", out)
+	}
+	// task 包和守护进程返回的错误也使用设置中的语言
+	if out := run("done", "9"); out != "Error: no task with number 9\n" {
+		t.Errorf("done 9 = %q", out)
+	}
+	if out := run("add", "写报告", "--repeat", "sometimes"); !strings.HasPrefix(out, "unrecognized repeat rule: sometimes\n") {
+		t.Errorf("add --repeat = %q", out)
+	}
+	if out := run("pause"); out != "Error: the daemon is not running, start it with gomato daemon\n" {
+		t.Errorf("pause = %q", out)
+	}
+	run("config", "set", "language", "zh")
+	if out := run("list"); out != "暂无任务\n" {
+		t.Errorf("list = %q", out)
+	}
+}
+
+// TestCorruptSettings 测试设置文件损坏时只读的命令使用默认设置，config set 失败，都不修改文件
+func TestCorruptSettings(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	t.Cleanup(func() { i18n.SetLanguage(i18n.Default) })
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "setting.json")
+	os.WriteFile(path, []byte(`{"pomodoro": 50, "language": "en"`), 0644)
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := Run(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, out, errOut := run("config", "get", "pomodoro")
+	if code != 0 || out != "25\n" || !strings.Contains(errOut, path) {
+		t.Errorf("config get = %d %q %q", code, out, errOut)
+	}
+	if code, out, _ := run("status"); code != 0 || out == "" {
+		t.Errorf("status = %d %q", code, out)
+	}
+	if code, _, _ := run("config", "set", "pomodoro", "30"); code != 1 {
+		t.Errorf("config set 应当失败: %d", code)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"pomodoro": 50, "language": "en"` {
+		t.Errorf("损坏的设置文件被修改: %q", data)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+		t.Errorf("不应移动或备份设置文件: %v", matches)
+	}
+}
+
+// TestListFlag 测试 --list 选择任务列表：add 创建新列表，其他命令要求列表已存在
+func TestListFlag(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := Run(args, &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+
+	if code, out := run("add", "周报", "--list", "work"); code != 0 {
+		t.Fatalf("add --list work: %d %s", code, out)
+	}
+	if code, out := run("add", "写报告"); code != 0 {
+		t.Fatalf("add: %d %s", code, out)
+	}
+	if _, out := run("list", "--list", "work"); !strings.Contains(out, "周报") || strings.Contains(out, "写报告") {
+		t.Errorf("list --list work = %q", out)
+	}
+	if _, out := run("list"); strings.Contains(out, "周报") || !strings.Contains(out, "写报告") {
+		t.Errorf("list = %q", out)
+	}
+	if code, _ := run("done", "1", "--list", "home"); code != 1 {
+		t.Errorf("done --list home 应当因列表不存在而失败，退出码 %d", code)
+	}
+	if code, _ := run("list", "--list", "a/b"); code != 2 {
+		t.Errorf("无效的列表名称应当是参数错误，退出码 %d", code)
+	}
+
+	// 未指定 --list 时使用设置中的当前列表
+	if code, out := run("config", "set", "taskList", "work"); code != 0 {
+		t.Fatalf("config set: %d %s", code, out)
+	}
+	if _, out := run("list"); !strings.Contains(out, "周报") {
+		t.Errorf("当前列表为 work 时 list = %q", out)
+	}
+	if _, out := run("lists"); !strings.Contains(out, "* work") || !strings.Contains(out, "  default") {
+		t.Errorf("lists = %q", out)
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	dir, rest, err := ParseGlobalFlags([]string{"--data-dir", "/tmp/g", "list", "--data-dir", "x"})
+	if err != nil || dir != "/tmp/g" || !reflect.DeepEqual(rest, []string{"list", "--data-dir", "x"}) {
+		t.Errorf("ParseGlobalFlags = %q %q %v", dir, rest, err)
+	}
+	dir, rest, err = ParseGlobalFlags([]string{"-data-dir=/tmp/g"})
+	if err != nil || dir != "/tmp/g" || len(rest) != 0 {
+		t.Errorf("ParseGlobalFlags = %q %q %v", dir, rest, err)
+	}
+	if dir, _, _ := ParseGlobalFlags([]string{"--data-dir", "rel"}); !filepath.IsAbs(dir) {
+		t.Errorf("相对路径应转换为绝对路径: %q", dir)
+	}
+	if _, _, err := ParseGlobalFlags([]string{"--data-dir"}); err == nil {
+		t.Error("缺少目录时应报错")
+	}
+}
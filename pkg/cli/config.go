@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"io"
+	"sort"
+	"strings"
+)
+
+// settingsMap 把设置转换为以 JSON 字段名为键的 map
+func settingsMap(s common.Settings) (map[string]any, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// lookup 按以点分隔的键（例如 notifications.bell）找到所在的 map 和字段名
+func lookup(m map[string]any, key string) (map[string]any, string, error) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts[:len(parts)-1] {
+		sub, ok := m[p].(map[string]any)
+		if !ok {
+			return nil, "", errors.New(i18n.T("cli.config.unknownKey", key))
+		}
+		m = sub
+	}
+	leaf := parts[len(parts)-1]
+	if _, ok := m[leaf]; !ok {
+		return nil, "", errors.New(i18n.T("cli.config.unknownKey", key))
+	}
+	return m, leaf, nil
+}
+
+// flatten 把嵌套的设置展开为以点分隔的键
+func flatten(prefix string, m map[string]any, out map[string]any) {
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			flatten(prefix+k+".", sub, out)
+			continue
+		}
+		out[prefix+k] = v
+	}
+}
+
+// getSetting 返回键对应的设置值
+func getSetting(s common.Settings, key string) (any, error) {
+	m, err := settingsMap(s)
+	if err != nil {
+		return nil, err
+	}
+	parent, leaf, err := lookup(m, key)
+	if err != nil {
+		return nil, err
+	}
+	return parent[leaf], nil
+}
+
+// setSetting 修改一个设置项。值先按 JSON 解析，类型不符时再作为字符串尝试，
+// 仍然不符或超出范围时返回错误。
+func setSetting(s common.Settings, key, value string) (common.Settings, error) {
+	var parsed any
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	for _, v := range []any{parsed, value} {
+		m, err := settingsMap(s)
+		if err != nil {
+			return s, err
+		}
+		parent, leaf, err := lookup(m, key)
+		if err != nil {
+			return s, err
+		}
+		parent[leaf] = v
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			return s, err
+		}
+		var updated common.Settings
+		if err := json.Unmarshal(data, &updated); err == nil {
+			if err := updated.Validate(); err != nil {
+				return s, err
+			}
+			return updated, nil
+		}
+	}
+	return s, errors.New(i18n.T("cli.config.badValue", key, value))
+}
+
+func runConfig(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageError{i18n.T("cli.config.needSub")}
+	}
+	// 设置文件损坏时 get 显示默认设置；set 仍然失败，避免覆盖可以从备份恢复的文件
+	settings, err := common.LoadSettings()
+	if args[0] != "set" {
+		settings, err = loadSettings()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) > 2 {
+			return usageError{i18n.T("cli.config.getUsage")}
+		}
+		if len(args) == 2 {
+			v, err := getSetting(settings, args[1])
+			if err != nil {
+				return err
+			}
+			if *asJSON {
+				return writeJSON(stdout, v)
+			}
+			fmt.Fprintln(stdout, v)
+			return nil
+		}
+		if *asJSON {
+			return writeJSON(stdout, settings)
+		}
+		nested, err := settingsMap(settings)
+		if err != nil {
+			return err
+		}
+		m := map[string]any{}
+		flatten("", nested, m)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(stdout, "%s = %v\n", k, m[k])
+		}
+		return nil
+	case "set":
+		if len(args) != 3 {
+			return usageError{i18n.T("cli.config.setUsage")}
+		}
+		updated, err := setSetting(settings, args[1], args[2])
+		if err != nil {
+			return err
+		}
+		if err := updated.Save(); err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeJSON(stdout, updated)
+		}
+		return nil
+	}
+	return usageError{i18n.T("cli.config.unknownSub", args[0])}
+}
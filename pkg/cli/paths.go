@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ParseGlobalFlags takes the global --data-dir flag from the start of args
+// and returns its value as an absolute path together with the remaining
+// arguments. It applies to the TUI as well as to every subcommand, so it
+// has to be given before the command name.
+func ParseGlobalFlags(args []string) (string, []string, error) {
+	dataDir := ""
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") || name != "data-dir" {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return "", nil, usageError{i18n.T("cli.needDataDir")}
+			}
+			value, args = args[0], args[1:]
+		}
+		if value == "" {
+			return "", nil, usageError{i18n.T("cli.needDataDir")}
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return "", nil, err
+		}
+		dataDir = abs
+	}
+	return dataDir, args, nil
+}
+
+// pathsJSON 是 paths 命令的 JSON 输出
+type pathsJSON struct {
+	Config  string `json:"config"`
+	Data    string `json:"data"`
+	State   string `json:"state"`
+	Runtime string `json:"runtime"`
+}
+
+// runPaths 显示设置、数据、状态和 socket 所在的目录
+func runPaths(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("paths", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var out pathsJSON
+	for _, d := range []struct {
+		dir  *string
+		from func() (string, error)
+	}{
+		{&out.Config, paths.ConfigDir},
+		{&out.Data, paths.DataDir},
+		{&out.State, paths.StateDir},
+		{&out.Runtime, paths.RuntimeDir},
+	} {
+		dir, err := d.from()
+		if err != nil {
+			return err
+		}
+		*d.dir = dir
+	}
+	if *asJSON {
+		return writeJSON(stdout, out)
+	}
+	labels := []string{i18n.T("cli.paths.config"), i18n.T("cli.paths.data"), i18n.T("cli.paths.state"), i18n.T("cli.paths.runtime")}
+	width := labelWidth(labels...)
+	for i, dir := range []string{out.Config, out.Data, out.State, out.Runtime} {
+		fmt.Fprintln(stdout, pad(labels[i], width)+dir)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/task"
+	"io"
+	"time"
+)
+
+// statsJSON 是 stats 命令的 JSON 输出，时长以秒为单位
+type statsJSON struct {
+	Period         string          `json:"period"`
+	List           string          `json:"list,omitempty"` // 为空表示统计所有列表
+	WorkSessions   int             `json:"workSessions"`
+	Abandoned      int             `json:"abandoned"`
+	Breaks         int             `json:"breaks"`
+	FocusSeconds   int             `json:"focusSeconds"`
+	CompletionRate float64         `json:"completionRate"`
+	TasksCompleted int             `json:"tasksCompleted"`
+	TasksOpen      int             `json:"tasksOpen"`
+	TaskRate       float64         `json:"taskCompletionRate"`
+	PerTask        []taskStatsJSON `json:"perTask"`
+	GroupBy        string          `json:"groupBy,omitempty"`
+	Groups         []taskStatsJSON `json:"groups,omitempty"` // 按 GroupBy 分组的统计，-group task 时省略
+}
+
+type taskStatsJSON struct {
+	Task         string `json:"task"`
+	Sessions     int    `json:"sessions"`
+	FocusSeconds int    `json:"focusSeconds"`
+}
+
+func newTaskStatsJSON(ts task.TaskStats) taskStatsJSON {
+	return taskStatsJSON{
+		Task:         ts.Name,
+		Sessions:     ts.Sessions,
+		FocusSeconds: int(ts.FocusTime / time.Second),
+	}
+}
+
+func parseGrouping(s string) (task.Grouping, error) {
+	for _, g := range task.Groupings {
+		if string(g) == s {
+			return g, nil
+		}
+	}
+	return "", usageError{i18n.T("cli.unknownGrouping", s)}
+}
+
+// groupName 返回分组在文本输出中的名称
+func groupName(by task.Grouping, name string) string {
+	if name != "" {
+		switch by {
+		case task.GroupByProject:
+			return "@" + name
+		case task.GroupByTag:
+			return "#" + name
+		}
+		return name
+	}
+	switch by {
+	case task.GroupByProject:
+		return i18n.T("stats.none.project")
+	case task.GroupByTag:
+		return i18n.T("stats.none.tag")
+	case task.GroupByPriority:
+		return i18n.T("stats.none.priority")
+	}
+	return i18n.T("stats.none.task")
+}
+
+// listTasks 返回列表中的任务，list 为空时返回所有列表的任务
+func listTasks(list string) ([]task.Task, error) {
+	names := []string{list}
+	if list == "" {
+		var err error
+		if names, err = task.Lists(); err != nil {
+			return nil, err
+		}
+	}
+	var tasks []task.Task
+	for _, name := range names {
+		m, err := task.NewManager(name)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, m.Tasks...)
+	}
+	return tasks, nil
+}
+
+// periodStart 返回统计范围的起始时间，all 返回零值
+func periodStart(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case "today":
+		return task.StartOfDay(now), nil
+	case "week":
+		return task.StartOfWeek(now), nil
+	case "all":
+		return time.Time{}, nil
+	}
+	return time.Time{}, usageError{i18n.T("cli.unknownPeriod", period)}
+}
+
+func runStats(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	period := fs.String("period", "today", i18n.T("cli.flag.period"))
+	group := fs.String("group", "task", i18n.T("cli.flag.group"))
+	list := fs.String("list", "", i18n.T("cli.flag.statsList"))
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	from, err := periodStart(*period, time.Now())
+	if err != nil {
+		return err
+	}
+	by, err := parseGrouping(*group)
+	if err != nil {
+		return err
+	}
+
+	if *list != "" && !task.ListExists(*list) {
+		return errors.New(i18n.T("cli.noList", *list))
+	}
+
+	history, err := task.NewHistory()
+	if err != nil {
+		return err
+	}
+	sessions, err := history.Load()
+	if err != nil {
+		return err
+	}
+	sessions = task.Since(sessions, from)
+	if *list != "" {
+		sessions = task.InList(sessions, *list)
+	}
+	st := task.Summarize(sessions)
+	groups := st.PerTask
+	if by != task.GroupByTask {
+		groups = task.Group(sessions, by)
+	}
+	tasks, err := listTasks(*list)
+	if err != nil {
+		return err
+	}
+	progress := task.Progress(tasks, from)
+
+	if *asJSON {
+		out := statsJSON{
+			Period:         *period,
+			List:           *list,
+			WorkSessions:   st.WorkSessions,
+			Abandoned:      st.Abandoned,
+			Breaks:         st.Breaks,
+			FocusSeconds:   int(st.FocusTime / time.Second),
+			CompletionRate: st.CompletionRate(),
+			TasksCompleted: progress.Completed,
+			TasksOpen:      progress.Open,
+			TaskRate:       progress.Rate(),
+			PerTask:        []taskStatsJSON{},
+		}
+		for _, ts := range st.PerTask {
+			out.PerTask = append(out.PerTask, newTaskStatsJSON(ts))
+		}
+		if by != task.GroupByTask {
+			out.GroupBy = string(by)
+			out.Groups = []taskStatsJSON{}
+			for _, ts := range groups {
+				out.Groups = append(out.Groups, newTaskStatsJSON(ts))
+			}
+		}
+		return writeJSON(stdout, out)
+	}
+
+	rows := [][2]string{
+		{i18n.T("stats.sessions"), fmt.Sprint(st.WorkSessions)},
+		{i18n.T("stats.focus"), st.FocusTime.Round(time.Minute).String()},
+		{i18n.T("stats.breaks"), fmt.Sprint(st.Breaks)},
+		{i18n.T("stats.abandoned"), fmt.Sprint(st.Abandoned)},
+		{i18n.T("stats.rate"), fmt.Sprintf("%.0f%%", st.CompletionRate()*100)},
+		{i18n.T("stats.tasks"), fmt.Sprintf("%d/%d (%.0f%%)", progress.Completed, progress.Completed+progress.Open, progress.Rate()*100)},
+	}
+	width := 0
+	for _, r := range rows {
+		width = max(width, labelWidth(r[0]))
+	}
+	for _, r := range rows {
+		fmt.Fprintln(stdout, pad(r[0], width)+r[1])
+	}
+	for _, ts := range groups {
+		name := groupName(by, ts.Name)
+		fmt.Fprintf(stdout, "  %s %d  %s\n", pad(name, 20), ts.Sessions, ts.FocusTime.Round(time.Minute))
+	}
+	return nil
+}
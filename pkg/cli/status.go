@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+)
+
+// statusJSON 是 status 命令的输出，也是 --format 模板的数据
+type statusJSON struct {
+	Task      string `json:"task"`
+	Type      string `json:"type"`
+	State     string `json:"state"`     // idle、running 或 paused
+	Remaining int    `json:"remaining"` // 剩余秒数
+	Duration  int    `json:"duration"`  // 计划秒数
+	Cycle     int    `json:"cycle"`     // 本周期已完成的番茄数
+	Cycles    int    `json:"cycles"`    // 每周期的番茄数
+}
+
+// Clock 以 MM:SS 的形式返回剩余时间
+func (s statusJSON) Clock() string {
+	return formatClock(time.Duration(s.Remaining) * time.Second)
+}
+
+// Label 返回会话类型的显示名称
+func (s statusJSON) Label() string {
+	return kindLabel(timer.Kind(s.Type))
+}
+
+// StateLabel 返回运行状态的显示名称
+func (s statusJSON) StateLabel() string {
+	return stateLabel(stateFromString(s.State))
+}
+
+// Running 表示计时器正在运行
+func (s statusJSON) Running() bool {
+	return s.State == timer.StateRunning.String()
+}
+
+// Paused 表示计时器已暂停
+func (s statusJSON) Paused() bool {
+	return s.State == timer.StatePaused.String()
+}
+
+// Percent 返回当前会话已经过的百分比
+func (s statusJSON) Percent() int {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return (s.Duration - s.Remaining) * 100 / s.Duration
+}
+
+// loadStatus 从计时状态快照中读取当前状态。TUI 和守护进程在每次变化时
+// 都会保存快照，运行中的剩余时间按截止时间计算，因此无需连接任何进程。
+func loadStatus(now time.Time) (statusJSON, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return statusJSON{}, err
+	}
+	st := statusJSON{
+		Type:      string(timer.Work),
+		State:     timer.StateIdle.String(),
+		Remaining: int(settings.Pomodoro) * 60,
+		Duration:  int(settings.Pomodoro) * 60,
+		Cycles:    int(settings.Cycle),
+	}
+
+	store, err := task.NewStateStore()
+	if err != nil {
+		return st, err
+	}
+	state, err := store.Load()
+	if err != nil || state == nil || state.TaskName == "" {
+		return st, err
+	}
+	return newStatus(st, state, now), nil
+}
+
+// newStatus 用保存的状态填充 st 中的计时字段
+func newStatus(st statusJSON, state *task.ActiveState, now time.Time) statusJSON {
+	snap := state.Snapshot()
+	st.Task = state.TaskName
+	st.Type = string(snap.Kind)
+	st.State = snap.State.String()
+	st.Remaining = int((snap.RemainingAt(now) + time.Second - 1) / time.Second)
+	st.Duration = int(snap.Planned / time.Second)
+	st.Cycle = snap.Cycle
+	return st
+}
+
+// statusFormatter 把状态格式化为一行输出
+type statusFormatter func(statusJSON) (string, error)
+
+// newStatusFormatter 根据 --format 的值创建格式化函数：
+// 空值为默认的文字输出，json 为单行 JSON，其他值按 text/template 模板解析。
+func newStatusFormatter(format string) (statusFormatter, error) {
+	switch format {
+	case "":
+		return func(st statusJSON) (string, error) {
+			if st.Task == "" {
+				return i18n.T("cli.idle"), nil
+			}
+			return fmt.Sprintf("%s  %s  %s  %s  (%d/%d)",
+				st.Task, st.Label(), st.Clock(), st.StateLabel(), st.Cycle, st.Cycles), nil
+		}, nil
+	case "json":
+		return func(st statusJSON) (string, error) {
+			data, err := json.Marshal(st)
+			return string(data), err
+		}, nil
+	}
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return nil, usageError{i18n.T("cli.badFormat", err)}
+	}
+	return func(st statusJSON) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, st); err != nil {
+			return "", err
+		}
+		// 每个状态占一行，便于状态栏逐行读取
+		return strings.ReplaceAll(strings.TrimRight(buf.String(), "\n"), "\n", " "), nil
+	}, nil
+}
+
+func runStatus(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	format := fs.String("format", "", i18n.T("cli.flag.format"))
+	follow := fs.Bool("follow", false, i18n.T("cli.flag.follow"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *asJSON && !*follow && *format == "" {
+		st, err := loadStatus(time.Now())
+		if err != nil {
+			return err
+		}
+		return writeJSON(stdout, st)
+	}
+	if *asJSON {
+		*format = "json"
+	}
+	render, err := newStatusFormatter(*format)
+	if err != nil {
+		return err
+	}
+	if !*follow {
+		line, err := renderStatus(render)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, line)
+		return nil
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		// 快照可能正被写入，读取失败时等下一次
+		if st, err := loadStatus(time.Now()); err == nil {
+			line, err := render(st)
+			if err != nil {
+				return err
+			}
+			if line != last {
+				if _, err := fmt.Fprintln(stdout, line); err != nil {
+					// 读取端已关闭，例如状态栏重新加载
+					return nil
+				}
+				last = line
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-sig:
+			return nil
+		}
+	}
+}
+
+// renderStatus 读取当前状态并格式化
+func renderStatus(render statusFormatter) (string, error) {
+	st, err := loadStatus(time.Now())
+	if err != nil {
+		return "", err
+	}
+	return render(st)
+}
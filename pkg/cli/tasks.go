@@ -0,0 +1,378 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/task"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// taskJSON 是任务在 --json 输出中的形式
+type taskJSON struct {
+	ID          string         `json:"id"`
+	Index       int            `json:"index"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	State       string         `json:"state"`
+	Remaining   int            `json:"remaining,omitempty"` // 未开始的任务为空
+	Project     string         `json:"project,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Priority    string         `json:"priority,omitempty"`
+	Checklist   []task.Subtask `json:"checklist,omitempty"`
+	Estimate    int            `json:"estimate,omitempty"`
+	Pomodoros   int            `json:"pomodoros"`
+	Done        bool           `json:"done"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+	Due         *time.Time     `json:"due,omitempty"`
+	Scheduled   *time.Time     `json:"scheduled,omitempty"`
+	Overdue     bool           `json:"overdue,omitempty"`
+	Repeat      string         `json:"repeat,omitempty"`
+	Series      string         `json:"series,omitempty"`
+	Next        string         `json:"next,omitempty"` // 完成重复任务后生成的下一次
+}
+
+func newTaskJSON(i int, t task.Task) taskJSON {
+	j := taskJSON{
+		ID:          t.ID,
+		Index:       i + 1,
+		Title:       t.Name,
+		Description: t.Detail,
+		State:       t.Timer.Snapshot(0).State.String(),
+		Remaining:   t.Timer.TimerRemaining,
+		Project:     t.Project,
+		Tags:        t.Tags,
+		Priority:    t.Priority.String(),
+		Checklist:   t.Checklist,
+		Estimate:    t.Estimate,
+		Pomodoros:   t.Pomodoros,
+		Done:        t.Done,
+		Repeat:      t.Recur.String(),
+		Series:      t.Series,
+		Next:        t.Next,
+	}
+	if t.Done {
+		j.CompletedAt = &t.CompletedAt
+	}
+	if !t.Due.IsZero() {
+		j.Due = &t.Due
+		j.Overdue = t.Overdue(time.Now())
+	}
+	if !t.Scheduled.IsZero() {
+		j.Scheduled = &t.Scheduled
+	}
+	return j
+}
+
+func runAdd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	desc := fs.String("d", "", i18n.T("cli.flag.desc"))
+	estimate := fs.Int("e", 0, i18n.T("cli.flag.estimate"))
+	project := fs.String("project", "", i18n.T("cli.flag.project"))
+	tags := fs.String("tags", "", i18n.T("cli.flag.tags"))
+	priority := fs.String("priority", "", i18n.T("cli.flag.priority"))
+	due := fs.String("due", "", i18n.T("cli.flag.due"))
+	scheduled := fs.String("scheduled", "", i18n.T("cli.flag.scheduled"))
+	repeat := fs.String("repeat", "", i18n.T("cli.flag.repeat"))
+	list := listFlag(fs)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	title := joinArgs(rest)
+	if title == "" {
+		return usageError{i18n.T("cli.needTitle")}
+	}
+	if *estimate < 0 {
+		return usageError{i18n.T("cli.negativeEstimate")}
+	}
+	p, err := task.ParsePriority(*priority)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	now := time.Now()
+	dueDate, err := task.ParseDate(*due, now)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	scheduledDate, err := task.ParseDate(*scheduled, now)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	recur, err := task.ParseRecurrence(*repeat)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+
+	m, err := openManager(*list, true)
+	if err != nil {
+		return err
+	}
+	err = m.Add(title, *desc, func(t *task.Task) {
+		t.Estimate = *estimate
+		t.Project = strings.TrimSpace(*project)
+		t.Tags = task.ParseTags(*tags)
+		t.Priority = p
+		t.Due = dueDate
+		t.Scheduled = scheduledDate
+		t.Recur = recur
+	})
+	if err != nil {
+		return err
+	}
+	i := len(m.Tasks) - 1
+	if *asJSON {
+		return writeJSON(stdout, newTaskJSON(i, m.Tasks[i]))
+	}
+	if m.List() != task.DefaultList {
+		fmt.Fprintln(stdout, i18n.T("cli.addedToList", i+1, title, m.List()))
+		return nil
+	}
+	fmt.Fprintln(stdout, i18n.T("cli.added", i+1, title))
+	return nil
+}
+
+func runList(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	sortBy := fs.String("sort", "manual", i18n.T("cli.flag.sort"))
+	filterBy := fs.String("filter", "all", i18n.T("cli.flag.filter"))
+	list := listFlag(fs)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	mode, err := parseSortMode(*sortBy)
+	if err != nil {
+		return err
+	}
+	filter, err := parseDateFilter(*filterBy)
+	if err != nil {
+		return err
+	}
+
+	m, err := openManager(*list, false)
+	if err != nil {
+		return err
+	}
+	// 序号始终是任务在 tasks.json 中的位置，排序和筛选后仍可用于其他命令
+	now := time.Now()
+	var indexes []int
+	for i, t := range m.Tasks {
+		if filter.Match(t, now) {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return task.Less(m.Tasks[indexes[a]], m.Tasks[indexes[b]], mode)
+	})
+	if *asJSON {
+		tasks := make([]taskJSON, len(indexes))
+		for j, i := range indexes {
+			tasks[j] = newTaskJSON(i, m.Tasks[i])
+		}
+		return writeJSON(stdout, tasks)
+	}
+	if len(indexes) == 0 {
+		fmt.Fprintln(stdout, i18n.T("cli.noTasks"))
+		return nil
+	}
+	for _, i := range indexes {
+		t := m.Tasks[i]
+		mark := " "
+		if t.Done {
+			mark = "x"
+		} else if t.Overdue(now) {
+			mark = "!"
+		}
+		fmt.Fprintf(stdout, "%3d  %s  [%s] %s", i+1, t.ID, mark, t.Name)
+		if d := t.Description(); d != "" {
+			fmt.Fprintf(stdout, "  - %s", d)
+		}
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
+
+// listJSON 是任务列表在 lists --json 输出中的形式
+type listJSON struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	Open    int    `json:"open"`
+	Done    int    `json:"done"`
+}
+
+// runLists 列出所有任务列表，当前列表以 * 标出
+func runLists(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("lists", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	current, err := currentList("")
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		current = task.DefaultList
+	}
+	names, err := task.Lists()
+	if err != nil {
+		return err
+	}
+	lists := make([]listJSON, len(names))
+	for i, name := range names {
+		m, err := task.NewManager(name)
+		if err != nil {
+			return err
+		}
+		lists[i] = listJSON{Name: name, Current: name == current}
+		for _, t := range m.Tasks {
+			if t.Done {
+				lists[i].Done++
+			} else {
+				lists[i].Open++
+			}
+		}
+	}
+	if *asJSON {
+		return writeJSON(stdout, lists)
+	}
+	for _, l := range lists {
+		mark := " "
+		if l.Current {
+			mark = "*"
+		}
+		fmt.Fprintf(stdout, "%s %s%s\n", mark, pad(l.Name, 21), i18n.T("cli.listSummary", l.Open, l.Done))
+	}
+	return nil
+}
+
+// listFlag 为命令添加 --list 参数
+func listFlag(fs *flag.FlagSet) *string {
+	return fs.String("list", "", i18n.T("cli.flag.list"))
+}
+
+// currentList 返回 --list 指定的列表，未指定时返回设置中记录的当前列表
+func currentList(list string) (string, error) {
+	if list != "" {
+		return list, nil
+	}
+	settings, err := common.LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	return settings.TaskList, nil
+}
+
+// openManager 打开任务列表。create 为 false 时列表必须已存在，避免拼错名称时静默地读到空列表
+func openManager(list string, create bool) (*task.Manager, error) {
+	list, err := currentList(list)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.ValidateListName(list); list != "" && err != nil {
+		return nil, usageError{err.Error()}
+	}
+	if !create && !task.ListExists(list) {
+		return nil, errors.New(i18n.T("cli.noList", list))
+	}
+	return task.NewManager(list)
+}
+
+// parseSortMode 解析 list 的 --sort 参数
+func parseSortMode(s string) (task.SortMode, error) {
+	mode, err := task.ParseSortMode(s)
+	if err != nil {
+		return mode, usageError{i18n.T("cli.unknownSort", s)}
+	}
+	return mode, nil
+}
+
+// parseDateFilter 解析 list 的 --filter 参数
+func parseDateFilter(s string) (task.DateFilter, error) {
+	for _, f := range task.DateFilters {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return task.FilterAll, usageError{i18n.T("cli.unknownFilter", s)}
+}
+
+func runRemove(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	list := listFlag(fs)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError{i18n.T("cli.needTask", "rm")}
+	}
+
+	m, err := openManager(*list, false)
+	if err != nil {
+		return err
+	}
+	i, err := m.Find(joinArgs(rest))
+	if err != nil {
+		return err
+	}
+	removed := m.Tasks[i]
+	if err := m.Delete(removed.ID); err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(stdout, newTaskJSON(i, removed))
+	}
+	fmt.Fprintln(stdout, i18n.T("cli.removed", removed.Name))
+	return nil
+}
+
+func runDone(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+	list := listFlag(fs)
+	undo := fs.Bool("undo", false, i18n.T("cli.flag.undo"))
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usageError{i18n.T("cli.needTask", "done")}
+	}
+
+	m, err := openManager(*list, false)
+	if err != nil {
+		return err
+	}
+	i, err := m.Find(joinArgs(rest))
+	if err != nil {
+		return err
+	}
+	// 完成或取消完成重复任务时会增删下一次，序号可能变化
+	id := m.Tasks[i].ID
+	if err := m.SetDone(id, !*undo, time.Now()); err != nil {
+		return err
+	}
+	i = m.Index(id)
+	t := m.Tasks[i]
+	if *asJSON {
+		return writeJSON(stdout, newTaskJSON(i, t))
+	}
+	if *undo {
+		fmt.Fprintln(stdout, i18n.T("cli.reopened", t.Name))
+		return nil
+	}
+	fmt.Fprintln(stdout, i18n.T("cli.done", t.Name))
+	if j := m.Index(t.Next); j >= 0 {
+		fmt.Fprintln(stdout, i18n.T("cli.next", j+1, m.Tasks[j].Dates(time.Now())))
+	}
+	return nil
+}
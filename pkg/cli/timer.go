@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gomato/pkg/daemon"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
+	"gomato/pkg/notice"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// kindLabel 返回会话类型的显示名称
+func kindLabel(k timer.Kind) string {
+	switch k {
+	case timer.ShortBreak:
+		return i18n.T("session.shortBreak")
+	case timer.LongBreak:
+		return i18n.T("session.longBreak")
+	default:
+		return i18n.T("session.work")
+	}
+}
+
+// stateLabel 返回运行状态的显示名称
+func stateLabel(s timer.State) string {
+	switch s {
+	case timer.StateRunning:
+		return i18n.T("timer.running")
+	case timer.StatePaused:
+		return i18n.T("timer.paused")
+	default:
+		return i18n.T("timer.idle")
+	}
+}
+
+// formatClock 以 MM:SS 的形式显示剩余时间
+func formatClock(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// runControl 把 pause、resume、skip、reset 等命令发送给守护进程
+func runControl(cmd string, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, i18n.T("cli.flag.json"))
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	c, err := daemon.DialDefault()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	state, err := c.Do(daemon.Request{Cmd: cmd})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(stdout, state)
+	}
+	printState(stdout, state)
+	return nil
+}
+
+// printState 以一行文字显示守护进程返回的状态
+func printState(stdout io.Writer, state *task.ActiveState) {
+	if state == nil {
+		return
+	}
+	snap := state.Snapshot()
+	fmt.Fprintf(stdout, "%s  %s  %s  %s\n",
+		state.TaskName, kindLabel(snap.Kind), formatClock(snap.RemainingAt(time.Now())), stateLabel(snap.State))
+}
+
+// runDaemon 在前台运行守护进程，收到 SIGINT 或 SIGTERM 时保存状态并退出
+func runDaemon(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	path, err := daemon.SocketPath()
+	if err != nil {
+		return err
+	}
+	server, err := daemon.NewServer()
+	if err != nil {
+		return err
+	}
+	l, err := daemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logging.Log(fmt.Sprintf("[Daemon] 守护进程已启动: %s", path))
+	fmt.Fprintln(stdout, i18n.T("cli.daemonStarted", path))
+	return server.Serve(ctx, l)
+}
+
+// stateFromString 是 timer.State.String 的逆操作
+func stateFromString(s string) timer.State {
+	switch s {
+	case timer.StateRunning.String():
+		return timer.StateRunning
+	case timer.StatePaused.String():
+		return timer.StatePaused
+	default:
+		return timer.StateIdle
+	}
+}
+
+// runStart 开始所选任务的当前会话。守护进程运行时交给它在后台计时，
+// 否则在前台运行，直到会话结束或按下 Ctrl+C 暂停。
+func runStart(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("start", flag.ContinueOnError)
+	list := listFlag(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if c, err := daemon.DialDefault(); err == nil {
+		defer c.Close()
+		name, err := currentList(*list)
+		if err != nil {
+			return err
+		}
+		state, err := c.Do(daemon.Request{Cmd: daemon.CmdStart, Task: joinArgs(rest), List: name})
+		if err != nil {
+			return err
+		}
+		printState(stdout, state)
+		return nil
+	}
+	if len(rest) == 0 {
+		return usageError{i18n.T("cli.needTask", "start")}
+	}
+
+	m, err := openManager(*list, false)
+	if err != nil {
+		return err
+	}
+	i, err := m.Find(joinArgs(rest))
+	if err != nil {
+		return err
+	}
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	history, err := task.NewHistory()
+	if err != nil {
+		return err
+	}
+	store, err := task.NewStateStore()
+	if err != nil {
+		return err
+	}
+
+	cycle := 0
+	if state, err := store.Load(); err == nil && state != nil {
+		cycle = state.CycleCount
+	}
+
+	// 命令行模式只运行一个会话，结束后退出
+	cfg := settings.TimerConfig()
+	cfg.AutoStart = false
+	e := timer.New(cfg, nil)
+	e.Restore(m.Tasks[i].Timer.Snapshot(cycle))
+	e.SetConfig(cfg)
+
+	current := m.Tasks[i]
+	id, name := current.ID, current.Name
+	notifier := notice.FromSettings(settings.Notifications, os.Stderr)
+	defer notifier.Wait()
+	done := false
+	pomodoros := 0 // 本次完成的番茄数，保存时累加到任务上
+	e.Subscribe(func(ev timer.Event) {
+		switch ev.Type {
+		case timer.SessionCompleted, timer.Skipped, timer.Reset:
+			if ev.Session.Start.IsZero() {
+				return
+			}
+			s := task.NewSession(current, ev.Session, ev.Type == timer.SessionCompleted)
+			s.List = m.List()
+			if err := history.Append(s); err != nil {
+				logging.Log(fmt.Sprintf("[History] 写入会话记录失败: %v", err))
+			}
+		}
+		if title, msg, ok := notice.ForEvent(settings.Notifications, ev); ok {
+			notifier.Send(title, msg)
+		}
+		if ev.Type == timer.SessionCompleted {
+			if ev.Session.Kind == timer.Work {
+				pomodoros++
+			}
+			done = true
+		}
+	})
+	save := func() {
+		now := time.Now()
+		tm := task.NewTimeModel(e.Snapshot(), now)
+		// 计时期间其他命令可能增删了任务，按 ID 写回
+		if err := m.Load(); err != nil {
+			logging.Log(fmt.Sprintf("[CLI] 读取任务失败: %v", err))
+		}
+		i := m.Index(id)
+		if t, ok := m.Get(id); ok {
+			t.Timer = tm
+			for ; pomodoros > 0; pomodoros-- {
+				t.CountPomodoro(now)
+			}
+			if err := m.Update(t); err != nil {
+				logging.Log(fmt.Sprintf("[CLI] 保存任务失败: %v", err))
+			}
+		}
+		state := task.ActiveState{TaskID: id, TaskIndex: i, TaskName: name, List: m.List(), Timer: tm, CycleCount: e.Cycle(), SavedAt: now}
+		if err := store.Save(state); err != nil {
+			logging.Log(fmt.Sprintf("[State] 保存计时状态失败: %v", err))
+		}
+	}
+
+	kind := e.Kind()
+	e.Start()
+	save()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for !done {
+		fmt.Fprintf(stdout, "\r%s  %s  %s ", name, kindLabel(kind), formatClock(e.Remaining()))
+		select {
+		case <-ticker.C:
+			e.Tick()
+		case <-sig:
+			e.Pause()
+			save()
+			fmt.Fprintln(stdout, "\n"+i18n.T("cli.paused", formatClock(e.Remaining())))
+			return nil
+		}
+	}
+	save()
+	fmt.Fprintf(stdout, "\r%s  %s  %s\n", name, kindLabel(kind), i18n.T("cli.finished", kindLabel(e.Kind())))
+	return nil
+}
@@ -95,36 +95,86 @@ const (
    `
 )
 
+// DigitFonts 是可选的数字字体
+var DigitFonts = []string{"standard", "block", "small"}
+
+// digitFont 是一套数字字形
+type digitFont struct {
+	glyphs map[rune]string
+	height int    // 每个字形的行数，不足的行补空白
+	pad    string // 补齐用的空行
+	sep    string // 字形之间的间距
+}
+
+var digitFontsByName = map[string]digitFont{
+	"standard": {
+		glyphs: map[rune]string{
+			'0': ANSI_0, '1': ANSI_1, '2': ANSI_2, '3': ANSI_3, '4': ANSI_4,
+			'5': ANSI_5, '6': ANSI_6, '7': ANSI_7, '8': ANSI_8, '9': ANSI_9,
+			':': ANSI_COLON,
+		},
+		height: 8,
+		pad:    "          ", // 10个空格
+		sep:    "  ",
+	},
+	"block": {
+		glyphs: blockGlyphs(map[rune][5]string{
+			'0': {"111", "101", "101", "101", "111"},
+			'1': {"010", "110", "010", "010", "111"},
+			'2': {"111", "001", "111", "100", "111"},
+			'3': {"111", "001", "111", "001", "111"},
+			'4': {"101", "101", "111", "001", "001"},
+			'5': {"111", "100", "111", "001", "111"},
+			'6': {"111", "100", "111", "101", "111"},
+			'7': {"111", "001", "001", "001", "001"},
+			'8': {"111", "101", "111", "101", "111"},
+			'9': {"111", "101", "111", "001", "111"},
+			':': {"0", "1", "0", "1", "0"},
+		}),
+		height: 5,
+		sep:    " ",
+	},
+	"small": {
+		glyphs: map[rune]string{
+			'0': " _ \n| |\n|_|", '1': "   \n  |\n  |", '2': " _ \n _|\n|_ ",
+			'3': " _ \n _|\n _|", '4': "   \n|_|\n  |", '5': " _ \n|_ \n _|",
+			'6': " _ \n|_ \n|_|", '7': " _ \n  |\n  |", '8': " _ \n|_|\n|_|",
+			'9': " _ \n|_|\n _|", ':': " \n.\n.",
+		},
+		height: 3,
+		sep:    " ",
+	},
+}
+
+// blockGlyphs 把点阵转换为方块字形，每个点占两列
+func blockGlyphs(bitmaps map[rune][5]string) map[rune]string {
+	glyphs := make(map[rune]string, len(bitmaps))
+	for r, rows := range bitmaps {
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			lines[i] = strings.NewReplacer("1", "██", "0", "  ").Replace(row)
+		}
+		glyphs[r] = strings.Join(lines, "\n")
+	}
+	return glyphs
+}
+
 // TimeToAnsiArt 将时间格式 (MM:SS) 转换为 ANSI 艺术显示
 func TimeToAnsiArt(timeStr string) string {
-	const linesPerDigit = 8
+	return TimeToArt(timeStr, "standard")
+}
+
+// TimeToArt 使用指定字体把时间格式 (MM:SS) 转换为 ANSI 艺术显示，
+// 未知的字体使用 standard
+func TimeToArt(timeStr, font string) string {
+	f, ok := digitFontsByName[font]
+	if !ok {
+		f = digitFontsByName["standard"]
+	}
 	var digits []string
 	for _, char := range timeStr {
-		if char >= '0' && char <= '9' {
-			switch char {
-			case '0':
-				digits = append(digits, ANSI_0)
-			case '1':
-				digits = append(digits, ANSI_1)
-			case '2':
-				digits = append(digits, ANSI_2)
-			case '3':
-				digits = append(digits, ANSI_3)
-			case '4':
-				digits = append(digits, ANSI_4)
-			case '5':
-				digits = append(digits, ANSI_5)
-			case '6':
-				digits = append(digits, ANSI_6)
-			case '7':
-				digits = append(digits, ANSI_7)
-			case '8':
-				digits = append(digits, ANSI_8)
-			case '9':
-				digits = append(digits, ANSI_9)
-			}
-		} else if char == ':' {
-			digits = append(digits, ANSI_COLON)
+		if glyph, ok := f.glyphs[char]; ok {
+			digits = append(digits, glyph)
 		}
 	}
 
@@ -132,25 +182,25 @@ func TimeToAnsiArt(timeStr string) string {
 		return timeStr
 	}
 
-	// 每个数字分割成8行，不足补空行
+	// 每个数字分割成固定行数，不足补空行
 	digitLines := make([][]string, len(digits))
 	for i, digit := range digits {
 		lines := strings.Split(digit, "\n")
-		for len(lines) < linesPerDigit {
-			lines = append(lines, "          ") // 10个空格
+		for len(lines) < f.height {
+			lines = append(lines, f.pad)
 		}
 		digitLines[i] = lines
 	}
 
 	var result strings.Builder
-	for i := 0; i < linesPerDigit; i++ {
+	for i := 0; i < f.height; i++ {
 		if i > 0 {
 			result.WriteString("\n")
 		}
 		for j, lines := range digitLines {
 			result.WriteString(lines[i])
 			if j < len(digitLines)-1 {
-				result.WriteString("  ") // 数字之间的间距
+				result.WriteString(f.sep) // 数字之间的间距
 			}
 		}
 	}
@@ -2,8 +2,17 @@ package common
 
 import (
 	"encoding/json"
+	"errors"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"gomato/pkg/safefile"
+	"gomato/pkg/timer"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Settings struct {
@@ -12,7 +21,38 @@ type Settings struct {
 	LongBreak       uint   `json:"longBreak"`
 	Cycle           uint   `json:"cycle"`
 	TimeDisplayMode string `json:"timeDisplayMode"` // "normal" 或 "ansi"
-	Language        string `json:"language"`        // "zh" 或 "en"
+	Language        string `json:"language"`        // 界面语言，见 i18n.Languages
+
+	Theme     string `json:"theme"`     // 颜色主题，见 Themes
+	DigitFont string `json:"digitFont"` // ANSI 艺术显示的数字字体，见 DigitFonts
+	Compact   bool   `json:"compact"`   // 紧凑模式：减少留白，列表不显示描述
+
+	TaskList  string `json:"taskList"`  // 当前的任务列表，命令行未指定 --list 时也使用它
+	TaskSort  string `json:"taskSort"`  // 任务列表的排序方式，见 task.SortModes
+	TrashDays uint   `json:"trashDays"` // 删除的任务在回收站中保留的天数，0 表示不自动清除
+
+	Notifications NotificationSettings `json:"notifications"`
+}
+
+// NotificationSettings 选择启用哪些通知后端，可以同时启用多个
+type NotificationSettings struct {
+	// 按事件开关通知
+	WorkEnd    bool `json:"workEnd"`    // 工作结束，开始休息
+	BreakEnd   bool `json:"breakEnd"`   // 休息结束，开始工作
+	CycleEnd   bool `json:"cycleEnd"`   // 完成一个周期，进入长休息
+	WarnBefore uint `json:"warnBefore"` // 会话结束前多少分钟提醒，0 表示不提醒
+
+	// 通知后端
+	Desktop   bool   `json:"desktop"`   // 系统桌面通知
+	Sound     bool   `json:"sound"`     // 播放提示音
+	SoundFile string `json:"soundFile"` // 提示音文件，为空时使用系统声音
+	Bell      bool   `json:"bell"`      // 终端响铃
+	OSC       string `json:"osc"`       // 终端通知转义序列: "" 关闭, "9" 或 "777"
+	Command   string `json:"command"`   // 非空时通过 shell 执行，标题和内容在 GOMATO_TITLE、GOMATO_MESSAGE 环境变量中
+	File      string `json:"file"`      // 非空时把通知追加写入该文件
+
+	ChecklistPrompt bool `json:"checklistPrompt"` // 番茄结束时询问完成的是清单中的哪一项
+	DueBefore       uint `json:"dueBefore"`       // 任务截止前多少分钟提醒，0 表示不提醒
 }
 
 var defaultSettings = Settings{
@@ -20,22 +60,127 @@ var defaultSettings = Settings{
 	ShortBreak:      5,
 	LongBreak:       15,
 	Cycle:           4,
-	TimeDisplayMode: "ansi", // 默认使用ANSI艺术显示
-	Language:        "zh",   // 默认中文
+	TimeDisplayMode: "ansi",       // 默认使用ANSI艺术显示
+	Language:        i18n.Default, // 首次运行时根据 LANG/LC_ALL 选择，见 defaults
+	Theme:           "default",
+	DigitFont:       "standard",
+	TaskList:        "default",
+	TaskSort:        "manual",
+	TrashDays:       30,
+	Notifications: NotificationSettings{
+		WorkEnd:  true,
+		BreakEnd: true,
+		CycleEnd: true,
+		Desktop:  true,
+		Sound:    true,
+
+		ChecklistPrompt: true,
+		DueBefore:       30,
+	},
+}
+
+// TimerConfig 根据设置生成计时器状态机的配置
+func (s Settings) TimerConfig() timer.Config {
+	return timer.Config{
+		Work:       time.Duration(s.Pomodoro) * time.Minute,
+		ShortBreak: time.Duration(s.ShortBreak) * time.Minute,
+		LongBreak:  time.Duration(s.LongBreak) * time.Minute,
+		Cycle:      int(s.Cycle),
+		AutoStart:  true, // 会话结束后自动进入下一阶段
+		Warning:    time.Duration(s.Notifications.WarnBefore) * time.Minute,
+	}
+}
+
+// OSCOptions 是终端通知转义序列的可选值，空字符串表示关闭
+var OSCOptions = []string{"", "9", "777"}
+
+// Validate reports the first setting that is out of range: a duration or
+// cycle of zero, or a time display mode, theme, digit font or terminal
+// notification that does not exist. Both the settings form and
+// "gomato config set" check it before saving.
+func (s Settings) Validate() error {
+	for _, f := range []struct {
+		key   string
+		value uint
+	}{
+		{"pomodoro", s.Pomodoro},
+		{"shortBreak", s.ShortBreak},
+		{"longBreak", s.LongBreak},
+		{"cycle", s.Cycle},
+	} {
+		if f.value < 1 {
+			return errors.New(i18n.T("config.atLeastOne", f.key))
+		}
+	}
+
+	themes := make([]string, len(Themes))
+	for i, t := range Themes {
+		themes[i] = t.Name
+	}
+	for _, c := range []struct {
+		key, value string
+		options    []string
+	}{
+		{"timeDisplayMode", s.TimeDisplayMode, []string{"ansi", "normal"}},
+		{"theme", s.Theme, themes},
+		{"digitFont", s.DigitFont, DigitFonts},
+		{"notifications.osc", s.Notifications.OSC, OSCOptions},
+	} {
+		if !slices.Contains(c.options, c.value) {
+			quoted := make([]string, len(c.options))
+			for i, o := range c.options {
+				quoted[i] = strconv.Quote(o)
+			}
+			return errors.New(i18n.T("config.notOneOf", c.key, c.value, strings.Join(quoted, ", ")))
+		}
+	}
+	return nil
+}
+
+// RenderTime 按时间显示方式和数字字体显示 MM:SS 形式的时间
+func (s Settings) RenderTime(clock string) string {
+	if s.TimeDisplayMode == "normal" {
+		return clock
+	}
+	return TimeToArt(clock, s.DigitFont)
+}
+
+// defaults 返回默认设置，界面语言取自环境变量
+func defaults() Settings {
+	s := defaultSettings
+	s.Language = i18n.Detect()
+	return s
 }
 
 func getSettingsPath() (string, error) {
-	home, err := os.UserHomeDir()
+	configDir, err := paths.ConfigDir()
 	if err != nil {
 		return "", err
 	}
-	configDir := filepath.Join(home, ".gomato")
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return "", err
 	}
 	return filepath.Join(configDir, "setting.json"), nil
 }
 
+// SettingsCorruptError reports a settings file that exists but cannot be
+// parsed. LoadSettings returns it together with the default settings and
+// leaves the file alone, so that it can still be restored; see
+// RecoverSettings.
+type SettingsCorruptError struct {
+	Path string
+	Err  error
+}
+
+func (e *SettingsCorruptError) Error() string {
+	return i18n.T("config.corrupt", e.Path, e.Err)
+}
+
+func (e *SettingsCorruptError) Unwrap() error { return e.Err }
+
+// LoadSettings reads the settings file. A missing or empty file gives the
+// defaults. A damaged file gives the defaults and a *SettingsCorruptError;
+// the file is not modified.
 func LoadSettings() (Settings, error) {
 	path, err := getSettingsPath()
 	if err != nil {
@@ -45,22 +190,74 @@ func LoadSettings() (Settings, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return defaultSettings, nil // Return defaults if file doesn't exist
+			return defaults(), nil // Return defaults if file doesn't exist
 		}
 		return defaultSettings, err
 	}
 
 	if len(data) == 0 {
-		return defaultSettings, nil // Return defaults if file is empty
+		return defaults(), nil // Return defaults if file is empty
 	}
 
-	var s Settings
+	// 文件中没有的字段（例如旧版本保存的设置）保留默认值
+	s := defaults()
 	if err := json.Unmarshal(data, &s); err != nil {
-		return defaultSettings, err
+		return defaults(), &SettingsCorruptError{Path: path, Err: err}
 	}
 	return s, nil
 }
 
+// validSettings 报告 data 是否是可以读取的设置文件
+func validSettings(data []byte) bool {
+	var s Settings
+	return json.Unmarshal(data, &s) == nil
+}
+
+// SettingsBackups returns the backups of the settings file that can be
+// restored, newest first.
+func SettingsBackups() ([]string, error) {
+	path, err := getSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	files, err := safefile.Backups(path)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, f := range files {
+		if data, err := os.ReadFile(f); err == nil && validSettings(data) {
+			backups = append(backups, f)
+		}
+	}
+	return backups, nil
+}
+
+// RecoverSettings replaces the damaged settings file with the backup at path
+// backup, or removes it so that the defaults are used when backup is "". The
+// damaged file is kept next to it as setting.json.corrupt-<time>;
+// RecoverSettings returns its path.
+func RecoverSettings(backup string) (string, error) {
+	path, err := getSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	var data []byte
+	if backup != "" {
+		if data, err = os.ReadFile(backup); err != nil {
+			return "", err
+		}
+		if !validSettings(data) {
+			return "", errors.New(i18n.T("config.badBackup", backup))
+		}
+	}
+	aside, err := safefile.SetAside(path)
+	if err != nil || backup == "" {
+		return aside, err
+	}
+	return aside, safefile.WriteFile(path, data, 0644)
+}
+
 func (s *Settings) Save() error {
 	path, err := getSettingsPath()
 	if err != nil {
@@ -72,5 +269,7 @@ func (s *Settings) Save() error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	// 备份只是为了在文件损坏时恢复，备份失败不影响保存
+	safefile.Backup(path, validSettings)
+	return safefile.WriteFile(path, data, 0644)
 }
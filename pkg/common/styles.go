@@ -2,8 +2,71 @@ package common
 
 import "github.com/charmbracelet/lipgloss"
 
+// Theme 是一套界面配色
+type Theme struct {
+	Name      string
+	Title     lipgloss.Color // 标题背景
+	OnTitle   lipgloss.Color // 标题文字
+	Accent    lipgloss.Color // 焦点与选中项
+	Muted     lipgloss.Color // 次要文字
+	Status    lipgloss.Color // 状态栏消息
+	Warning   lipgloss.Color // 需要注意的内容，如超出预估的任务
+	Danger    lipgloss.Color // 紧急的内容，如已过期的任务
+	Highlight lipgloss.AdaptiveColor
+}
+
+// Themes 是可选的颜色主题，第一个为默认主题
+var Themes = []Theme{
+	{
+		Name: "default", Title: "#25A065", OnTitle: "#FFFDF5", Accent: "205", Muted: "240", Status: "#04B575", Warning: "#E5A50A", Danger: "#E01B24",
+		Highlight: lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"},
+	},
+	{
+		Name: "ocean", Title: "#1E6FBA", OnTitle: "#F0F8FF", Accent: "39", Muted: "244", Status: "#4FC1E9", Warning: "#F5A623", Danger: "#FF5C5C",
+		Highlight: lipgloss.AdaptiveColor{Light: "#1E6FBA", Dark: "#4FC1E9"},
+	},
+	{
+		Name: "tomato", Title: "#D9432F", OnTitle: "#FFF5EE", Accent: "209", Muted: "242", Status: "#F28C28", Warning: "#FFD700", Danger: "#FF3030",
+		Highlight: lipgloss.AdaptiveColor{Light: "#D9432F", Dark: "#FF7F50"},
+	},
+	{
+		Name: "mono", Title: "#444444", OnTitle: "#FFFFFF", Accent: "255", Muted: "245", Status: "#BBBBBB", Warning: "#FFFFFF", Danger: "#FFFFFF",
+		Highlight: lipgloss.AdaptiveColor{Light: "#444444", Dark: "#BBBBBB"},
+	},
+}
+
+// 当前主题下的样式，由 ApplyAppearance 更新
 var (
 	AppStyle           = lipgloss.NewStyle().Padding(1, 2)
 	TitleStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#25A065")).Padding(0, 1)
 	StatusMessageStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"}).Render
+	AccentStyle        = lipgloss.NewStyle().Foreground(Themes[0].Accent) // 焦点与选中项
+	MutedStyle         = lipgloss.NewStyle().Foreground(Themes[0].Muted)  // 次要文字
+	CurrentTheme       = Themes[0]
 )
+
+// ThemeByName returns the theme with the given name, or the default theme.
+func ThemeByName(name string) Theme {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Themes[0]
+}
+
+// ApplyAppearance updates the shared styles for the theme and compact mode
+// in s.
+func ApplyAppearance(s Settings) {
+	t := ThemeByName(s.Theme)
+	CurrentTheme = t
+	TitleStyle = lipgloss.NewStyle().Foreground(t.OnTitle).Background(t.Title).Padding(0, 1)
+	StatusMessageStyle = lipgloss.NewStyle().Foreground(t.Status).Render
+	AccentStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	MutedStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	if s.Compact {
+		AppStyle = lipgloss.NewStyle().Padding(0, 1)
+	} else {
+		AppStyle = lipgloss.NewStyle().Padding(1, 2)
+	}
+}
@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"gomato/pkg/task"
+	"net"
+	"time"
+)
+
+// requestTimeout 是单个请求等待回复的最长时间
+const requestTimeout = 2 * time.Second
+
+// Client 是到守护进程的一条连接，不是并发安全的
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to the daemon listening on path. It returns ErrNotRunning
+// if nothing is listening.
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// DialDefault connects to the daemon at the default socket path.
+func DialDefault() (*Client, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(path)
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Do sends req and waits for the reply. A reply reporting failure is
+// returned as an error.
+func (c *Client) Do(req Request) (*task.ActiveState, error) {
+	c.conn.SetDeadline(time.Now().Add(requestTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+	if err := c.send(req); err != nil {
+		return nil, err
+	}
+	resp, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+// Status returns the daemon's current state.
+func (c *Client) Status() (*task.ActiveState, error) {
+	return c.Do(Request{Cmd: CmdStatus})
+}
+
+// Subscribe switches the connection to event streaming and calls fn with
+// the current state and then with every event, until fn returns false or
+// the connection fails. The connection cannot be used for requests after
+// subscribing.
+func (c *Client) Subscribe(fn func(Response) bool) error {
+	if err := c.send(Request{Cmd: CmdSubscribe}); err != nil {
+		return err
+	}
+	for {
+		resp, err := c.read()
+		if err != nil {
+			return err
+		}
+		if !fn(resp) {
+			return nil
+		}
+	}
+}
+
+func (c *Client) send(req Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(append(data, '\n'))
+	return err
+}
+
+func (c *Client) read() (Response, error) {
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return Response{}, err
+	}
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return Response{}, err
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
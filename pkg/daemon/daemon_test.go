@@ -0,0 +1,276 @@
+package daemon
+
+import (
+	"context"
+	"gomato/pkg/paths"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestServer 在临时主目录中启动守护进程，返回 socket 路径
+func startTestServer(t *testing.T, titles ...string) string {
+	t.Helper()
+	_, path := startServer(t, titles...)
+	return path
+}
+
+// startServer 与 startTestServer 相同，同时返回守护进程以便检查内部状态
+func startServer(t *testing.T, titles ...string) (*Server, string) {
+	t.Helper()
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	m, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range titles {
+		if err := m.AddItem(title, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "gomato.sock")
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Serve(ctx, l)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return s, path
+}
+
+func dial(t *testing.T, path string) *Client {
+	t.Helper()
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestStartPauseResume(t *testing.T) {
+	path := startTestServer(t, "写报告", "读书")
+	c := dial(t, path)
+
+	if _, err := c.Do(Request{Cmd: CmdStart}); err == nil {
+		t.Error("start without a task should fail before one is selected")
+	}
+
+	state, err := c.Do(Request{Cmd: CmdStart, Task: "读书"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.TaskIndex != 1 || state.TaskName != "读书" || state.Snapshot().State != timer.StateRunning {
+		t.Fatalf("after start: %+v", state)
+	}
+
+	state, err = c.Do(Request{Cmd: CmdPause})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Snapshot().State != timer.StatePaused {
+		t.Errorf("after pause: state = %v", state.Snapshot().State)
+	}
+
+	// 另一个客户端看到同一个计时器
+	other := dial(t, path)
+	state, err = other.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.TaskName != "读书" || state.Snapshot().State != timer.StatePaused {
+		t.Errorf("status from second client: %+v", state)
+	}
+
+	if _, err := other.Do(Request{Cmd: CmdResume}); err != nil {
+		t.Fatal(err)
+	}
+	state, err = c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Snapshot().State != timer.StateRunning {
+		t.Errorf("after resume: state = %v", state.Snapshot().State)
+	}
+
+	// 状态会写回任务文件
+	m, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Tasks[1].Timer.TimerIsRunning {
+		t.Error("task timer not saved as running")
+	}
+}
+
+func TestSubscribeReceivesEvents(t *testing.T) {
+	path := startTestServer(t, "写报告")
+	c := dial(t, path)
+	if _, err := c.Do(Request{Cmd: CmdStart, Task: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := dial(t, path)
+	events := make(chan Response, 8)
+	go sub.Subscribe(func(r Response) bool {
+		events <- r
+		return true
+	})
+
+	next := func() Response {
+		select {
+		case r := <-events:
+			return r
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return Response{}
+		}
+	}
+	if r := next(); r.Event != "" || r.Status == nil || r.Status.TaskName != "写报告" {
+		t.Fatalf("first message should be the current status, got %+v", r)
+	}
+
+	if _, err := c.Do(Request{Cmd: CmdSkip}); err != nil {
+		t.Fatal(err)
+	}
+	if r := next(); r.Event != timer.Skipped.String() {
+		t.Errorf("event = %q, want skipped", r.Event)
+	}
+	// 跳过运行中的工作会话后，休息自动开始
+	if r := next(); r.Event != timer.SessionStarted.String() || r.Status.Snapshot().Kind != timer.ShortBreak {
+		t.Errorf("event = %q, status = %+v", r.Event, r.Status)
+	}
+}
+
+// TestSubscriberDisconnect 测试订阅的客户端断开后，即使没有事件也会取消订阅
+func TestSubscriberDisconnect(t *testing.T) {
+	s, path := startServer(t, "写报告")
+	sub, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Response, 8)
+	go sub.Subscribe(func(r Response) bool {
+		events <- r
+		return true
+	})
+	select {
+	case <-events:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the current status")
+	}
+
+	sub.Close()
+	subscribers := func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.subs)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("断开后仍有 %d 个订阅者", subscribers())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStartInOtherList 测试开始另一个任务列表中的任务时，守护进程切换到该列表
+func TestStartInOtherList(t *testing.T) {
+	path := startTestServer(t, "写报告")
+	work, err := task.NewManager("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := work.AddItem("周报", ""); err != nil {
+		t.Fatal(err)
+	}
+	c := dial(t, path)
+
+	if _, err := c.Do(Request{Cmd: CmdStart, Task: "周报"}); err == nil {
+		t.Error("默认列表中没有 周报，应当报错")
+	}
+	if _, err := c.Do(Request{Cmd: CmdStart, Task: "1", List: "home"}); err == nil {
+		t.Error("不存在的列表应当报错")
+	}
+	state, err := c.Do(Request{Cmd: CmdStart, Task: "周报", List: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ListName() != "work" || state.TaskName != "周报" {
+		t.Fatalf("after start: %+v", state)
+	}
+
+	// 切回默认列表时暂停原任务的会话并保存到 work 列表
+	if state, err = c.Do(Request{Cmd: CmdStart, Task: "写报告"}); err != nil {
+		t.Fatal(err)
+	}
+	if state.ListName() != task.DefaultList || state.TaskName != "写报告" {
+		t.Errorf("after switching back: %+v", state)
+	}
+	work.Load()
+	if work.Tasks[0].Timer.StartedAt.IsZero() {
+		t.Errorf("周报 的会话应当保存在 work 列表中: %+v", work.Tasks[0].Timer)
+	}
+}
+
+// TestCorruptTasksNotOverwritten 测试任务文件损坏时守护进程不再写入它，并把错误告诉客户端
+func TestCorruptTasksNotOverwritten(t *testing.T) {
+	path := startTestServer(t, "写报告")
+	c := dial(t, path)
+	if _, err := c.Do(Request{Cmd: CmdStart, Task: "写报告"}); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := paths.DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasksPath := filepath.Join(dir, "tasks.json")
+	os.WriteFile(tasksPath, []byte("[{"), 0644)
+
+	if _, err := c.Do(Request{Cmd: CmdPause}); err == nil || !strings.Contains(err.Error(), tasksPath) {
+		t.Errorf("pause 应当报告任务文件损坏: %v", err)
+	}
+	if state, err := c.Status(); err != nil || state.Snapshot().State != timer.StatePaused {
+		t.Errorf("计时操作仍应生效: %+v, %v", state, err)
+	}
+	if data, _ := os.ReadFile(tasksPath); string(data) != "[{" {
+		t.Errorf("损坏的任务文件被覆盖: %q", data)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	path := startTestServer(t)
+	c := dial(t, path)
+	if _, err := c.Do(Request{Cmd: "bogus"}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+	// 出错后连接仍可使用
+	if _, err := c.Status(); err != nil {
+		t.Errorf("status after error: %v", err)
+	}
+}
+
+func TestListenRejectsRunningDaemon(t *testing.T) {
+	path := startTestServer(t)
+	if _, err := Listen(path); err == nil {
+		t.Error("expected error when a daemon is already listening")
+	}
+}
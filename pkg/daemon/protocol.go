@@ -0,0 +1,82 @@
+// Package daemon 让一个后台进程持有番茄钟状态机和任务存储，
+// 并通过本地 Unix socket 接受控制。
+//
+// 协议是按行分隔的 JSON：客户端每发送一个 Request，服务端回复一个 Response。
+// subscribe 请求之后，服务端会在该连接上持续推送事件，直到连接断开。
+package daemon
+
+import (
+	"errors"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"gomato/pkg/task"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 请求命令
+const (
+	CmdStatus    = "status"    // 返回当前状态
+	CmdStart     = "start"     // 开始 Task 指定的任务；未指定时开始或继续当前会话
+	CmdPause     = "pause"     // 暂停
+	CmdResume    = "resume"    // 继续
+	CmdToggle    = "toggle"    // 运行中则暂停，否则开始
+	CmdSkip      = "skip"      // 跳过当前阶段
+	CmdReset     = "reset"     // 放弃当前会话
+	CmdSubscribe = "subscribe" // 订阅事件
+)
+
+// Request 是客户端发送的请求
+type Request struct {
+	Cmd  string `json:"cmd"`
+	Task string `json:"task,omitempty"` // 任务序号（从 1 开始）或标题
+	List string `json:"list,omitempty"` // Task 所在的任务列表，为空表示默认列表
+}
+
+// Response 是服务端的回复或推送的事件
+type Response struct {
+	OK     bool              `json:"ok"`
+	Error  string            `json:"error,omitempty"`
+	Event  string            `json:"event,omitempty"` // 事件类型，仅订阅推送时设置
+	Status *task.ActiveState `json:"status,omitempty"`
+}
+
+// ErrNotRunning 表示守护进程没有运行
+var ErrNotRunning error = notRunningError{}
+
+// notRunningError 在输出时才翻译，提示随当前的界面语言变化
+type notRunningError struct{}
+
+func (notRunningError) Error() string { return i18n.T("daemon.notRunning") }
+
+// SocketPath returns the path of the control socket.
+func SocketPath() (string, error) {
+	return paths.RuntimeFile("gomato.sock")
+}
+
+// Listen creates the control socket at path. A socket left behind by a
+// daemon that exited without cleaning up is removed; a live one is an error.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, errors.New(i18n.T("daemon.running", path))
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// 只允许当前用户连接
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,377 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
+	"gomato/pkg/notice"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Server 持有唯一的计时器状态机，所有客户端都通过它控制计时
+type Server struct {
+	mu       sync.Mutex
+	engine   *timer.Engine
+	tasks    *task.Manager
+	history  *task.History
+	store    *task.StateStore
+	notifier *notice.Dispatcher
+	notify   common.NotificationSettings
+	current  string // 当前任务的 ID，为空表示尚未选择任务
+	name     string
+	taskErr  string // 上一次读写任务文件的错误，相同的错误每秒计时时只记录一次
+	subs     map[chan Response]struct{}
+}
+
+// NewServer loads the tasks, settings and saved timer state and returns a
+// server ready to serve. A session that was running when the previous
+// process stopped keeps running.
+func NewServer() (*Server, error) {
+	history, err := task.NewHistory()
+	if err != nil {
+		return nil, err
+	}
+	store, err := task.NewStateStore()
+	if err != nil {
+		return nil, err
+	}
+	state, err := store.Load()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[State] 读取计时状态失败: %v", err))
+	}
+	// 打开上次计时的任务所在的列表
+	list := ""
+	if state != nil {
+		list = state.ListName()
+	}
+	tasks, err := task.NewManager(list)
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 读取任务列表 %s 失败，使用默认列表: %v", list, err))
+		if tasks, err = task.NewManager(""); err != nil {
+			return nil, err
+		}
+	}
+	settings, err := common.LoadSettings()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 读取设置失败，使用默认设置: %v", err))
+	}
+
+	s := &Server{
+		engine:   timer.New(settings.TimerConfig(), nil),
+		tasks:    tasks,
+		history:  history,
+		store:    store,
+		notifier: notice.FromSettings(settings.Notifications, os.Stderr),
+		notify:   settings.Notifications,
+		subs:     map[chan Response]struct{}{},
+	}
+	s.engine.Subscribe(s.onEvent)
+
+	if state != nil && state.ListName() == tasks.List() {
+		if i := tasks.StateTask(*state); i >= 0 {
+			s.current = tasks.Tasks[i].ID
+			s.name = state.TaskName
+			s.engine.Restore(state.Snapshot())
+			s.engine.SetConfig(settings.TimerConfig())
+		}
+	}
+	return s, nil
+}
+
+// Serve accepts connections on l and drives the timer until ctx is done.
+// The state is saved before it returns.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	runDone := make(chan struct{})
+	defer func() { <-runDone }()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	go func() {
+		s.run(ctx)
+		close(runDone)
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// run 每秒检查一次截止时间，会话到期后进入下一阶段
+func (s *Server) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.saveTask()
+			s.mu.Unlock()
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.engine.State() == timer.StateRunning {
+				s.engine.Tick()
+				s.saveTask()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(Response{Error: i18n.T("daemon.badRequest", err)})
+			continue
+		}
+		if req.Cmd == CmdSubscribe {
+			s.subscribe(scanner, enc)
+			return
+		}
+		if err := enc.Encode(s.Do(req)); err != nil {
+			return
+		}
+	}
+}
+
+// subscribe 先回复当前状态，然后持续推送事件，直到客户端断开或写入失败
+func (s *Server) subscribe(scanner *bufio.Scanner, enc *json.Encoder) {
+	ch := make(chan Response, 16)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	status := s.status()
+	s.mu.Unlock()
+	defer func() {
+		// 事件在持有锁时发送，取消订阅后关闭不会与发送冲突
+		s.mu.Lock()
+		delete(s.subs, ch)
+		close(ch)
+		s.mu.Unlock()
+	}()
+
+	// 订阅后客户端不再发送请求，读到 EOF 或出错说明它已断开。
+	// 返回后 handle 关闭连接，这个 goroutine 随之结束
+	gone := make(chan struct{})
+	go func() {
+		for scanner.Scan() {
+		}
+		close(gone)
+	}()
+
+	if err := enc.Encode(Response{OK: true, Status: &status}); err != nil {
+		return
+	}
+	for {
+		select {
+		case resp := <-ch:
+			if err := enc.Encode(resp); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// Do executes a single request.
+func (s *Server) Do(req Request) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloadSettings()
+	switch req.Cmd {
+	case CmdStatus:
+	case CmdStart:
+		if req.Task != "" {
+			if err := s.selectTask(req.Task, req.List); err != nil {
+				return Response{Error: err.Error()}
+			}
+		}
+		if s.current == "" {
+			return Response{Error: i18n.T("daemon.noTask")}
+		}
+		s.engine.Start()
+	case CmdPause:
+		s.engine.Pause()
+	case CmdResume:
+		s.engine.Start()
+	case CmdToggle:
+		s.engine.Toggle()
+	case CmdSkip:
+		s.engine.Skip()
+	case CmdReset:
+		s.engine.Reset()
+	default:
+		return Response{Error: i18n.T("daemon.unknownCommand", req.Cmd)}
+	}
+	if req.Cmd != CmdStatus {
+		// 计时操作已经生效，但任务文件无法读写时告诉客户端，计时不会写入任务
+		if err := s.saveTask(); err != nil {
+			return Response{Error: err.Error()}
+		}
+	}
+	status := s.status()
+	return Response{OK: true, Status: &status}
+}
+
+// selectTask 切换到任务列表 list 中 ref 指定的任务。任务列表可能被其他进程修改过，
+// 因此先重新读取；原任务正在运行时先暂停。
+func (s *Server) selectTask(ref, list string) error {
+	tasks := s.tasks
+	if list == "" {
+		list = task.DefaultList
+	}
+	if list != tasks.List() {
+		if !task.ListExists(list) {
+			return errors.New(i18n.T("daemon.noList", list))
+		}
+		var err error
+		if tasks, err = task.NewManager(list); err != nil {
+			return err
+		}
+	} else if err := tasks.Load(); err != nil {
+		return err
+	}
+	i, err := tasks.Find(ref)
+	if err != nil {
+		return err
+	}
+	if tasks == s.tasks && tasks.Tasks[i].ID == s.current {
+		return nil
+	}
+	if s.engine.State() == timer.StateRunning {
+		s.engine.Pause()
+		s.saveTask()
+	}
+	s.tasks = tasks
+	s.current = tasks.Tasks[i].ID
+	s.name = tasks.Tasks[i].Name
+	s.engine.Restore(tasks.Tasks[i].Timer.Snapshot(s.engine.Cycle()))
+	s.engine.SetConfig(s.engine.Config())
+	return nil
+}
+
+// reloadSettings 在每次请求时读取最新设置，使 config set 或设置界面的修改立即生效
+func (s *Server) reloadSettings() {
+	settings, err := common.LoadSettings()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 读取设置失败: %v", err))
+		return
+	}
+	i18n.SetLanguage(settings.Language)
+	s.engine.SetConfig(settings.TimerConfig())
+	s.notifier = notice.FromSettings(settings.Notifications, os.Stderr)
+	s.notify = settings.Notifications
+}
+
+// status 返回当前状态，调用方需持有锁
+func (s *Server) status() task.ActiveState {
+	now := time.Now()
+	return task.ActiveState{
+		TaskID:     s.current,
+		TaskIndex:  s.tasks.Index(s.current),
+		TaskName:   s.name,
+		List:       s.tasks.List(),
+		Timer:      task.NewTimeModel(s.engine.Snapshot(), now),
+		CycleCount: s.engine.Cycle(),
+		SavedAt:    now,
+	}
+}
+
+// saveTask 把计时器写回所属任务并保存状态快照，调用方需持有锁。
+// 返回读写任务文件的错误
+func (s *Server) saveTask() error {
+	state := s.status()
+	if err := s.store.Save(state); err != nil {
+		logging.Log(fmt.Sprintf("[State] 保存计时状态失败: %v", err))
+	}
+	return s.updateTask(func(t *task.Task) { t.Timer = state.Timer })
+}
+
+// countPomodoro 为当前任务增加一个在 end 完成的番茄，调用方需持有锁
+func (s *Server) countPomodoro(end time.Time) {
+	s.updateTask(func(t *task.Task) { t.CountPomodoro(end) })
+}
+
+// updateTask 重新读取任务文件，修改当前任务后按 ID 写回，其他客户端增删的任务得以保留。
+// 任务文件损坏时不写入，以免覆盖可以从备份恢复的任务
+func (s *Server) updateTask(fn func(t *task.Task)) error {
+	if s.current == "" {
+		return nil
+	}
+	err := s.tasks.Load()
+	if err == nil {
+		if t, ok := s.tasks.Get(s.current); ok {
+			fn(&t)
+			err = s.tasks.Update(t)
+		}
+	}
+	if err != nil && err.Error() != s.taskErr {
+		logging.Log(fmt.Sprintf("[Daemon] 保存任务失败: %v", err))
+	}
+	s.taskErr = ""
+	if err != nil {
+		s.taskErr = err.Error()
+	}
+	return err
+}
+
+// onEvent 记录历史、发送通知并推送给订阅者。事件在持有锁时同步触发。
+func (s *Server) onEvent(ev timer.Event) {
+	switch ev.Type {
+	case timer.SessionCompleted, timer.Skipped, timer.Reset:
+		if !ev.Session.Start.IsZero() {
+			// 任务可能已被删除，此时只记录会话开始时的标题
+			t, ok := s.tasks.Get(s.current)
+			if !ok {
+				t = task.Task{Name: s.name}
+			}
+			session := task.NewSession(t, ev.Session, ev.Type == timer.SessionCompleted)
+			session.List = s.tasks.List()
+			if err := s.history.Append(session); err != nil {
+				logging.Log(fmt.Sprintf("[History] 写入会话记录失败: %v", err))
+			}
+		}
+	}
+	if ev.Type == timer.SessionCompleted && ev.Session.Kind == timer.Work {
+		s.countPomodoro(ev.Session.End)
+	}
+	if title, msg, ok := notice.ForEvent(s.notify, ev); ok {
+		s.notifier.Send(title, msg)
+	}
+
+	status := s.status()
+	resp := Response{OK: true, Event: ev.Type.String(), Status: &status}
+	for ch := range s.subs {
+		select {
+		case ch <- resp:
+		default:
+			// 订阅者处理不过来时丢弃事件，避免阻塞计时
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package gomato
 
 import (
+	"errors"
+	"fmt"
 	"gomato/pkg/common"
+	"gomato/pkg/daemon"
+	"gomato/pkg/i18n"
 	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/notice"
 	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"os"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,79 +23,276 @@ const (
 	taskInputView
 	timeView
 	settingView
+	statsView
+	resumeView
+	archiveView
+	detailView
+	checklistPromptView
+	trashView
+	listsView
+	recoveryView
+	settingsRecoveryView
 )
 
 type viewState int
 
 type App struct {
-	currentView       viewState
-	currentTaskIndex  int
-	taskManager       *task.Manager
-	list              list.Model
-	keys              *keymap.ListKeyMap
-	delegateKeys      *keymap.DelegateKeyMap
-	timeViewKeys      *keymap.TimeViewKeyMap
-	timeModel         task.TimeModel
-	settingModel      SettingModel
-	taskInput         TaskInputModel
-	CurrentCycleCount int
+	currentView     viewState
+	currentTaskID   string // 当前任务的 ID，为空表示尚未选择任务
+	taskManager     *task.Manager
+	history         *task.History
+	stateStore      *task.StateStore
+	list            list.Model
+	keys            *keymap.ListKeyMap
+	delegateKeys    *keymap.DelegateKeyMap
+	timeViewKeys    *keymap.TimeViewKeyMap
+	engine          *timer.Engine  // 番茄钟状态机，App 订阅其事件
+	remote          *daemon.Client // 守护进程运行时不为空，计时操作转发给它
+	pendingCmds     []tea.Cmd      // 处理计时器事件时产生的命令
+	notifier        *notice.Dispatcher
+	settingModel    SettingModel
+	statsModel      StatsModel
+	archiveModel    ArchiveModel
+	trashModel      TrashModel
+	listsModel      ListsModel
+	undo            undoStack // 任务列表中可以撤销和重做的操作
+	detailModel     DetailModel
+	checklistPrompt ChecklistPromptModel
+	promptReturn    viewState // 清单提示结束后返回的界面
+	resumeModel     ResumeModel
+	recoveryModel   RecoveryModel
+	settingRecovery SettingsRecoveryModel
+	taskInput       TaskInputModel
+	sortMode        task.SortMode        // 任务列表的排序方式
+	dateFilter      task.DateFilter      // 任务列表的日期筛选
+	reminded        map[string]time.Time // 已提醒过的任务及其截止日期
+	width, height   int                  // 终端窗口大小，切换紧凑模式时用于重新布局
+	tickGen         int                  // 当前有效的 tick 链代数
+	clock           timer.Clock          // 为空时使用 time.Now
 }
 
 func NewApp() *App {
+	// 先切换界面语言，之后创建的按键帮助和界面文字才使用该语言
+	settingModel := NewSettingModel()
+	i18n.SetLanguage(settingModel.Settings.Language)
+	settingModel.setLabels()
+
 	delegateKeys := keymap.NewDelegateKeyMap()
 	listKeys := keymap.NewListKeyMap()
 	timeViewKeys := keymap.NewTimeViewKeyMap()
-	taskManager, _ := task.NewManager()
-	if len(taskManager.Tasks) == 0 {
-		taskManager.AddItem("欢迎使用Gomato!", "这是一个番茄钟应用，希望能帮助你提高效率。")
+	statsViewKeys := keymap.NewStatsViewKeyMap()
+	now := time.Now()
+	cfg := settingModel.Settings.TimerConfig()
+	taskManager := &task.Manager{}
+	var corrupt *task.CorruptError
+	// 设置文件损坏时其中记录的当前列表不可信，先恢复设置再打开任务列表
+	if settingModel.corrupt == nil {
+		taskManager, corrupt = startList(settingModel.Settings.TaskList, cfg, now)
 	}
-	settingModel := NewSettingModel()
-	for i := range taskManager.Tasks {
-		taskManager.Tasks[i].Timer = task.TimeModel{
-			TimerDuration:  int(settingModel.Settings.Pomodoro) * 60,
-			TimerRemaining: int(settingModel.Settings.Pomodoro) * 60,
-			TimerIsRunning: false,
-		}
+	history, err := task.NewHistory()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[History] 初始化历史记录失败: %v", err))
+	}
+	stateStore, err := task.NewStateStore()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[State] 初始化状态存储失败: %v", err))
 	}
 	taskList := NewTaskList(listKeys, delegateKeys, taskManager)
-	taskTimeModel := task.TimeModel{
-		TimerDuration:  int(settingModel.Settings.Pomodoro) * 60,
-		TimerRemaining: int(settingModel.Settings.Pomodoro) * 60,
-		TimerIsRunning: false,
-		IsWorkSession:  true,
-	}
-	return &App{
-		currentView:       taskListView,
-		currentTaskIndex:  0,
-		list:              taskList,
-		taskInput:         NewTaskInputModel(),
-		keys:              listKeys,
-		delegateKeys:      delegateKeys,
-		timeViewKeys:      timeViewKeys,
-		taskManager:       taskManager,
-		timeModel:         taskTimeModel,
-		settingModel:      settingModel,
-		CurrentCycleCount: 0,
+	app := &App{
+		currentView:  taskListView,
+		list:         taskList,
+		taskInput:    NewTaskInputModel(),
+		keys:         listKeys,
+		delegateKeys: delegateKeys,
+		timeViewKeys: timeViewKeys,
+		taskManager:  taskManager,
+		history:      history,
+		engine:       timer.New(cfg, nil),
+		settingModel: settingModel,
+		statsModel:   NewStatsModel(statsViewKeys),
+		archiveModel: NewArchiveModel(keymap.NewArchiveViewKeyMap()),
+		trashModel:   NewTrashModel(keymap.NewTrashViewKeyMap(), keymap.NewConfirmKeyMap()),
+		listsModel:   NewListsModel(keymap.NewListsViewKeyMap()),
+		detailModel:  DetailModel{keys: keymap.NewDetailViewKeyMap()},
+		stateStore:   stateStore,
+		notifier:     notice.FromSettings(settingModel.Settings.Notifications, os.Stderr),
+	}
+	app.settingModel.Settings.TaskList = taskManager.List()
+	app.list.Title = app.listTitle()
+	app.purgeExpiredTrash()
+	if mode, err := task.ParseSortMode(settingModel.Settings.TaskSort); err == nil {
+		app.sortMode = mode
+		app.refreshList()
 	}
+	app.engine.Subscribe(app.onTimerEvent)
+	app.applyAppearance()
+	if settingModel.corrupt != nil {
+		app.settingRecovery = NewSettingsRecoveryModel(settingModel.corrupt, keymap.NewConfirmKeyMap())
+		app.currentView = settingsRecoveryView
+		return app
+	}
+	if corrupt != nil {
+		app.recoveryModel = NewRecoveryModel(corrupt, keymap.NewConfirmKeyMap())
+		app.currentView = recoveryView
+		return app
+	}
+	// 守护进程持有计时状态时由它负责恢复，不再提示
+	if !app.attachDaemon() {
+		app.offerResume(keymap.NewConfirmKeyMap(), now)
+	}
+	return app
 }
 
-func (m *App) Init() tea.Cmd {
+// startList 打开上次使用的任务列表，它已被删除或无法打开时回到默认列表。
+// 任务文件损坏时返回空的 Manager 和 CorruptError，由恢复界面决定如何处理
+func startList(list string, cfg timer.Config, now time.Time) (*task.Manager, *task.CorruptError) {
+	if !task.ListExists(list) {
+		list = task.DefaultList
+	}
+	taskManager, err := loadTasks(list, cfg, now)
+	var corrupt *task.CorruptError
+	if err != nil && !errors.As(err, &corrupt) {
+		logging.Log(fmt.Sprintf("[Task] 打开任务列表 %s 失败，使用默认列表: %v", list, err))
+		taskManager, err = loadTasks("", cfg, now)
+	}
+	if errors.As(err, &corrupt) {
+		// 任务文件损坏：先不打开任何列表
+		logging.Log(fmt.Sprintf("[Task] %v", err))
+		return &task.Manager{}, corrupt
+	}
+	if len(taskManager.Tasks) == 0 && taskManager.List() == task.DefaultList {
+		taskManager.AddItem(i18n.T("app.welcome.title"), i18n.T("app.welcome.description"))
+	}
+	return taskManager, nil
+}
+
+// currentTask 返回当前任务，尚未选择或已被删除时返回 nil
+func (m *App) currentTask() *task.Task {
+	if i := m.taskManager.Index(m.currentTaskID); i >= 0 {
+		return &m.taskManager.Tasks[i]
+	}
 	return nil
 }
 
+// listIndex 返回任务在列表全部条目（不受筛选影响）中的位置，找不到时返回 -1
+func (m *App) listIndex(id string) int {
+	for i, item := range m.list.Items() {
+		if t, ok := item.(task.Task); ok && t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyLanguage 切换到设置中的界面语言，并用新语言重建按键帮助和列表标题
+func (m *App) applyLanguage() {
+	i18n.SetLanguage(m.settingModel.Settings.Language)
+	// 按键映射被列表和各视图共享，原地替换以保留引用
+	removable := m.delegateKeys.Remove.Enabled()
+	*m.keys = *keymap.NewListKeyMap()
+	*m.delegateKeys = *keymap.NewDelegateKeyMap()
+	m.delegateKeys.Remove.SetEnabled(removable)
+	*m.timeViewKeys = *keymap.NewTimeViewKeyMap()
+	if m.statsModel.keys != nil {
+		*m.statsModel.keys = *keymap.NewStatsViewKeyMap()
+	}
+	if m.archiveModel.keys != nil {
+		*m.archiveModel.keys = *keymap.NewArchiveViewKeyMap()
+	}
+	if m.trashModel.keys != nil {
+		*m.trashModel.keys = *keymap.NewTrashViewKeyMap()
+	}
+	if m.trashModel.confirm != nil {
+		*m.trashModel.confirm = *keymap.NewConfirmKeyMap()
+	}
+	if m.listsModel.keys != nil {
+		*m.listsModel.keys = *keymap.NewListsViewKeyMap()
+	}
+	if m.detailModel.keys != nil {
+		*m.detailModel.keys = *keymap.NewDetailViewKeyMap()
+	}
+	m.list.Title = m.listTitle()
+}
+
+// offerResume 若上次退出时有未完成的会话，则进入恢复提示界面
+func (m *App) offerResume(keys *keymap.ConfirmKeyMap, now time.Time) {
+	if m.stateStore == nil {
+		return
+	}
+	state, err := m.stateStore.Load()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[State] 读取计时状态失败: %v", err))
+		return
+	}
+	if state == nil || !state.InProgress() {
+		return
+	}
+	if state.ListName() != m.taskManager.List() {
+		// 上次计时的任务在另一个列表中
+		if !task.ListExists(state.ListName()) {
+			logging.Log("[State] 上次的会话所属列表已不存在，忽略恢复")
+			return
+		}
+		if err := m.openList(state.ListName()); err != nil {
+			logging.Log(fmt.Sprintf("[State] 打开上次会话所在的任务列表失败: %v", err))
+			return
+		}
+	}
+	if m.taskManager.StateTask(*state) < 0 {
+		logging.Log("[State] 上次的会话所属任务已不存在，忽略恢复")
+		return
+	}
+	m.resumeModel = NewResumeModel(*state, keys, now)
+	m.currentView = resumeView
+}
+
+func (m *App) Init() tea.Cmd {
+	if m.remote != nil {
+		// 持续同步守护进程的状态
+		return tea.Batch(m.checkReminders(), remindLater(), m.startTicking())
+	}
+	return tea.Batch(m.checkReminders(), remindLater())
+}
+
 func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// 命令行或守护进程可能修改了任务文件，修改任务之前先重新读取
+	switch msg.(type) {
+	case tea.KeyMsg, taskCreatedMsg, taskEditedMsg, archiveMsg, trashMsg, checklistMsg, checklistPickMsg:
+		m.reloadTasks()
+	}
 	switch msg := msg.(type) {
 	case taskCreatedMsg:
 		return handleTaskCreated(m, msg)
+	case taskEditedMsg:
+		return handleTaskEdited(m, msg)
 	case backMsg:
 		return handleBack(m)
+	case resumeMsg:
+		return handleResume(m, msg)
+	case recoveryMsg:
+		return handleRecovery(m, msg)
+	case settingsRecoveryMsg:
+		return handleSettingsRecovery(m, msg)
+	case archiveMsg:
+		return handleArchive(m, msg)
+	case trashMsg:
+		return handleTrash(m, msg)
+	case listSwitchMsg:
+		return handleListSwitch(m, msg)
+	case checklistMsg:
+		return handleChecklist(m, msg)
+	case checklistPickMsg:
+		return handleChecklistPick(m, msg)
 	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
 		h, v := common.AppStyle.GetFrameSize()
 		m.list.SetSize(msg.Width-h, msg.Height-v)
 		m.settingModel, _ = m.settingModel.Update(msg)
 		return m, nil
 	case tickMsg:
-		return m, handleTick(m)
+		return m, handleTick(m, msg)
+	case reminderMsg:
+		return handleReminder(m)
 	}
 	var cmd tea.Cmd
 	switch m.currentView {
@@ -98,6 +304,24 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.taskInput, cmd = m.taskInput.Update(msg)
 	case settingView:
 		m.settingModel, cmd = m.settingModel.Update(msg)
+	case statsView:
+		m.statsModel, cmd = m.statsModel.Update(msg)
+	case resumeView:
+		m.resumeModel, cmd = m.resumeModel.Update(msg)
+	case recoveryView:
+		m.recoveryModel, cmd = m.recoveryModel.Update(msg)
+	case settingsRecoveryView:
+		m.settingRecovery, cmd = m.settingRecovery.Update(msg)
+	case archiveView:
+		m.archiveModel, cmd = m.archiveModel.Update(msg)
+	case trashView:
+		m.trashModel, cmd = m.trashModel.Update(msg)
+	case listsView:
+		m.listsModel, cmd = m.listsModel.Update(msg)
+	case detailView:
+		m.detailModel, cmd = m.detailModel.Update(msg)
+	case checklistPromptView:
+		m.checklistPrompt, cmd = m.checklistPrompt.Update(msg)
 	}
 
 	return m, cmd
@@ -108,11 +332,29 @@ func (m *App) View() string {
 	case taskListView:
 		return common.AppStyle.Render(m.list.View())
 	case timeView:
-		return common.AppStyle.Render(m.timeModel.ViewWithSettings(&m.settingModel.Settings))
+		return common.AppStyle.Render(timerView(m.timeModel(), &m.settingModel.Settings))
 	case taskInputView:
 		return common.AppStyle.Render(m.taskInput.View())
 	case settingView:
 		return m.settingModel.View()
+	case statsView:
+		return common.AppStyle.Render(m.statsModel.View())
+	case resumeView:
+		return common.AppStyle.Render(m.resumeModel.View())
+	case recoveryView:
+		return common.AppStyle.Render(m.recoveryModel.View())
+	case settingsRecoveryView:
+		return common.AppStyle.Render(m.settingRecovery.View())
+	case archiveView:
+		return common.AppStyle.Render(m.archiveModel.View())
+	case trashView:
+		return common.AppStyle.Render(m.trashModel.View())
+	case listsView:
+		return common.AppStyle.Render(m.listsModel.View())
+	case detailView:
+		return common.AppStyle.Render(m.detailModel.View())
+	case checklistPromptView:
+		return common.AppStyle.Render(m.checklistPrompt.View())
 	default:
 		return ""
 	}
@@ -0,0 +1,46 @@
+package gomato
+
+import (
+	"gomato/pkg/common"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// applyAppearance 按当前设置更新主题颜色和紧凑模式，
+// 启动时和修改设置后调用
+func (m *App) applyAppearance() {
+	s := m.settingModel.Settings
+	common.ApplyAppearance(s)
+	t := common.CurrentTheme
+
+	focusedStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	blurredStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	cursorStyle = focusedStyle
+	helpStyle = blurredStyle
+
+	highlightColor = t.Highlight
+	inactiveTabStyle = lipgloss.NewStyle().Border(inactiveTabBorder, true).BorderForeground(highlightColor).Padding(0, 1)
+	activeTabStyle = inactiveTabStyle.Border(activeTabBorder, true)
+	windowStyle = lipgloss.NewStyle().BorderForeground(highlightColor).Padding(2, 0).Align(lipgloss.Center).Border(lipgloss.NormalBorder()).UnsetBorderTop()
+
+	statusMessageStyle = common.StatusMessageStyle
+
+	m.list.Styles.Title = common.TitleStyle
+	m.list.SetDelegate(newItemDelegate(m.delegateKeys, s.Compact))
+	if m.width > 0 {
+		h, v := common.AppStyle.GetFrameSize()
+		m.list.SetSize(m.width-h, m.height-v)
+	}
+}
+
+// styleDelegate 把主题颜色应用到列表项，紧凑模式下只显示标题且不留空行
+func styleDelegate(d *list.DefaultDelegate, compact bool) {
+	t := common.CurrentTheme
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(t.Accent).BorderForeground(t.Accent)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(t.Accent).BorderForeground(t.Accent)
+	d.ShowDescription = !compact
+	if compact {
+		d.SetSpacing(0)
+	}
+}
@@ -0,0 +1,135 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ArchiveModel 列出已完成的任务，可以恢复或删除
+type ArchiveModel struct {
+	tasks  []task.Task // 按完成时间倒序
+	cursor int
+	status string
+	keys   *keymap.ArchiveViewKeyMap
+}
+
+// archiveMsg 请求 App 恢复或删除归档中的任务，id 为空表示删除全部
+type archiveMsg struct {
+	id      string
+	restore bool
+}
+
+func NewArchiveModel(keys *keymap.ArchiveViewKeyMap) ArchiveModel {
+	return ArchiveModel{keys: keys}
+}
+
+// Reload 重新读取已完成的任务，并保持光标在有效范围内
+func (m *ArchiveModel) Reload(manager *task.Manager) {
+	m.tasks = manager.Completed()
+	if m.cursor >= len(m.tasks) {
+		m.cursor = len(m.tasks) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m ArchiveModel) Update(msg tea.Msg) (ArchiveModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		m.status = ""
+		return m, func() tea.Msg { return backMsg{} }
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(m.tasks)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Restore):
+		if len(m.tasks) > 0 {
+			id := m.tasks[m.cursor].ID
+			return m, func() tea.Msg { return archiveMsg{id: id, restore: true} }
+		}
+	case key.Matches(keyMsg, m.keys.Purge):
+		if len(m.tasks) > 0 {
+			id := m.tasks[m.cursor].ID
+			return m, func() tea.Msg { return archiveMsg{id: id} }
+		}
+	case key.Matches(keyMsg, m.keys.PurgeAll):
+		if len(m.tasks) > 0 {
+			return m, func() tea.Msg { return archiveMsg{} }
+		}
+	}
+	return m, nil
+}
+
+func (m ArchiveModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("archive.title")))
+	b.WriteString("\n\n")
+
+	if len(m.tasks) == 0 {
+		b.WriteString(helpStyle.Render(i18n.T("archive.empty")) + "\n")
+	}
+	done := lipgloss.NewStyle().Strikethrough(true)
+	for i, t := range m.tasks {
+		line := fmt.Sprintf("%s  %s", done.Render(t.Title()),
+			helpStyle.Render(i18n.T("archive.completedAt", t.CompletedAt.Format("2006-01-02 15:04"))))
+		if i == m.cursor {
+			b.WriteString(focusedStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.status != "" {
+		b.WriteString("\n" + statusMessageStyle(m.status) + "\n")
+	}
+	b.WriteString("\n" + helpStyle.Render(i18n.T("archive.help", keymap.HelpKey("archive.restore"),
+		keymap.HelpKey("archive.purge"), keymap.HelpKey("archive.purgeAll"), keymap.HelpKey("archive.back"))))
+	return b.String()
+}
+
+// handleArchive 恢复或删除归档中的任务，然后刷新归档和任务列表
+func handleArchive(m *App, msg archiveMsg) (tea.Model, tea.Cmd) {
+	var err error
+	switch {
+	case msg.id == "":
+		n := len(m.archiveModel.tasks)
+		if err = m.taskManager.PurgeCompleted(); err == nil {
+			m.archiveModel.status = i18n.T("archive.purgedAll", n)
+		}
+	case msg.restore:
+		t, _ := m.taskManager.Get(msg.id)
+		if err = m.taskManager.SetDone(msg.id, false, time.Time{}); err == nil {
+			m.archiveModel.status = i18n.T("archive.restored", t.Title())
+		}
+	default:
+		t, _ := m.taskManager.Get(msg.id)
+		if err = m.taskManager.Delete(msg.id); err == nil {
+			m.archiveModel.status = i18n.T("archive.purged", t.Title())
+		}
+	}
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Task] 更新已完成任务失败: %v", err))
+	}
+	m.archiveModel.Reload(m.taskManager)
+	m.refreshList()
+	return m, nil
+}
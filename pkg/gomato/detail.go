@@ -0,0 +1,289 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DetailModel 显示任务的全部信息，并可以编辑任务的清单
+type DetailModel struct {
+	task   task.Task
+	cursor int
+	adding bool // 正在输入新的清单项
+	input  textinput.Model
+	keys   *keymap.DetailViewKeyMap
+}
+
+// checklistMsg 在清单改变后发送，由 App 保存
+type checklistMsg struct {
+	id    string
+	items []task.Subtask
+}
+
+func NewDetailModel(t task.Task, keys *keymap.DetailViewKeyMap) DetailModel {
+	input := textinput.New()
+	input.Placeholder = i18n.T("detail.newItem")
+	input.CharLimit = 156
+	input.Width = 50
+	// 复制清单，编辑时不直接改动任务管理器中的数据
+	t.Checklist = append([]task.Subtask(nil), t.Checklist...)
+	return DetailModel{task: t, input: input, keys: keys}
+}
+
+// changed 返回保存当前清单的命令
+func (m DetailModel) changed() tea.Cmd {
+	id := m.task.ID
+	items := append([]task.Subtask(nil), m.task.Checklist...)
+	return func() tea.Msg { return checklistMsg{id: id, items: items} }
+}
+
+func (m DetailModel) Update(msg tea.Msg) (DetailModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.adding {
+		switch keyMsg.Type {
+		case tea.KeyEnter:
+			m.adding = false
+			m.input.Blur()
+			title := strings.TrimSpace(m.input.Value())
+			m.input.SetValue("")
+			if title == "" {
+				return m, nil
+			}
+			m.task.Checklist = append(m.task.Checklist, task.Subtask{Title: title})
+			m.cursor = len(m.task.Checklist) - 1
+			return m, m.changed()
+		case tea.KeyEsc:
+			m.adding = false
+			m.input.Blur()
+			m.input.SetValue("")
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	items := m.task.Checklist
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		return m, func() tea.Msg { return backMsg{} }
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(items)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Add):
+		m.adding = true
+		return m, m.input.Focus()
+	case len(items) == 0:
+		// 以下操作都需要选中一项
+	case key.Matches(keyMsg, m.keys.Toggle):
+		items[m.cursor].Done = !items[m.cursor].Done
+		return m, m.changed()
+	case key.Matches(keyMsg, m.keys.Remove):
+		m.task.Checklist = append(items[:m.cursor:m.cursor], items[m.cursor+1:]...)
+		if m.cursor >= len(m.task.Checklist) && m.cursor > 0 {
+			m.cursor--
+		}
+		return m, m.changed()
+	case key.Matches(keyMsg, m.keys.MoveUp):
+		if m.cursor > 0 {
+			items[m.cursor-1], items[m.cursor] = items[m.cursor], items[m.cursor-1]
+			m.cursor--
+			return m, m.changed()
+		}
+	case key.Matches(keyMsg, m.keys.MoveDown):
+		if m.cursor < len(items)-1 {
+			items[m.cursor+1], items[m.cursor] = items[m.cursor], items[m.cursor+1]
+			m.cursor++
+			return m, m.changed()
+		}
+	}
+	return m, nil
+}
+
+func (m DetailModel) View() string {
+	var b strings.Builder
+	t := m.task
+	b.WriteString(common.TitleStyle.Render(i18n.T("detail.title")))
+	b.WriteString("\n\n" + lipgloss.NewStyle().Bold(true).Render(t.Title()) + "\n")
+	if labels := t.Labels(); labels != "" {
+		b.WriteString(helpStyle.Render(labels) + "\n")
+	}
+	if dates := strings.TrimSpace(t.Dates(time.Now()) + "  " + t.RecurrenceLabel()); dates != "" {
+		b.WriteString(helpStyle.Render(dates) + "\n")
+	}
+	if t.Detail != "" {
+		b.WriteString("\n" + t.Detail + "\n")
+	}
+	if p := t.PomodoroProgress(); p != "" {
+		b.WriteString("\n" + i18n.T("detail.pomodoros", p) + "\n")
+	}
+
+	b.WriteString("\n" + i18n.T("detail.checklist"))
+	if p := t.ChecklistProgress(); p != "" {
+		b.WriteString("  " + helpStyle.Render(p))
+	}
+	b.WriteRune('\n')
+	if len(t.Checklist) == 0 && !m.adding {
+		b.WriteString(helpStyle.Render("  "+i18n.T("detail.empty", keymap.HelpKey("detail.add"))) + "\n")
+	}
+	for i, s := range t.Checklist {
+		b.WriteString(subtaskLine(s, i == m.cursor && !m.adding) + "\n")
+	}
+
+	if m.adding {
+		b.WriteString("  " + m.input.View() + "\n")
+		b.WriteString("\n" + helpStyle.Render(i18n.T("detail.addHelp")))
+		return b.String()
+	}
+	b.WriteString("\n" + helpStyle.Render(i18n.T("detail.help", keymap.HelpKey("detail.toggle"), keymap.HelpKey("detail.add"),
+		keymap.HelpKey("detail.remove"), keymap.HelpKey("detail.moveUp"), keymap.HelpKey("detail.moveDown"), keymap.HelpKey("detail.back"))))
+	return b.String()
+}
+
+// subtaskLine 显示清单中的一项，已完成的项加删除线
+func subtaskLine(s task.Subtask, selected bool) string {
+	box, title := "[ ] ", s.Title
+	if s.Done {
+		box = "[x] "
+		title = lipgloss.NewStyle().Strikethrough(true).Foreground(common.CurrentTheme.Muted).Render(title)
+	}
+	if s.Pomodoros > 0 {
+		title += " " + helpStyle.Render(strings.Repeat("●", s.Pomodoros))
+	}
+	if selected {
+		return focusedStyle.Render("> "+box) + title
+	}
+	return "  " + box + title
+}
+
+// handleChecklist 保存详情视图中修改的清单，任务的其他字段保持不变
+func handleChecklist(m *App, msg checklistMsg) (tea.Model, tea.Cmd) {
+	t, ok := m.taskManager.Get(msg.id)
+	if !ok {
+		return m, nil
+	}
+	t.Checklist = msg.items
+	if err := m.taskManager.Update(t); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 保存清单失败: %v", err))
+	}
+	return m, m.updateListItem(t)
+}
+
+// ChecklistPromptModel 在番茄结束后询问这个番茄完成的是清单中的哪一项
+type ChecklistPromptModel struct {
+	task   task.Task
+	open   []int // 未完成的清单项在清单中的位置
+	cursor int
+	keys   *keymap.DetailViewKeyMap
+}
+
+// checklistPickMsg 记录提示的结果，skip 为 true 表示不记录
+type checklistPickMsg struct {
+	id    string
+	index int
+	done  bool
+	skip  bool
+}
+
+func NewChecklistPromptModel(t task.Task, keys *keymap.DetailViewKeyMap) ChecklistPromptModel {
+	return ChecklistPromptModel{task: t, open: t.OpenSubtasks(), keys: keys}
+}
+
+func (m ChecklistPromptModel) Update(msg tea.Msg) (ChecklistPromptModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	pick := func(done bool) tea.Cmd {
+		id, index := m.task.ID, m.open[m.cursor]
+		return func() tea.Msg { return checklistPickMsg{id: id, index: index, done: done} }
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Skip):
+		return m, func() tea.Msg { return checklistPickMsg{skip: true} }
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(m.open)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Pick):
+		return m, pick(false)
+	case key.Matches(keyMsg, m.keys.Done):
+		return m, pick(true)
+	}
+	return m, nil
+}
+
+func (m ChecklistPromptModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("prompt.title")))
+	b.WriteString("\n\n" + i18n.T("prompt.task", m.task.Title()) + "\n\n")
+	for i, index := range m.open {
+		b.WriteString(subtaskLine(m.task.Checklist[index], i == m.cursor) + "\n")
+	}
+	b.WriteString("\n" + helpStyle.Render(i18n.T("prompt.help",
+		keymap.HelpKey("prompt.pick"), keymap.HelpKey("prompt.done"), keymap.HelpKey("prompt.skip"))))
+	return b.String()
+}
+
+// offerChecklistPrompt 在工作会话结束后，若当前任务还有未完成的清单项则进入提示界面。
+// 只在计时和任务列表界面中提示，避免打断正在进行的编辑。
+func (m *App) offerChecklistPrompt() {
+	if !m.settingModel.Settings.Notifications.ChecklistPrompt {
+		return
+	}
+	t := m.currentTask()
+	if t == nil || len(t.OpenSubtasks()) == 0 {
+		return
+	}
+	if m.currentView != timeView && m.currentView != taskListView {
+		return
+	}
+	m.promptReturn = m.currentView
+	m.checklistPrompt = NewChecklistPromptModel(*t, m.detailModel.keys)
+	m.currentView = checklistPromptView
+}
+
+// handleChecklistPick 把番茄记录到选中的清单项，然后回到提示前的界面
+func handleChecklistPick(m *App, msg checklistPickMsg) (tea.Model, tea.Cmd) {
+	m.currentView = m.promptReturn
+	if msg.skip {
+		return m, nil
+	}
+	t, ok := m.taskManager.Get(msg.id)
+	if !ok || msg.index >= len(t.Checklist) {
+		return m, nil
+	}
+	item := &t.Checklist[msg.index]
+	item.Pomodoros++
+	if msg.done {
+		item.Done = true
+	}
+	if err := m.taskManager.Update(t); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 保存清单失败: %v", err))
+	}
+	return m, tea.Batch(m.updateListItem(t),
+		m.list.NewStatusMessage(statusMessageStyle(i18n.T("prompt.recorded", item.Title))))
+}
@@ -0,0 +1,86 @@
+package gomato
+
+import (
+	"gomato/pkg/keymap"
+	"gomato/pkg/task"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestDetailChecklist 测试在详情视图中添加、完成、调整顺序和删除清单项
+func TestDetailChecklist(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告")
+	manager := m.taskManager
+	m.detailModel = DetailModel{keys: keymap.NewDetailViewKeyMap()}
+
+	updateTaskListView(m, keyPress("v"))
+	if m.currentView != detailView {
+		t.Fatalf("未进入详情视图: %d", m.currentView)
+	}
+	// send 把按键交给 App，并处理详情视图发出的消息
+	send := func(msg tea.KeyMsg) {
+		_, cmd := m.Update(msg)
+		if cmd != nil {
+			if msg := cmd(); msg != nil {
+				m.Update(msg)
+			}
+		}
+	}
+	for _, title := range []string{"列提纲", "写初稿"} {
+		// 输入时只返回光标闪烁的命令，不需要执行
+		m.Update(keyPress("a"))
+		m.Update(keyPress(title))
+		send(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	send(keyPress("K")) // 把“写初稿”移到最前
+	send(keyPress("j"))
+	send(keyPress(" ")) // 完成“列提纲”
+
+	got := manager.Tasks[0].Checklist
+	if len(got) != 2 || got[0].Title != "写初稿" || got[1].Title != "列提纲" || !got[1].Done || got[0].Done {
+		t.Fatalf("清单: %+v", got)
+	}
+	if d := m.list.Items()[0].(task.Task).Description(); d != "☑ 1/2" {
+		t.Errorf("列表中的清单进度: %q", d)
+	}
+
+	send(keyPress("d"))
+	if got := manager.Tasks[0].Checklist; len(got) != 1 || got[0].Title != "写初稿" {
+		t.Errorf("删除后的清单: %+v", got)
+	}
+}
+
+// TestChecklistPromptAfterPomodoro 测试番茄结束后询问清单项并记录到选中的一项
+func TestChecklistPromptAfterPomodoro(t *testing.T) {
+	m, clock := newTickTestApp(1)
+	m.settingModel.Settings.Notifications.ChecklistPrompt = true
+	m.detailModel = DetailModel{keys: keymap.NewDetailViewKeyMap()}
+	m.taskManager.Tasks = []task.Task{{ID: "a", Name: "写报告", Checklist: []task.Subtask{
+		{Title: "列提纲", Done: true}, {Title: "写初稿"}, {Title: "校对"},
+	}}}
+	m.currentTaskID = "a"
+	m.currentView = timeView
+
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
+	if m.currentView != checklistPromptView {
+		t.Fatalf("工作会话结束后未询问清单项: view=%d", m.currentView)
+	}
+
+	_, cmd := m.Update(keyPress("j"))
+	if cmd != nil {
+		t.Fatal("移动光标不应产生命令")
+	}
+	_, cmd = m.Update(keyPress(" "))
+	m.Update(cmd())
+
+	item := m.taskManager.Tasks[0].Checklist[2]
+	if item.Title != "校对" || item.Pomodoros != 1 || !item.Done {
+		t.Errorf("记录结果: %+v", m.taskManager.Tasks[0].Checklist)
+	}
+	if m.currentView != timeView {
+		t.Errorf("记录后应回到计时界面: view=%d", m.currentView)
+	}
+}
@@ -0,0 +1,134 @@
+package gomato
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fieldKind int
+
+const (
+	toggleField fieldKind = iota // 开关，空格切换
+	choiceField                  // 单选，←/→ 或空格切换
+	textField                    // 文本输入
+)
+
+// formField 是设置表单中的一项
+type formField struct {
+	label   string
+	kind    fieldKind
+	on      bool     // toggleField 的值
+	options []string // choiceField 的选项
+	index   int      // choiceField 当前选中的序号
+	input   textinput.Model
+}
+
+func newToggleField(label string, on bool) formField {
+	return formField{label: label, kind: toggleField, on: on}
+}
+
+func newChoiceField(label string, options []string, index int) formField {
+	return formField{label: label, kind: choiceField, options: options, index: index}
+}
+
+func newTextField(label, value, placeholder string) formField {
+	t := textinput.New()
+	t.Cursor.Style = cursorStyle
+	t.Prompt = ""
+	t.Placeholder = placeholder
+	t.CharLimit = 256
+	t.Width = 40
+	t.SetValue(value)
+	return formField{label: label, kind: textField, input: t}
+}
+
+// settingsForm 是设置界面中一个标签页的表单，焦点只在本表单的字段间移动
+type settingsForm struct {
+	fields []formField
+	focus  int
+}
+
+// editing 表示焦点在文本输入框上，此时按键优先交给输入框
+func (f *settingsForm) editing() bool {
+	return len(f.fields) > 0 && f.fields[f.focus].kind == textField
+}
+
+// Update 处理按键，返回 false 表示该按键未被表单使用
+func (f *settingsForm) Update(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if len(f.fields) == 0 {
+		return nil, false
+	}
+	field := &f.fields[f.focus]
+	switch msg.String() {
+	case "tab", "down":
+		return f.setFocus(f.focus + 1), true
+	case "shift+tab", "up":
+		return f.setFocus(f.focus - 1), true
+	}
+
+	switch field.kind {
+	case toggleField:
+		if msg.String() == " " {
+			field.on = !field.on
+			return nil, true
+		}
+	case choiceField:
+		switch msg.String() {
+		case " ", "right", "l":
+			field.index = (field.index + 1) % len(field.options)
+			return nil, true
+		case "left", "h":
+			field.index = (field.index + len(field.options) - 1) % len(field.options)
+			return nil, true
+		}
+	case textField:
+		switch msg.String() {
+		case "enter", "esc", "ctrl+c":
+			return nil, false
+		}
+		var cmd tea.Cmd
+		field.input, cmd = field.input.Update(msg)
+		return cmd, true
+	}
+	return nil, false
+}
+
+func (f *settingsForm) setFocus(i int) tea.Cmd {
+	if f.fields[f.focus].kind == textField {
+		f.fields[f.focus].input.Blur()
+	}
+	f.focus = (i + len(f.fields)) % len(f.fields)
+	if f.fields[f.focus].kind == textField {
+		return f.fields[f.focus].input.Focus()
+	}
+	return nil
+}
+
+func (f *settingsForm) View() string {
+	var b strings.Builder
+	for i, field := range f.fields {
+		label := field.label + ": "
+		if i == f.focus {
+			label = focusedStyle.Render("> " + label)
+		} else {
+			label = "  " + label
+		}
+		b.WriteString(label)
+		switch field.kind {
+		case toggleField:
+			if field.on {
+				b.WriteString("[x]")
+			} else {
+				b.WriteString("[ ]")
+			}
+		case choiceField:
+			b.WriteString("< " + field.options[field.index] + " >")
+		case textField:
+			b.WriteString(field.input.View())
+		}
+		b.WriteRune('\n')
+	}
+	return b.String()
+}
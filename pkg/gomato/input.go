@@ -1,49 +1,207 @@
 package gomato
 
 import (
+	"errors"
 	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/notice"
+	"gomato/pkg/task"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// 任务表单中各输入框的下标
+const (
+	inputTitle = iota
+	inputDescription
+	inputEstimate
+	inputProject
+	inputTags
+	inputPriority
+	inputDue
+	inputScheduled
+	inputRepeat
+	inputCount
+)
+
+// TaskInputModel 是创建和编辑任务共用的表单
 type TaskInputModel struct {
 	inputs       []textinput.Model
 	focused      int
 	err          error
 	submitButton string
+	editID       string // 正在编辑的任务 ID，为空表示创建新任务
 }
 
-type taskCreatedMsg struct {
+// taskFields 是表单中可编辑的任务字段
+type taskFields struct {
 	title       string
 	description string
+	estimate    int
+	project     string
+	tags        []string
+	priority    task.Priority
+	due         time.Time
+	scheduled   time.Time
+	recur       task.Recurrence
+}
+
+// apply 把表单字段写入任务，不改动计时状态、番茄数等其他字段
+func (f taskFields) apply(t *task.Task) {
+	t.Name = f.title
+	t.Detail = f.description
+	t.Estimate = f.estimate
+	t.Project = f.project
+	t.Tags = f.tags
+	t.Priority = f.priority
+	t.Due = f.due
+	t.Scheduled = f.scheduled
+	t.Recur = f.recur
+}
+
+// fieldsOf 返回任务中表单可编辑的字段
+func fieldsOf(t task.Task) taskFields {
+	return taskFields{
+		title:       t.Name,
+		description: t.Detail,
+		estimate:    t.Estimate,
+		project:     t.Project,
+		tags:        t.Tags,
+		priority:    t.Priority,
+		due:         t.Due,
+		scheduled:   t.Scheduled,
+		recur:       t.Recur,
+	}
+}
+
+type taskCreatedMsg struct {
+	taskFields
+}
+
+// taskEditedMsg 在编辑表单提交时发送
+type taskEditedMsg struct {
+	id string
+	taskFields
 }
 
 type backMsg struct{}
 
 func NewTaskInputModel() TaskInputModel {
-	var inputs = make([]textinput.Model, 2)
-	inputs[0] = textinput.New()
-	inputs[0].Placeholder = "Title"
-	inputs[0].Focus()
-	inputs[0].CharLimit = 156
-	inputs[0].Width = 20
-
-	inputs[1] = textinput.New()
-	inputs[1].Placeholder = "Description"
-	inputs[1].CharLimit = 156
-	inputs[1].Width = 50
+	var inputs = make([]textinput.Model, inputCount)
+	inputs[inputTitle] = textinput.New()
+	inputs[inputTitle].Placeholder = i18n.T("input.title")
+	inputs[inputTitle].Focus()
+	inputs[inputTitle].CharLimit = 156
+	inputs[inputTitle].Width = 20
+
+	inputs[inputDescription] = textinput.New()
+	inputs[inputDescription].Placeholder = i18n.T("input.description")
+	inputs[inputDescription].CharLimit = 156
+	inputs[inputDescription].Width = 50
+
+	inputs[inputEstimate] = textinput.New()
+	inputs[inputEstimate].Placeholder = i18n.T("input.estimate")
+	inputs[inputEstimate].CharLimit = 2
+	inputs[inputEstimate].Width = 20
+
+	inputs[inputProject] = textinput.New()
+	inputs[inputProject].Placeholder = i18n.T("input.project")
+	inputs[inputProject].CharLimit = 64
+	inputs[inputProject].Width = 20
+
+	inputs[inputTags] = textinput.New()
+	inputs[inputTags].Placeholder = i18n.T("input.tags")
+	inputs[inputTags].CharLimit = 156
+	inputs[inputTags].Width = 50
+
+	inputs[inputPriority] = textinput.New()
+	inputs[inputPriority].Placeholder = i18n.T("input.priority")
+	inputs[inputPriority].CharLimit = 8
+	inputs[inputPriority].Width = 30
+
+	inputs[inputDue] = textinput.New()
+	inputs[inputDue].Placeholder = i18n.T("input.due")
+	inputs[inputDue].CharLimit = 32
+	inputs[inputDue].Width = 50
+
+	inputs[inputScheduled] = textinput.New()
+	inputs[inputScheduled].Placeholder = i18n.T("input.scheduled")
+	inputs[inputScheduled].CharLimit = 32
+	inputs[inputScheduled].Width = 50
+
+	inputs[inputRepeat] = textinput.New()
+	inputs[inputRepeat].Placeholder = i18n.T("input.repeat")
+	inputs[inputRepeat].CharLimit = 64
+	inputs[inputRepeat].Width = 60
 
 	return TaskInputModel{
 		inputs:       inputs,
 		focused:      0,
 		err:          nil,
-		submitButton: "Create",
+		submitButton: i18n.T("input.submit"),
 	}
 }
 
+// NewTaskEditModel returns the task form pre-filled with the fields of t,
+// which saves the changes to t instead of creating a new task.
+func NewTaskEditModel(t task.Task) TaskInputModel {
+	m := NewTaskInputModel()
+	m.inputs[inputTitle].SetValue(t.Name)
+	m.inputs[inputDescription].SetValue(t.Detail)
+	if t.Estimate > 0 {
+		m.inputs[inputEstimate].SetValue(strconv.Itoa(t.Estimate))
+	}
+	m.inputs[inputProject].SetValue(t.Project)
+	m.inputs[inputTags].SetValue(strings.Join(t.Tags, ", "))
+	m.inputs[inputPriority].SetValue(t.Priority.String())
+	m.inputs[inputDue].SetValue(task.FormatDate(t.Due))
+	m.inputs[inputScheduled].SetValue(task.FormatDate(t.Scheduled))
+	m.inputs[inputRepeat].SetValue(t.Recur.String())
+	m.submitButton = i18n.T("input.save")
+	m.editID = t.ID
+	return m
+}
+
+// fields 解析表单内容，预估番茄数、优先级、日期或重复规则无法解析时返回错误。
+// 相对日期以 now 为准。
+func (m TaskInputModel) fields(now time.Time) (taskFields, error) {
+	f := taskFields{
+		title:       m.inputs[inputTitle].Value(),
+		description: m.inputs[inputDescription].Value(),
+		project:     strings.TrimSpace(m.inputs[inputProject].Value()),
+		tags:        task.ParseTags(m.inputs[inputTags].Value()),
+	}
+	if v := strings.TrimSpace(m.inputs[inputEstimate].Value()); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, errors.New(i18n.T("input.badEstimate", v))
+		}
+		f.estimate = n
+	}
+	p, err := task.ParsePriority(m.inputs[inputPriority].Value())
+	if err != nil {
+		return f, errors.New(i18n.T("input.badPriority", m.inputs[inputPriority].Value()))
+	}
+	f.priority = p
+	if f.due, err = task.ParseDate(m.inputs[inputDue].Value(), now); err != nil {
+		return f, errors.New(i18n.T("input.badDate", strings.TrimSpace(m.inputs[inputDue].Value())))
+	}
+	if f.scheduled, err = task.ParseDate(m.inputs[inputScheduled].Value(), now); err != nil {
+		return f, errors.New(i18n.T("input.badDate", strings.TrimSpace(m.inputs[inputScheduled].Value())))
+	}
+	if f.recur, err = task.ParseRecurrence(m.inputs[inputRepeat].Value()); err != nil {
+		return f, errors.New(i18n.T("input.badRepeat", strings.TrimSpace(m.inputs[inputRepeat].Value())))
+	}
+	return f, nil
+}
+
 func (m TaskInputModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -57,12 +215,16 @@ func (m TaskInputModel) Update(msg tea.Msg) (TaskInputModel, tea.Cmd) {
 		switch msg.Type {
 		case tea.KeyEnter:
 			if m.focused == len(m.inputs) {
-				return m, func() tea.Msg {
-					return taskCreatedMsg{
-						title:       m.inputs[0].Value(),
-						description: m.inputs[1].Value(),
-					}
+				f, err := m.fields(time.Now())
+				m.err = err
+				if err != nil {
+					return m, nil
+				}
+				if m.editID != "" {
+					id := m.editID
+					return m, func() tea.Msg { return taskEditedMsg{id: id, taskFields: f} }
 				}
+				return m, func() tea.Msg { return taskCreatedMsg{taskFields: f} }
 			}
 			m.nextInput()
 		case tea.KeyCtrlC, tea.KeyEsc:
@@ -85,8 +247,9 @@ func (m TaskInputModel) Update(msg tea.Msg) (TaskInputModel, tea.Cmd) {
 func (m *TaskInputModel) nextInput() {
 	m.focused = (m.focused + 1) % (len(m.inputs) + 1)
 	if m.focused == len(m.inputs) {
-		m.inputs[0].Blur()
-		m.inputs[1].Blur()
+		for i := range m.inputs {
+			m.inputs[i].Blur()
+		}
 		return
 	}
 	for i := 0; i <= len(m.inputs)-1; i++ {
@@ -107,8 +270,9 @@ func (m *TaskInputModel) prevInput() {
 	}
 
 	if m.focused == len(m.inputs) {
-		m.inputs[0].Blur()
-		m.inputs[1].Blur()
+		for i := range m.inputs {
+			m.inputs[i].Blur()
+		}
 		return
 	}
 
@@ -126,7 +290,11 @@ func (m *TaskInputModel) prevInput() {
 func (m TaskInputModel) View() string {
 	var b strings.Builder
 
-	b.WriteString("Create a new task\n\n")
+	heading := i18n.T("input.heading")
+	if m.editID != "" {
+		heading = i18n.T("input.editHeading")
+	}
+	b.WriteString(heading + "\n\n")
 
 	for i := range m.inputs {
 		b.WriteString(m.inputs[i].View())
@@ -141,8 +309,11 @@ func (m TaskInputModel) View() string {
 	}
 
 	fmt.Fprintf(&b, "\n\n%s\n\n", button)
+	if m.err != nil {
+		b.WriteString(lipgloss.NewStyle().Foreground(common.CurrentTheme.Warning).Render(m.err.Error()) + "\n\n")
+	}
 
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("(press esc to cancel)"))
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(i18n.T("input.cancel")))
 
 	return b.String()
 }
@@ -150,24 +321,22 @@ func (m TaskInputModel) View() string {
 func handleBack(m *App) (tea.Model, tea.Cmd) {
 	if m.currentView == settingView {
 		// Apply new settings to all timers when returning from settings
-		newDuration := int(m.settingModel.Settings.Pomodoro) * 60
+		now := m.now()
+		cfg := m.settingModel.Settings.TimerConfig()
 
-		// Update the main/active timer model
-		m.timeModel.TimerDuration = newDuration
-		if m.timeModel.TimerRemaining > newDuration || !m.timeModel.TimerIsRunning {
-			m.timeModel.TimerRemaining = newDuration
-		}
+		// Update the main/active timer
+		m.engine.SetConfig(cfg)
 
 		// Update the timer for all individual tasks
 		for i := range m.taskManager.Tasks {
 			taskTimer := &m.taskManager.Tasks[i].Timer
-			taskTimer.TimerDuration = newDuration
-			if taskTimer.TimerRemaining > newDuration || !taskTimer.TimerIsRunning {
-				taskTimer.TimerRemaining = newDuration
-			}
+			*taskTimer = task.NewTimeModel(cfg.Apply(taskTimer.Snapshot(0), now), now)
 		}
 		// Persist the changes to the tasks file
-		m.taskManager.Save()
+		m.saveCurrentTimer()
+		m.notifier = notice.FromSettings(m.settingModel.Settings.Notifications, os.Stderr)
+		m.applyLanguage()
+		m.applyAppearance()
 	}
 	m.currentView = taskListView
 	m.taskInput = NewTaskInputModel()
@@ -0,0 +1,228 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ListsModel 列出所有任务列表，可以切换到其他列表或新建列表
+type ListsModel struct {
+	lists   []listInfo
+	current string // 当前打开的列表
+	cursor  int
+	adding  bool // 正在输入新列表的名称
+	input   textinput.Model
+	status  string
+	keys    *keymap.ListsViewKeyMap
+}
+
+// listInfo 是切换视图中显示的一个列表
+type listInfo struct {
+	name string
+	open int // 未完成的任务数
+}
+
+// listSwitchMsg 请求 App 切换到名为 name 的列表，列表不存在时新建
+type listSwitchMsg struct {
+	name string
+}
+
+func NewListsModel(keys *keymap.ListsViewKeyMap) ListsModel {
+	input := textinput.New()
+	input.Placeholder = i18n.T("lists.newName")
+	input.CharLimit = 64
+	input.Width = 40
+	return ListsModel{input: input, keys: keys}
+}
+
+// Reload 重新读取所有列表，并把光标移到当前列表
+func (m *ListsModel) Reload(current string) {
+	m.current = current
+	m.lists = nil
+	names, err := task.Lists()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Task] 读取任务列表失败: %v", err))
+		names = []string{task.DefaultList}
+	}
+	for i, name := range names {
+		info := listInfo{name: name}
+		if manager, err := task.NewManager(name); err == nil {
+			for _, t := range manager.Tasks {
+				if !t.Done {
+					info.open++
+				}
+			}
+		}
+		if name == current {
+			m.cursor = i
+		}
+		m.lists = append(m.lists, info)
+	}
+}
+
+func (m ListsModel) Update(msg tea.Msg) (ListsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.adding {
+		switch keyMsg.Type {
+		case tea.KeyEnter:
+			name := m.input.Value()
+			if err := task.ValidateListName(name); err != nil {
+				m.status = i18n.T("lists.invalid", err)
+				return m, nil
+			}
+			m.adding = false
+			m.input.Blur()
+			m.input.SetValue("")
+			m.status = ""
+			return m, func() tea.Msg { return listSwitchMsg{name: name} }
+		case tea.KeyEsc:
+			m.adding = false
+			m.input.Blur()
+			m.input.SetValue("")
+			m.status = ""
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		m.status = ""
+		return m, func() tea.Msg { return backMsg{} }
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(m.lists)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.New):
+		m.adding = true
+		m.status = ""
+		return m, m.input.Focus()
+	case key.Matches(keyMsg, m.keys.Choose):
+		if len(m.lists) > 0 {
+			name := m.lists[m.cursor].name
+			return m, func() tea.Msg { return listSwitchMsg{name: name} }
+		}
+	}
+	return m, nil
+}
+
+func (m ListsModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("lists.title")))
+	b.WriteString("\n\n")
+	for i, l := range m.lists {
+		line := fmt.Sprintf("%s  %s", l.name, helpStyle.Render(i18n.T("lists.open", l.open)))
+		if l.name == m.current {
+			line += "  " + focusedStyle.Render(i18n.T("lists.current"))
+		}
+		if i == m.cursor && !m.adding {
+			b.WriteString(focusedStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if m.adding {
+		b.WriteString("\n" + m.input.View() + "\n")
+	}
+
+	if m.status != "" {
+		b.WriteString("\n" + statusMessageStyle(m.status) + "\n")
+	}
+	if m.adding {
+		b.WriteString("\n" + helpStyle.Render(i18n.T("lists.addHelp")))
+	} else {
+		b.WriteString("\n" + helpStyle.Render(i18n.T("lists.help", keymap.HelpKey("lists.choose"),
+			keymap.HelpKey("lists.new"), keymap.HelpKey("lists.back"))))
+	}
+	return b.String()
+}
+
+// handleListSwitch 切换到所选的列表，然后回到任务列表
+func handleListSwitch(m *App, msg listSwitchMsg) (tea.Model, tea.Cmd) {
+	if msg.name == m.taskManager.List() {
+		m.currentView = taskListView
+		return m, nil
+	}
+	if err := m.switchList(msg.name); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 切换到任务列表 %s 失败: %v", msg.name, err))
+		m.listsModel.status = i18n.T("lists.switchFailed", msg.name, err)
+		return m, nil
+	}
+	m.currentView = taskListView
+	return m, m.list.NewStatusMessage(statusMessageStyle(i18n.T("lists.switched", msg.name)))
+}
+
+// loadTasks 打开任务列表。未开始的计时器采用当前设置的时长；
+// 保留未完成的会话，但程序未运行期间不应继续计时
+func loadTasks(list string, cfg timer.Config, now time.Time) (*task.Manager, error) {
+	manager, err := task.NewManager(list)
+	if err != nil {
+		return nil, err
+	}
+	for i := range manager.Tasks {
+		e := timer.New(cfg, nil)
+		e.Restore(manager.Tasks[i].Timer.Snapshot(0))
+		e.Pause()
+		e.SetConfig(cfg)
+		manager.Tasks[i].Timer = task.NewTimeModel(e.Snapshot(), now)
+	}
+	return manager, nil
+}
+
+// switchList 切换到另一个任务列表。本地计时的会话先暂停并保存到原任务；
+// 守护进程中的会话不受影响，继续在后台计时。
+func (m *App) switchList(name string) error {
+	if m.remote == nil && m.engine.State() == timer.StateRunning {
+		m.pauseTimer()
+	}
+	return m.openList(name)
+}
+
+// openList 打开任务列表并记住它，下次启动时仍打开该列表；列表不存在时新建。
+// 撤销记录只对原列表有效，因此一并清空。
+func (m *App) openList(name string) error {
+	manager, err := loadTasks(name, m.settingModel.Settings.TimerConfig(), m.now())
+	if err != nil {
+		return err
+	}
+	if !task.ListExists(name) {
+		// 立即保存，使空列表也出现在列表切换视图和命令行中
+		if err := manager.Save(); err != nil {
+			return err
+		}
+	}
+	m.taskManager = manager
+	m.currentTaskID = ""
+	m.undo = undoStack{}
+	m.list.ResetFilter()
+	m.list.Title = m.listTitle()
+	m.refreshList()
+	m.list.Select(0)
+	m.purgeExpiredTrash()
+
+	m.settingModel.Settings.TaskList = manager.List()
+	if err := m.settingModel.Settings.Save(); err != nil {
+		logging.Log(fmt.Sprintf("[Setting] 保存设置失败: %v", err))
+	}
+	return nil
+}
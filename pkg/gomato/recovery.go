@@ -0,0 +1,201 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/safefile"
+	"gomato/pkg/task"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RecoveryModel 在启动时发现任务文件损坏，询问是否从最新的有效备份恢复。
+// 用户选择之前不会写入任务文件。
+type RecoveryModel struct {
+	err    *task.CorruptError
+	backup *task.Backup // 最新的有效备份，为空表示没有可用的备份
+	keys   *keymap.ConfirmKeyMap
+	status string // 恢复失败时的提示
+}
+
+type recoveryMsg struct {
+	accept bool
+}
+
+func NewRecoveryModel(err *task.CorruptError, keys *keymap.ConfirmKeyMap) RecoveryModel {
+	m := RecoveryModel{err: err, keys: keys}
+	backups, lerr := task.ListBackups(err.List)
+	if lerr != nil {
+		logging.Log(fmt.Sprintf("[Task] 读取任务列表 %s 的备份失败: %v", err.List, lerr))
+	}
+	if len(backups) > 0 {
+		m.backup = &backups[0]
+	}
+	return m
+}
+
+func (m RecoveryModel) Update(msg tea.Msg) (RecoveryModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Yes):
+		return m, func() tea.Msg { return recoveryMsg{accept: true} }
+	case key.Matches(keyMsg, m.keys.No):
+		return m, func() tea.Msg { return recoveryMsg{accept: false} }
+	}
+	return m, nil
+}
+
+func (m RecoveryModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("recovery.title")))
+	b.WriteString("\n\n" + i18n.T("recovery.detected", m.err.List) + "\n\n")
+	b.WriteString(i18n.T("recovery.file", m.err.Path) + "\n")
+	b.WriteString(i18n.T("recovery.error", m.err.Err) + "\n\n")
+	yes, no := keymap.HelpKey("confirm.yes"), keymap.HelpKey("confirm.no")
+	if m.backup != nil {
+		b.WriteString(i18n.T("recovery.backup", m.backup.Time.Format("2006-01-02 15:04:05"), m.backup.Tasks) + "\n\n")
+		b.WriteString(statusMessageStyle(i18n.T("recovery.prompt", yes, no)))
+	} else {
+		b.WriteString(i18n.T("recovery.noBackup") + "\n\n")
+		b.WriteString(statusMessageStyle(i18n.T("recovery.promptEmpty", yes, no)))
+	}
+	if m.status != "" {
+		b.WriteString("\n\n" + m.status)
+	}
+	return b.String()
+}
+
+// handleRecovery 按用户选择恢复损坏的任务文件；放弃时直接退出，文件保持原样
+func handleRecovery(m *App, msg recoveryMsg) (tea.Model, tea.Cmd) {
+	if !msg.accept {
+		return m, tea.Quit
+	}
+	r := &m.recoveryModel
+	backup := ""
+	if r.backup != nil {
+		backup = r.backup.Path
+	}
+	aside, err := task.Recover(r.err.List, backup)
+	if err == nil {
+		err = m.openList(r.err.List)
+	}
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Task] 恢复任务列表 %s 失败: %v", r.err.List, err))
+		r.status = i18n.T("recovery.failed", err)
+		return m, nil
+	}
+	logging.Log(fmt.Sprintf("[Task] 任务列表 %s 已恢复，损坏的文件保存为 %s", r.err.List, aside))
+
+	status := i18n.T("recovery.restored", aside)
+	if r.backup == nil {
+		status = i18n.T("recovery.emptied", aside)
+	}
+	m.currentView = taskListView
+	cmd := m.list.NewStatusMessage(statusMessageStyle(status))
+	// 恢复之前没有打开任务，启动时跳过的守护进程连接和会话恢复在这里进行
+	if m.attachDaemon() {
+		return m, tea.Batch(cmd, m.startTicking())
+	}
+	m.offerResume(r.keys, m.now())
+	return m, cmd
+}
+
+// SettingsRecoveryModel 在启动时发现设置文件损坏，询问是否从最新的有效备份恢复。
+// 用户选择之前不会写入设置文件，也不会打开任务列表。
+type SettingsRecoveryModel struct {
+	err    *common.SettingsCorruptError
+	backup string // 最新的有效备份，为空表示没有可用的备份
+	keys   *keymap.ConfirmKeyMap
+	status string // 恢复失败时的提示
+}
+
+type settingsRecoveryMsg struct {
+	accept bool
+}
+
+func NewSettingsRecoveryModel(err *common.SettingsCorruptError, keys *keymap.ConfirmKeyMap) SettingsRecoveryModel {
+	m := SettingsRecoveryModel{err: err, keys: keys}
+	backups, lerr := common.SettingsBackups()
+	if lerr != nil {
+		logging.Log(fmt.Sprintf("[Setting] 读取设置的备份失败: %v", lerr))
+	}
+	if len(backups) > 0 {
+		m.backup = backups[0]
+	}
+	return m
+}
+
+func (m SettingsRecoveryModel) Update(msg tea.Msg) (SettingsRecoveryModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Yes):
+		return m, func() tea.Msg { return settingsRecoveryMsg{accept: true} }
+	case key.Matches(keyMsg, m.keys.No):
+		return m, func() tea.Msg { return settingsRecoveryMsg{accept: false} }
+	}
+	return m, nil
+}
+
+func (m SettingsRecoveryModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("recovery.settingsTitle")))
+	b.WriteString("\n\n" + i18n.T("recovery.settingsDetected") + "\n\n")
+	b.WriteString(i18n.T("recovery.file", m.err.Path) + "\n")
+	b.WriteString(i18n.T("recovery.error", m.err.Err) + "\n\n")
+	yes, no := keymap.HelpKey("confirm.yes"), keymap.HelpKey("confirm.no")
+	if m.backup != "" {
+		at, _ := safefile.BackupTime(m.backup)
+		b.WriteString(i18n.T("recovery.settingsBackup", at.Format("2006-01-02 15:04:05")) + "\n\n")
+		b.WriteString(statusMessageStyle(i18n.T("recovery.prompt", yes, no)))
+	} else {
+		b.WriteString(i18n.T("recovery.noBackup") + "\n\n")
+		b.WriteString(statusMessageStyle(i18n.T("recovery.promptDefaults", yes, no)))
+	}
+	if m.status != "" {
+		b.WriteString("\n\n" + m.status)
+	}
+	return b.String()
+}
+
+// handleSettingsRecovery 按用户选择恢复损坏的设置文件；放弃时直接退出，文件保持原样。
+// 恢复后按新的设置重新启动，与正常启动一样打开任务列表并检查任务文件和未完成的会话
+func handleSettingsRecovery(m *App, msg settingsRecoveryMsg) (tea.Model, tea.Cmd) {
+	if !msg.accept {
+		return m, tea.Quit
+	}
+	r := &m.settingRecovery
+	aside, err := common.RecoverSettings(r.backup)
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Setting] 恢复设置失败: %v", err))
+		r.status = i18n.T("recovery.failed", err)
+		return m, nil
+	}
+	logging.Log(fmt.Sprintf("[Setting] 设置已恢复，损坏的文件保存为 %s", aside))
+
+	status := i18n.T("recovery.settingsRestored", aside)
+	if r.backup == "" {
+		status = i18n.T("recovery.settingsDefaults", aside)
+	}
+	app := NewApp()
+	app.clock = m.clock
+	if m.width > 0 {
+		app.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	}
+	// 提醒的定时检查仍在进行，不再重新开始
+	cmds := []tea.Cmd{app.checkReminders(), app.list.NewStatusMessage(statusMessageStyle(status))}
+	if app.remote != nil {
+		cmds = append(cmds, app.startTicking())
+	}
+	return app, tea.Batch(cmds...)
+}
@@ -0,0 +1,48 @@
+package gomato
+
+import (
+	"gomato/pkg/notice"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reminderInterval 是检查任务截止日期的间隔
+const reminderInterval = 30 * time.Second
+
+// reminderMsg 定期触发截止日期检查，与计时器的 tick 链相互独立
+type reminderMsg struct{}
+
+func remindLater() tea.Cmd {
+	return tea.Tick(reminderInterval, func(time.Time) tea.Msg {
+		return reminderMsg{}
+	})
+}
+
+// checkReminders 为即将到期的任务发送通知。每个截止日期只提醒一次，
+// 修改截止日期后会重新提醒。
+func (m *App) checkReminders() tea.Cmd {
+	lead := time.Duration(m.settingModel.Settings.Notifications.DueBefore) * time.Minute
+	if lead == 0 {
+		return nil
+	}
+	if m.reminded == nil {
+		m.reminded = map[string]time.Time{}
+	}
+	now := m.now()
+	var cmds []tea.Cmd
+	for _, t := range m.taskManager.Tasks {
+		if !t.DueSoon(now, lead) || m.reminded[t.ID].Equal(t.Due) {
+			continue
+		}
+		m.reminded[t.ID] = t.Due
+		title, message := notice.ForDue(t)
+		m.notifier.Send(title, message)
+		cmds = append(cmds, m.list.NewStatusMessage(statusMessageStyle(message)))
+	}
+	return tea.Batch(cmds...)
+}
+
+func handleReminder(m *App) (tea.Model, tea.Cmd) {
+	return m, tea.Batch(m.checkReminders(), remindLater())
+}
@@ -0,0 +1,94 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/daemon"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// 守护进程运行时，TUI 作为客户端连接：计时操作转发给守护进程，
+// 本地状态机只是守护进程状态的镜像，用于显示，不记录历史也不写状态文件。
+
+// attachDaemon 尝试连接守护进程，成功时同步其当前状态
+func (m *App) attachDaemon() bool {
+	c, err := daemon.DialDefault()
+	if err != nil {
+		return false
+	}
+	m.remote = c
+	if err := m.syncRemote(); err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 同步守护进程状态失败: %v", err))
+		m.detachDaemon()
+		return false
+	}
+	logging.Log("[Daemon] 已连接守护进程")
+	return true
+}
+
+// detachDaemon 断开与守护进程的连接，此后回到本地计时
+func (m *App) detachDaemon() {
+	if m.remote != nil {
+		m.remote.Close()
+		m.remote = nil
+	}
+}
+
+// remoteDo 把计时操作发送给守护进程，并用返回的状态更新显示
+func (m *App) remoteDo(cmd, taskRef string) {
+	state, err := m.remote.Do(daemon.Request{Cmd: cmd, Task: taskRef, List: m.taskManager.List()})
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 请求 %s 失败: %v", cmd, err))
+		m.pendingCmds = append(m.pendingCmds, m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.daemonError", err))))
+		return
+	}
+	m.applyRemote(state)
+}
+
+func (m *App) syncRemote() error {
+	state, err := m.remote.Status()
+	if err != nil {
+		return err
+	}
+	m.applyRemote(state)
+	return nil
+}
+
+// applyRemote 用守护进程的状态覆盖本地状态机和当前任务。
+// 守护进程计时的任务不在当前打开的列表中时，不选中任何任务。
+func (m *App) applyRemote(state *task.ActiveState) {
+	if state == nil {
+		return
+	}
+	// 守护进程把完成的番茄数写入任务文件，先重新读取，之后保存时才不会覆盖
+	m.reloadTasks()
+	m.engine.Restore(state.Snapshot())
+	if state.ListName() != m.taskManager.List() {
+		m.currentTaskID = ""
+		return
+	}
+	if i := m.taskManager.StateTask(*state); i >= 0 {
+		m.currentTaskID = m.taskManager.Tasks[i].ID
+		m.taskManager.Tasks[i].Timer = state.Timer
+	}
+}
+
+// handleRemoteTick 每秒向守护进程同步一次状态，以便显示其他客户端的操作
+func handleRemoteTick(m *App, msg tickMsg) tea.Cmd {
+	if msg.gen != m.tickGen {
+		return nil
+	}
+	if err := m.syncRemote(); err != nil {
+		logging.Log(fmt.Sprintf("[Daemon] 与守护进程的连接已断开: %v", err))
+		m.detachDaemon()
+		// 本地状态机接管最后同步到的会话
+		return tea.Batch(
+			m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.daemonLost"))),
+			m.startTicking(),
+		)
+	}
+	return tick(msg.gen)
+}
@@ -0,0 +1,113 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ResumeModel 在启动时询问是否恢复上次未完成的会话
+type ResumeModel struct {
+	state task.ActiveState
+	keys  *keymap.ConfirmKeyMap
+	now   time.Time
+}
+
+type resumeMsg struct {
+	accept bool
+}
+
+func NewResumeModel(state task.ActiveState, keys *keymap.ConfirmKeyMap, now time.Time) ResumeModel {
+	return ResumeModel{state: state, keys: keys, now: now}
+}
+
+func (m ResumeModel) Update(msg tea.Msg) (ResumeModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Yes):
+		return m, func() tea.Msg { return resumeMsg{accept: true} }
+	case key.Matches(keyMsg, m.keys.No):
+		return m, func() tea.Msg { return resumeMsg{accept: false} }
+	}
+	return m, nil
+}
+
+func (m ResumeModel) View() string {
+	var b strings.Builder
+	tm := m.state.Timer
+
+	b.WriteString(common.TitleStyle.Render(i18n.T("resume.title")))
+	b.WriteString("\n\n" + i18n.T("resume.detected") + "\n\n")
+	b.WriteString(i18n.T("resume.task", m.state.TaskName) + "\n")
+	b.WriteString(i18n.T("resume.type", sessionTypeLabel(tm.Type())) + "\n")
+
+	remaining := m.state.Snapshot().RemainingAt(m.now)
+	switch {
+	case tm.TimerIsRunning && remaining == 0:
+		b.WriteString(i18n.T("resume.ended", tm.Deadline.Format("15:04")) + "\n")
+	case tm.TimerIsRunning:
+		b.WriteString(i18n.T("resume.running", formatClock(remaining)) + "\n")
+	default:
+		b.WriteString(i18n.T("resume.paused", formatClock(remaining)) + "\n")
+	}
+	b.WriteString(i18n.T("resume.cycles", m.state.CycleCount) + "\n\n")
+
+	b.WriteString(statusMessageStyle(i18n.T("resume.prompt", keymap.HelpKey("confirm.yes"), keymap.HelpKey("confirm.no"))))
+	return b.String()
+}
+
+// sessionTypeLabel 返回会话类型的显示名称
+func sessionTypeLabel(t task.SessionType) string {
+	switch t {
+	case task.SessionShortBreak:
+		return i18n.T("session.shortBreak")
+	case task.SessionLongBreak:
+		return i18n.T("session.longBreak")
+	default:
+		return i18n.T("session.work")
+	}
+}
+
+// formatClock 以 MM:SS 的形式显示剩余时间
+func formatClock(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// handleResume 根据用户选择恢复或放弃上次未完成的会话
+func handleResume(m *App, msg resumeMsg) (tea.Model, tea.Cmd) {
+	state := m.resumeModel.state
+	if i := m.taskManager.StateTask(state); i >= 0 {
+		m.currentTaskID = m.taskManager.Tasks[i].ID
+		m.list.Select(m.listIndex(m.currentTaskID))
+	}
+
+	if msg.accept {
+		m.engine.Restore(state.Snapshot())
+		m.currentView = timeView
+		if m.engine.State() == timer.StateRunning {
+			// 截止时间若已过去，下一次 tick 会按截止时间结束该会话并进入下一阶段
+			return m, m.startTicking()
+		}
+		m.saveCurrentTimer()
+		return m, nil
+	}
+
+	// 放弃：记录为未完成的会话，并从新的周期开始
+	state.CycleCount = 0
+	m.engine.Restore(state.Snapshot())
+	m.resetTimer()
+	m.currentView = taskListView
+	return m, nil
+}
@@ -1,8 +1,11 @@
 package gomato
 
 import (
+	"errors"
 	"fmt"
 	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
 	"strconv"
 	"strings"
 
@@ -25,17 +28,54 @@ const (
 	languageOpt = 5 // 新增语言选项索引
 )
 
+// 设置界面的标签页
+const (
+	generalTab = iota
+	timerTab
+	appearanceTab
+	notificationsTab
+)
+
+// 通知标签页中各字段的序号
+const (
+	notifyWorkEnd = iota
+	notifyBreakEnd
+	notifyCycleEnd
+	notifyWarnBefore
+	notifyDesktop
+	notifySound
+	notifySoundFile
+	notifyBell
+	notifyOSC
+	notifyCommand
+	notifyFile
+	notifyChecklist
+	notifyDueBefore
+)
+
+// 外观标签页中各字段的序号
+const (
+	appearanceTheme = iota
+	appearanceFont
+	appearanceCompact
+)
+
 var (
 	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 	blurredStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	cursorStyle  = focusedStyle
 	noStyle      = lipgloss.NewStyle()
 	helpStyle    = blurredStyle
-
-	focusedButton = focusedStyle.Render("[ Submit ]")
-	blurredButton = fmt.Sprintf("[ %s ]", blurredStyle.Render("Submit"))
 )
 
+// submitButton 用当前界面语言和主题渲染保存按钮，focused 表示按钮获得焦点
+func submitButton(focused bool) string {
+	if focused {
+		return focusedStyle.Render("[ " + i18n.T("settings.submit") + " ]")
+	}
+	return fmt.Sprintf("[ %s ]", blurredStyle.Render(i18n.T("settings.submit")))
+}
+
 type SettingModel struct {
 	Tabs               []string
 	ActiveTab          int
@@ -45,24 +85,30 @@ type SettingModel struct {
 	Settings           common.Settings
 	timeDisplayOptions []string
 	timeDisplayIndex   int
-	languageOptions    []string // 新增语言选项
+	languages          []string // 可选语言的代码，见 i18n.Languages
+	languageOptions    []string // 可选语言的显示名称
 	languageIndex      int      // 当前语言索引
+	notifyForm         settingsForm
+	appearanceForm     settingsForm
+
+	status  string                       // 设置无法保存时的提示
+	corrupt *common.SettingsCorruptError // 设置文件损坏时不为空，此时使用默认设置
 }
 
 func NewSettingModel() SettingModel {
-	tabs := []string{"General", "Timer", "Appearance", "Notifications"}
-
 	settings, err := common.LoadSettings()
-	if err != nil {
+	var corrupt *common.SettingsCorruptError
+	if errors.As(err, &corrupt) {
+		logging.Log(fmt.Sprintf("[Setting] %v", err))
+	} else if err != nil {
 		fmt.Println("could not load settings:", err)
 	}
 
 	m := SettingModel{
-		Tabs:               tabs,
-		inputs:             make([]textinput.Model, 4),
-		Settings:           settings,
-		timeDisplayOptions: []string{"ANSI艺术显示", "普通数字显示"},
-		languageOptions:    []string{"中文", "English"},
+		inputs:    make([]textinput.Model, 4),
+		Settings:  settings,
+		languages: i18n.Languages(),
+		corrupt:   corrupt,
 	}
 
 	// 设置时间显示方式的初始索引
@@ -71,13 +117,7 @@ func NewSettingModel() SettingModel {
 	} else {
 		m.timeDisplayIndex = 0 // 默认ANSI
 	}
-
-	// 设置语言的初始索引
-	if m.Settings.Language == "en" {
-		m.languageIndex = 1
-	} else {
-		m.languageIndex = 0 // 默认中文
-	}
+	m.languageIndex = m.indexOfLanguage(m.Settings.Language)
 
 	var t textinput.Model
 	for i := range m.inputs {
@@ -99,29 +139,58 @@ func NewSettingModel() SettingModel {
 			t.SetValue(fmt.Sprintf("%d", m.Settings.Pomodoro))
 			t.Placeholder = "25"
 			t.Focus()
-			t.Prompt = "Pomodoro: "
 			t.PromptStyle = focusedStyle
 			t.TextStyle = focusedStyle
 		case shortBreak:
 			t.SetValue(fmt.Sprintf("%d", m.Settings.ShortBreak))
 			t.Placeholder = "5"
-			t.Prompt = "Short Break: "
 		case longBreak:
 			t.SetValue(fmt.Sprintf("%d", m.Settings.LongBreak))
 			t.Placeholder = "15"
-			t.Prompt = "Long Break: "
 		case cycle:
 			t.SetValue(fmt.Sprintf("%d", m.Settings.Cycle))
 			t.Placeholder = "4"
-			t.Prompt = "Cycle (每周期工作/短休息次数): "
 		}
 
 		m.inputs[i] = t
 	}
+	m.setLabels()
 
 	return m
 }
 
+// setLabels 用当前界面语言设置标签页、输入框和选项的文字，并重建表单
+func (m *SettingModel) setLabels() {
+	m.Tabs = []string{
+		i18n.T("settings.tab.general"),
+		i18n.T("settings.tab.timer"),
+		i18n.T("settings.tab.appearance"),
+		i18n.T("settings.tab.notifications"),
+	}
+	m.inputs[pomodoro].Prompt = i18n.T("settings.pomodoro")
+	m.inputs[shortBreak].Prompt = i18n.T("settings.shortBreak")
+	m.inputs[longBreak].Prompt = i18n.T("settings.longBreak")
+	m.inputs[cycle].Prompt = i18n.T("settings.cycle")
+	m.timeDisplayOptions = []string{i18n.T("settings.timeDisplay.ansi"), i18n.T("settings.timeDisplay.normal")}
+	m.languageOptions = make([]string, len(m.languages))
+	for i, lang := range m.languages {
+		m.languageOptions[i] = i18n.Name(lang)
+	}
+	m.notifyForm = newNotificationForm(m.Settings.Notifications)
+	m.appearanceForm = newAppearanceForm(m.Settings)
+}
+
+// indexOfLanguage 返回最接近 lang 的可选语言的序号
+func (m *SettingModel) indexOfLanguage(lang string) int {
+	lang = i18n.Match(lang)
+	for i, l := range m.languages {
+		if l == lang {
+			return i
+		}
+	}
+	return 0
+}
+
 func (m SettingModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -129,6 +198,11 @@ func (m SettingModel) Init() tea.Cmd {
 func (m SettingModel) Update(msg tea.Msg) (SettingModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if form := m.activeForm(); form != nil {
+			if cmd, ok := form.Update(msg); ok {
+				return m, cmd
+			}
+		}
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -153,25 +227,32 @@ func (m SettingModel) Update(msg tea.Msg) (SettingModel, tea.Cmd) {
 				cy = int(m.Settings.Cycle)
 			}
 
-			m.Settings.Pomodoro = uint(p)
-			m.Settings.ShortBreak = uint(sb)
-			m.Settings.LongBreak = uint(lb)
-			m.Settings.Cycle = uint(cy)
+			s := m.Settings
+			s.Pomodoro = uint(p)
+			s.ShortBreak = uint(sb)
+			s.LongBreak = uint(lb)
+			s.Cycle = uint(cy)
 
 			// 保存时间显示方式
 			if m.timeDisplayIndex == 1 {
-				m.Settings.TimeDisplayMode = "normal"
+				s.TimeDisplayMode = "normal"
 			} else {
-				m.Settings.TimeDisplayMode = "ansi"
+				s.TimeDisplayMode = "ansi"
 			}
 
 			// 保存语言
-			if m.languageIndex == 1 {
-				m.Settings.Language = "en"
-			} else {
-				m.Settings.Language = "zh"
-			}
+			s.Language = m.languages[m.languageIndex]
+
+			s.Notifications = notificationSettings(&m.notifyForm, s.Notifications)
+			s.Theme, s.DigitFont, s.Compact = appearanceSettings(&m.appearanceForm)
 
+			// 超出范围的设置不保存，留在表单中修改
+			if err := s.Validate(); err != nil {
+				m.status = i18n.T("settings.invalid", err)
+				return m, nil
+			}
+			m.status = ""
+			m.Settings = s
 			m.Settings.Save()
 
 			return m, func() tea.Msg { return backMsg{} }
@@ -221,37 +302,30 @@ func (m SettingModel) Update(msg tea.Msg) (SettingModel, tea.Cmd) {
 
 			return m, tea.Batch(cmds...)
 		case "right", "l":
-			if m.focusIndex == timeDisplay {
+			if m.ActiveTab == timerTab && m.focusIndex == timeDisplay {
 				m.timeDisplayIndex = min(len(m.timeDisplayOptions)-1, m.timeDisplayIndex+1)
-			} else if m.focusIndex == languageOpt {
+			} else if m.ActiveTab == generalTab && m.focusIndex == languageOpt {
 				m.languageIndex = min(len(m.languageOptions)-1, m.languageIndex+1)
 			} else {
 				m.ActiveTab = min(m.ActiveTab+1, len(m.Tabs)-1)
 			}
 			return m, nil
 		case "left", "h":
-			if m.focusIndex == timeDisplay {
+			if m.ActiveTab == timerTab && m.focusIndex == timeDisplay {
 				m.timeDisplayIndex = max(0, m.timeDisplayIndex-1)
-			} else if m.focusIndex == languageOpt {
+			} else if m.ActiveTab == generalTab && m.focusIndex == languageOpt {
 				m.languageIndex = max(0, m.languageIndex-1)
 			} else {
 				m.ActiveTab = max(m.ActiveTab-1, 0)
 			}
 			return m, nil
-		// 语言选择快捷键
-		case "1", "2":
-			if m.focusIndex == timeDisplay {
-				if msg.String() == "1" {
-					m.timeDisplayIndex = 0
-				} else if msg.String() == "2" {
-					m.timeDisplayIndex = 1
-				}
-			} else if m.focusIndex == languageOpt {
-				if msg.String() == "1" {
-					m.languageIndex = 0
-				} else if msg.String() == "2" {
-					m.languageIndex = 1
-				}
+		// 选项快捷键，按数字选择第几项
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			n := int(msg.String()[0] - '1')
+			if m.focusIndex == timeDisplay && n < len(m.timeDisplayOptions) {
+				m.timeDisplayIndex = n
+			} else if m.focusIndex == languageOpt && n < len(m.languageOptions) {
+				m.languageIndex = n
 			}
 			return m, nil
 		}
@@ -326,7 +400,7 @@ func (m SettingModel) View() string {
 	if m.ActiveTab == 0 {
 		// General 标签页，仅显示语言设置
 		var b strings.Builder
-		languagePrompt := "语言(Language): "
+		languagePrompt := i18n.T("settings.language")
 		if m.focusIndex == languageOpt {
 			languagePrompt = focusedStyle.Render(languagePrompt)
 		}
@@ -352,11 +426,7 @@ func (m SettingModel) View() string {
 			}
 			b.WriteRune('\n')
 		}
-		button := &blurredButton
-		if m.focusIndex == len(m.inputs)+2 {
-			button = &focusedButton
-		}
-		fmt.Fprintf(&b, "\n\n%s\n\n", *button)
+		fmt.Fprintf(&b, "\n\n%s\n\n", submitButton(m.focusIndex == len(m.inputs)+2))
 		windowContent = b.String()
 	} else if m.ActiveTab == 1 {
 		// Timer 标签页
@@ -369,7 +439,7 @@ func (m SettingModel) View() string {
 		}
 		// 添加时间显示方式选择器
 		b.WriteRune('\n')
-		timeDisplayPrompt := "时间显示方式: "
+		timeDisplayPrompt := i18n.T("settings.timeDisplay")
 		if m.focusIndex == timeDisplay {
 			timeDisplayPrompt = focusedStyle.Render(timeDisplayPrompt)
 		}
@@ -395,19 +465,24 @@ func (m SettingModel) View() string {
 			}
 			b.WriteRune('\n')
 		}
-		button := &blurredButton
-		if m.focusIndex == len(m.inputs)+2 {
-			button = &focusedButton
-		}
-		fmt.Fprintf(&b, "\n\n%s\n\n", *button)
+		fmt.Fprintf(&b, "\n\n%s\n\n", submitButton(m.focusIndex == len(m.inputs)+2))
 		windowContent = b.String()
-	} else {
-		// 其他标签页
-		windowContent = fmt.Sprintf("%s Content", m.Tabs[m.ActiveTab])
+	} else if m.ActiveTab == appearanceTab {
+		// Appearance 标签页，附带当前数字字体的预览
+		windowContent = m.appearanceForm.View() + "\n" +
+			common.TimeToArt("12:34", common.DigitFonts[m.appearanceForm.fields[appearanceFont].index]) + "\n" +
+			helpStyle.Render(i18n.T("settings.appearance.help")) + "\n"
+	} else if m.ActiveTab == notificationsTab {
+		// Notifications 标签页，可同时启用多个通知后端
+		windowContent = m.notifyForm.View() + "\n" +
+			helpStyle.Render(i18n.T("settings.notify.help")) + "\n"
 	}
 
 	doc.WriteString(windowStyle.Width((lipgloss.Width(row) - windowStyle.GetHorizontalFrameSize())).Render(windowContent))
-	doc.WriteString("\n" + helpStyle.Render("  ↑/↓: navigate • tab: next field • enter: confirm • q: quit"))
+	if m.status != "" {
+		doc.WriteString("\n" + statusMessageStyle("  "+m.status))
+	}
+	doc.WriteString("\n" + helpStyle.Render("  "+i18n.T("settings.help")))
 	return docStyle.Render(doc.String())
 }
 
@@ -450,9 +525,130 @@ func (m *SettingModel) ReloadInputsFromSettings() {
 		m.timeDisplayIndex = 0 // 默认ANSI
 	}
 	// 重新加载语言设置
-	if m.Settings.Language == "en" {
-		m.languageIndex = 1
-	} else {
-		m.languageIndex = 0
+	m.languageIndex = m.indexOfLanguage(m.Settings.Language)
+	m.status = ""
+	m.setLabels()
+}
+
+// activeForm 返回当前标签页的表单，General 和 Timer 标签页没有表单
+func (m *SettingModel) activeForm() *settingsForm {
+	switch m.ActiveTab {
+	case appearanceTab:
+		return &m.appearanceForm
+	case notificationsTab:
+		return &m.notifyForm
 	}
+	return nil
+}
+
+// newNotificationForm 根据通知设置创建 Notifications 标签页的表单
+func newNotificationForm(s common.NotificationSettings) settingsForm {
+	oscLabels := []string{i18n.T("settings.notify.osc.off"), "OSC 9", "OSC 777"}
+	osc := 0
+	for i, v := range common.OSCOptions {
+		if v == s.OSC {
+			osc = i
+		}
+	}
+	warn := minutesField(i18n.T("settings.notify.warnBefore"), i18n.T("settings.notify.warnBefore.placeholder"), s.WarnBefore)
+	due := minutesField(i18n.T("settings.notify.dueBefore"), i18n.T("settings.notify.dueBefore.placeholder"), s.DueBefore)
+	return settingsForm{fields: []formField{
+		notifyWorkEnd:    newToggleField(i18n.T("settings.notify.workEnd"), s.WorkEnd),
+		notifyBreakEnd:   newToggleField(i18n.T("settings.notify.breakEnd"), s.BreakEnd),
+		notifyCycleEnd:   newToggleField(i18n.T("settings.notify.cycleEnd"), s.CycleEnd),
+		notifyWarnBefore: warn,
+		notifyDesktop:    newToggleField(i18n.T("settings.notify.desktop"), s.Desktop),
+		notifySound:      newToggleField(i18n.T("settings.notify.sound"), s.Sound),
+		notifySoundFile:  newTextField(i18n.T("settings.notify.soundFile"), s.SoundFile, i18n.T("settings.notify.soundFile.placeholder")),
+		notifyBell:       newToggleField(i18n.T("settings.notify.bell"), s.Bell),
+		notifyOSC:        newChoiceField(i18n.T("settings.notify.osc"), oscLabels, osc),
+		notifyCommand:    newTextField(i18n.T("settings.notify.command"), s.Command, i18n.T("settings.notify.command.placeholder")),
+		notifyFile:       newTextField(i18n.T("settings.notify.file"), s.File, i18n.T("settings.notify.file.placeholder")),
+		notifyChecklist:  newToggleField(i18n.T("settings.notify.checklist"), s.ChecklistPrompt),
+		notifyDueBefore:  due,
+	}}
+}
+
+// minutesField 创建以分钟为单位的文本框，0 不显示，留空表示不提醒
+func minutesField(label, placeholder string, minutes uint) formField {
+	f := newTextField(label, "", placeholder)
+	f.input.CharLimit = 3
+	f.input.Validate = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := strconv.Atoi(s); err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		return nil
+	}
+	if minutes > 0 {
+		f.input.SetValue(strconv.Itoa(int(minutes)))
+	}
+	return f
+}
+
+// minutesValue 读取 minutesField 中的分钟数，无法解析时返回 old
+func minutesValue(f formField, old uint) uint {
+	v := strings.TrimSpace(f.input.Value())
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return uint(n)
+	}
+	return old
+}
+
+// notificationSettings 读取 Notifications 标签页表单中的设置，
+// 提前提醒时间无法解析时沿用 old 中的值
+func notificationSettings(f *settingsForm, old common.NotificationSettings) common.NotificationSettings {
+	return common.NotificationSettings{
+		WorkEnd:    f.fields[notifyWorkEnd].on,
+		BreakEnd:   f.fields[notifyBreakEnd].on,
+		CycleEnd:   f.fields[notifyCycleEnd].on,
+		WarnBefore: minutesValue(f.fields[notifyWarnBefore], old.WarnBefore),
+		Desktop:    f.fields[notifyDesktop].on,
+		Sound:      f.fields[notifySound].on,
+		SoundFile:  strings.TrimSpace(f.fields[notifySoundFile].input.Value()),
+		Bell:       f.fields[notifyBell].on,
+		OSC:        common.OSCOptions[f.fields[notifyOSC].index],
+		Command:    strings.TrimSpace(f.fields[notifyCommand].input.Value()),
+		File:       strings.TrimSpace(f.fields[notifyFile].input.Value()),
+
+		ChecklistPrompt: f.fields[notifyChecklist].on,
+		DueBefore:       minutesValue(f.fields[notifyDueBefore], old.DueBefore),
+	}
+}
+
+// newAppearanceForm 根据外观设置创建 Appearance 标签页的表单
+func newAppearanceForm(s common.Settings) settingsForm {
+	themes := make([]string, len(common.Themes))
+	theme := 0
+	for i, t := range common.Themes {
+		themes[i] = t.Name
+		if t.Name == s.Theme {
+			theme = i
+		}
+	}
+	fonts := make([]string, len(common.DigitFonts))
+	font := 0
+	for i, name := range common.DigitFonts {
+		fonts[i] = i18n.T("settings.appearance.font." + name)
+		if name == s.DigitFont {
+			font = i
+		}
+	}
+	return settingsForm{fields: []formField{
+		appearanceTheme:   newChoiceField(i18n.T("settings.appearance.theme"), themes, theme),
+		appearanceFont:    newChoiceField(i18n.T("settings.appearance.font"), fonts, font),
+		appearanceCompact: newToggleField(i18n.T("settings.appearance.compact"), s.Compact),
+	}}
+}
+
+// appearanceSettings 读取 Appearance 标签页表单中的主题、数字字体和紧凑模式
+func appearanceSettings(f *settingsForm) (theme, font string, compact bool) {
+	return common.Themes[f.fields[appearanceTheme].index].Name,
+		common.DigitFonts[f.fields[appearanceFont].index],
+		f.fields[appearanceCompact].on
 }
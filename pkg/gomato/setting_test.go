@@ -0,0 +1,103 @@
+package gomato
+
+import (
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keyPress(s string) tea.KeyMsg {
+	switch s {
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// TestNotificationForm 测试通知标签页的表单能正确读写设置
+func TestNotificationForm(t *testing.T) {
+	f := newNotificationForm(common.NotificationSettings{WorkEnd: true, Desktop: true, Sound: true, WarnBefore: 2})
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			f.Update(keyPress(k))
+		}
+	}
+	press("down", " ")                // 开启休息结束通知
+	press("down", "down", "5")        // 提前提醒: 25 分钟
+	press("down", " ")                // 关闭桌面通知
+	press("down", "down", "h", "l")   // 声音文件中可以输入 h 和 l
+	press("down", " ")                // 开启终端响铃
+	press("down", "right", "right")   // 终端通知: OSC 777
+	press("down", "d", "o", "h", "x") // 执行命令
+
+	got := notificationSettings(&f, common.NotificationSettings{})
+	want := common.NotificationSettings{
+		WorkEnd: true, BreakEnd: true, WarnBefore: 25, Sound: true, SoundFile: "hl",
+		Bell: true, OSC: "777", Command: "dohx",
+	}
+	if got != want {
+		t.Errorf("settings = %+v, want %+v", got, want)
+	}
+
+	// 提前提醒时间无法解析时沿用原值
+	f.fields[notifyWarnBefore].input.SetValue("abc")
+	if got := notificationSettings(&f, want); got.WarnBefore != 25 {
+		t.Errorf("WarnBefore = %d, want 25", got.WarnBefore)
+	}
+}
+
+// TestAppearanceForm 测试外观标签页的表单能正确读写设置
+func TestAppearanceForm(t *testing.T) {
+	f := newAppearanceForm(common.Settings{Theme: "ocean", DigitFont: "standard"})
+	for _, k := range []string{"right", "down", "right", "right", "down", " "} {
+		f.Update(keyPress(k))
+	}
+	theme, font, compact := appearanceSettings(&f)
+	if theme != "tomato" || font != "small" || !compact {
+		t.Errorf("appearance = %s, %s, %v", theme, font, compact)
+	}
+}
+
+// TestSubmitButtonLanguage 测试保存按钮使用界面语言，而不是包初始化时的默认语言
+func TestSubmitButtonLanguage(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	i18n.SetLanguage("en")
+	t.Cleanup(func() { i18n.SetLanguage(i18n.Default) })
+	m := NewSettingModel()
+	m.setLabels()
+	m.ActiveTab = timerTab
+	if view := m.View(); !strings.Contains(view, "Submit") || strings.Contains(view, "保存") {
+		t.Errorf("保存按钮应为英文: %s", view)
+	}
+}
+
+// TestSettingsRejectInvalid 测试超出范围的设置留在表单中，不会保存
+func TestSettingsRejectInvalid(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	m := NewSettingModel()
+	m.inputs[cycle].SetValue("0")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.status == "" {
+		t.Fatalf("cycle 0 应当无法保存: status %q", m.status)
+	}
+	if saved, _ := common.LoadSettings(); saved.Cycle != 4 || m.Settings.Cycle != 4 {
+		t.Errorf("cycle = %d, saved %d, want 4", m.Settings.Cycle, saved.Cycle)
+	}
+
+	m.inputs[cycle].SetValue("3")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.status != "" {
+		t.Fatalf("cycle 3 应当保存: status %q", m.status)
+	}
+	if saved, _ := common.LoadSettings(); saved.Cycle != 3 {
+		t.Errorf("saved cycle = %d, want 3", saved.Cycle)
+	}
+}
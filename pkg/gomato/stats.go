@@ -0,0 +1,199 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	periodToday = iota
+	periodWeek
+	periodAll
+)
+
+var statsValueStyle = lipgloss.NewStyle().Bold(true)
+
+// statsLabelStyle 和 statsActiveStyle 基于共享样式，随主题变化
+func statsLabelStyle() lipgloss.Style  { return common.MutedStyle.Width(22) }
+func statsActiveStyle() lipgloss.Style { return common.AccentStyle.Underline(true) }
+
+// StatsModel 显示从历史记录计算出的统计数据
+type StatsModel struct {
+	period   int
+	grouping int    // task.Groupings 中的下标
+	allLists bool   // 为 false 时只统计当前列表
+	list     string // 当前列表
+	sessions []task.Session
+	tasks    []task.Task // 当前列表的任务
+	others   []task.Task // 其他列表的任务，统计所有列表时计入完成率
+	now      time.Time
+	language string
+	keys     *keymap.StatsViewKeyMap
+}
+
+func NewStatsModel(keys *keymap.StatsViewKeyMap) StatsModel {
+	return StatsModel{keys: keys}
+}
+
+// Reload 重新读取历史记录和其他列表的任务，manager 是当前列表，用于统计任务完成率
+func (m *StatsModel) Reload(history *task.History, manager *task.Manager, language string) {
+	m.now = time.Now()
+	m.language = language
+	m.list = manager.List()
+	m.tasks = manager.Tasks
+	m.others = nil
+	m.sessions = nil
+	if names, err := task.Lists(); err == nil {
+		for _, name := range names {
+			if name == m.list {
+				continue
+			}
+			if other, err := task.NewManager(name); err == nil {
+				m.others = append(m.others, other.Tasks...)
+			}
+		}
+	}
+	if history == nil {
+		return
+	}
+	sessions, err := history.Load()
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Stats] 读取历史记录失败: %v", err))
+		return
+	}
+	m.sessions = sessions
+}
+
+func (m StatsModel) Update(msg tea.Msg) (StatsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		return m, func() tea.Msg { return backMsg{} }
+	case key.Matches(keyMsg, m.keys.PrevPeriod):
+		m.period = (m.period + 2) % 3
+	case key.Matches(keyMsg, m.keys.NextPeriod):
+		m.period = (m.period + 1) % 3
+	case key.Matches(keyMsg, m.keys.Group):
+		m.grouping = (m.grouping + 1) % len(task.Groupings)
+		if task.Groupings[m.grouping] == task.GroupByList && !m.allLists {
+			// 只统计一个列表时按列表分组没有意义
+			m.grouping = (m.grouping + 1) % len(task.Groupings)
+		}
+	case key.Matches(keyMsg, m.keys.Scope):
+		m.allLists = !m.allLists
+		if task.Groupings[m.grouping] == task.GroupByList && !m.allLists {
+			m.grouping = 0
+		}
+	}
+	return m, nil
+}
+
+func (m StatsModel) label(k string) string {
+	return i18n.Tr(m.language, "stats."+k)
+}
+
+func (m StatsModel) View() string {
+	var b strings.Builder
+	title := m.label("title") + " · " + m.list
+	if m.allLists {
+		title = m.label("title") + " · " + m.label("allLists")
+	}
+	b.WriteString(common.TitleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	periods := []string{m.label("today"), m.label("week"), m.label("all")}
+	for i, p := range periods {
+		if i == m.period {
+			p = statsActiveStyle().Render(p)
+		}
+		b.WriteString(p)
+		if i < len(periods)-1 {
+			b.WriteString("  |  ")
+		}
+	}
+	b.WriteString("\n\n")
+
+	var from time.Time
+	switch m.period {
+	case periodToday:
+		from = task.StartOfDay(m.now)
+	case periodWeek:
+		from = task.StartOfWeek(m.now)
+	}
+	sessions, tasks := task.InList(m.sessions, m.list), m.tasks
+	if m.allLists {
+		sessions, tasks = m.sessions, append(append([]task.Task(nil), m.tasks...), m.others...)
+	}
+	sessions = task.Since(sessions, from)
+	st := task.Summarize(sessions)
+	progress := task.Progress(tasks, from)
+
+	row := func(label, value string) {
+		b.WriteString(statsLabelStyle().Render(label) + statsValueStyle.Render(value) + "\n")
+	}
+	row(m.label("sessions"), fmt.Sprintf("%d", st.WorkSessions))
+	row(m.label("focus"), formatDuration(st.FocusTime))
+	row(m.label("breaks"), fmt.Sprintf("%d", st.Breaks))
+	row(m.label("abandoned"), fmt.Sprintf("%d", st.Abandoned))
+	row(m.label("rate"), fmt.Sprintf("%.0f%%", st.CompletionRate()*100))
+	row(m.label("tasks"), fmt.Sprintf("%d/%d (%.0f%%)", progress.Completed, progress.Completed+progress.Open, progress.Rate()*100))
+
+	by := task.Groupings[m.grouping]
+	groups := task.Group(sessions, by)
+	b.WriteString("\n" + m.label("by."+string(by)) + "\n")
+	if len(groups) == 0 {
+		b.WriteString(helpStyle.Render("  " + m.label("noData")))
+		b.WriteRune('\n')
+	}
+	for _, ts := range groups {
+		name := m.groupName(by, ts.Name)
+		b.WriteString(fmt.Sprintf("  %s  %s  %s\n",
+			statsLabelStyle().Render(name),
+			statsValueStyle.Render(fmt.Sprintf("%d", ts.Sessions)),
+			formatDuration(ts.FocusTime)))
+	}
+
+	b.WriteString("\n" + helpStyle.Render(i18n.Tr(m.language, "stats.help",
+		keymap.HelpKey("stats.prev"), keymap.HelpKey("stats.next"), keymap.HelpKey("stats.group"),
+		keymap.HelpKey("stats.scope"), keymap.HelpKey("stats.back"))))
+	return b.String()
+}
+
+// groupName 返回分组的显示名称
+func (m StatsModel) groupName(by task.Grouping, name string) string {
+	switch {
+	case name == "":
+		return m.label("none." + string(by))
+	case by == task.GroupByProject:
+		return "@" + name
+	case by == task.GroupByTag:
+		return "#" + name
+	case by == task.GroupByPriority:
+		return i18n.Tr(m.language, "priority."+name)
+	}
+	return name
+}
+
+// formatDuration 以 "1h05m" 或 "25m" 的形式显示时长
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d / time.Hour)
+	mins := int((d % time.Hour) / time.Minute)
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, mins)
+	}
+	return fmt.Sprintf("%dm", mins)
+}
@@ -1,9 +1,16 @@
 package gomato
 
 import (
+	"fmt"
 	"gomato/pkg/common"
+	"gomato/pkg/daemon"
+	"gomato/pkg/i18n"
 	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
 	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"io"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -11,18 +18,29 @@ import (
 )
 
 func NewTaskList(listKeys *keymap.ListKeyMap, delegateKeys *keymap.DelegateKeyMap, taskManager *task.Manager) list.Model {
-	items := make([]list.Item, len(taskManager.Tasks))
-	for i, t := range taskManager.Tasks {
-		items[i] = t
-	}
-	delegate := newItemDelegate(delegateKeys)
+	items := listItems(taskManager, time.Now(), task.SortManual, task.FilterAll)
+	delegate := newItemDelegate(delegateKeys, false)
 	taskList := list.New(items, delegate, 0, 0)
-	taskList.Title = "番茄钟任务列表"
+	taskList.Title = i18n.T("app.title")
+	taskList.KeyMap = keymap.ListModelKeyMap()
 	taskList.Styles.Title = common.TitleStyle
 	taskList.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			listKeys.Setting,
 			listKeys.InsertItem,
+			listKeys.EditItem,
+			listKeys.Stats,
+			listKeys.Detail,
+			listKeys.ToggleDone,
+			listKeys.Archive,
+			listKeys.Sort,
+			listKeys.DateFilter,
+			listKeys.MoveUp,
+			listKeys.MoveDown,
+			listKeys.Undo,
+			listKeys.Redo,
+			listKeys.Trash,
+			listKeys.Lists,
 			listKeys.ToggleTitleBar,
 			listKeys.ToggleStatusBar,
 			listKeys.TogglePagination,
@@ -32,23 +50,182 @@ func NewTaskList(listKeys *keymap.ListKeyMap, delegateKeys *keymap.DelegateKeyMa
 	return taskList
 }
 
+// listItems 返回列表中显示的任务：未完成的任务和今天完成的任务，
+// 更早完成的任务只在归档视图中显示。任务按 sortMode 排序并按 filter 筛选。
+func listItems(taskManager *task.Manager, now time.Time, sortMode task.SortMode, filter task.DateFilter) []list.Item {
+	var tasks []task.Task
+	for _, t := range taskManager.Tasks {
+		if !t.Archived(now) && filter.Match(t, now) {
+			tasks = append(tasks, t)
+		}
+	}
+	task.Sort(tasks, sortMode)
+	items := make([]list.Item, len(tasks))
+	for i, t := range tasks {
+		items[i] = t
+	}
+	return items
+}
+
+// moveSelected 把选中的任务与列表中上方（step 为 -1）或下方（step 为 1）的任务交换位置，
+// 并保存到 tasks.json。被隐藏的已归档任务不影响交换的对象。
+func (m *App) moveSelected(step int) tea.Cmd {
+	if m.sortMode != task.SortManual || m.list.IsFiltered() {
+		return m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.manualOrderOnly", keymap.HelpKey("list.sort"))))
+	}
+	selected, ok := m.list.SelectedItem().(task.Task)
+	if !ok {
+		return nil
+	}
+	items := m.list.Items()
+	target := m.listIndex(selected.ID) + step
+	if target < 0 || target >= len(items) {
+		return nil
+	}
+	neighbor := items[target].(task.Task)
+	from, to := m.taskManager.Index(selected.ID), m.taskManager.Index(neighbor.ID)
+	if err := m.taskManager.Move(selected.ID, to); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 调整任务顺序失败: %v", err))
+		return nil
+	}
+	m.undo.push(moveChange(selected, from, to))
+	return m.refreshList()
+}
+
+// listTitle 返回列表标题，附上默认列表以外的列表名称和日期筛选方式
+func (m *App) listTitle() string {
+	title := i18n.T("app.title")
+	if m.taskManager != nil && m.taskManager.List() != task.DefaultList {
+		title += " · " + m.taskManager.List()
+	}
+	if m.dateFilter != task.FilterAll {
+		title += " · " + i18n.T("filter."+m.dateFilter.String())
+	}
+	return title
+}
+
+// refreshList 按任务管理器中的数据重建列表，并尽量保持原来选中的任务
+func (m *App) refreshList() tea.Cmd {
+	selected, _ := m.list.SelectedItem().(task.Task)
+	cmd := m.list.SetItems(listItems(m.taskManager, m.now(), m.sortMode, m.dateFilter))
+	if i := m.listIndex(selected.ID); i >= 0 {
+		m.list.Select(i)
+	}
+	m.delegateKeys.Remove.SetEnabled(len(m.list.Items()) > 0)
+	return cmd
+}
+
 func handleTaskCreated(m *App, msg taskCreatedMsg) (tea.Model, tea.Cmd) {
-	m.taskManager.AddItem(msg.title, msg.description)
-	m.taskManager.Tasks[len(m.taskManager.Tasks)-1].Timer = task.TimeModel{
-		TimerDuration:  25 * 60,
-		TimerRemaining: 25 * 60,
-		TimerIsRunning: false,
-		IsWorkSession:  true,
+	err := m.taskManager.Add(msg.title, msg.description, func(t *task.Task) {
+		m.applyTimerConfig(t)
+		msg.apply(t)
+	})
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Task] 保存任务失败: %v", err))
 	}
 	newTask := m.taskManager.Tasks[len(m.taskManager.Tasks)-1]
-	insertCmd := m.list.InsertItem(len(m.list.Items()), newTask)
-	statusCmd := m.list.NewStatusMessage(statusMessageStyle("添加了新任务: " + newTask.Title()))
+	m.undo.push(addChange(newTask))
+	// 新任务按排序方式插入，不满足当前日期筛选时不显示
+	refreshCmd := m.refreshList()
+	statusCmd := m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.taskAdded", newTask.Title())))
 	m.currentView = taskListView
-	return m, tea.Batch(insertCmd, statusCmd)
+	return m, tea.Batch(refreshCmd, statusCmd)
+}
+
+// taskDelegate 在默认样式的基础上为已完成的任务加删除线，
+// 以危险色显示已过期的任务，以警示色显示超出预估番茄数的任务
+type taskDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d taskDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if t, ok := item.(task.Task); ok && t.Done {
+		muted := common.CurrentTheme.Muted
+		s := &d.Styles
+		s.NormalTitle = s.NormalTitle.Strikethrough(true).Foreground(muted)
+		s.NormalDesc = s.NormalDesc.Strikethrough(true).Foreground(muted)
+		s.SelectedTitle = s.SelectedTitle.Strikethrough(true)
+		s.SelectedDesc = s.SelectedDesc.Strikethrough(true)
+		s.DimmedTitle = s.DimmedTitle.Strikethrough(true)
+		s.DimmedDesc = s.DimmedDesc.Strikethrough(true)
+	} else if ok && t.Overdue(time.Now()) {
+		danger := common.CurrentTheme.Danger
+		s := &d.Styles
+		s.NormalTitle = s.NormalTitle.Foreground(danger).Bold(true)
+		s.NormalDesc = s.NormalDesc.Foreground(danger)
+		s.SelectedTitle = s.SelectedTitle.Bold(true)
+		s.SelectedDesc = s.SelectedDesc.Foreground(danger)
+	} else if ok && t.OverEstimate() {
+		// 超出预估的任务需要重新规划，用警示色突出
+		warning := common.CurrentTheme.Warning
+		s := &d.Styles
+		s.NormalTitle = s.NormalTitle.Foreground(warning)
+		s.NormalDesc = s.NormalDesc.Foreground(warning)
+		s.SelectedDesc = s.SelectedDesc.Foreground(warning)
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// prepareNextOccurrence 让完成重复任务时生成的下一次采用当前设置的番茄时长，
+// 没有生成下一次时返回 nil
+func (m *App) prepareNextOccurrence(id string) *task.Task {
+	t, _ := m.taskManager.Get(id)
+	i := m.taskManager.Index(t.Next)
+	if t.Next == "" || i < 0 {
+		return nil
+	}
+	next := &m.taskManager.Tasks[i]
+	m.applyTimerConfig(next)
+	if err := m.taskManager.Save(); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 保存任务失败: %v", err))
+	}
+	return next
 }
 
-func newItemDelegate(keys *keymap.DelegateKeyMap) list.ItemDelegate {
+// reloadTasks 在任务文件被其他进程修改过时重新读取，并刷新列表
+func (m *App) reloadTasks() {
+	if changed, err := m.taskManager.Reload(); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 重新读取任务失败: %v", err))
+	} else if changed {
+		m.refreshList()
+	}
+}
+
+// applyTimerConfig 让任务的计时器采用当前设置的番茄时长
+func (m *App) applyTimerConfig(t *task.Task) {
+	now := m.now()
+	t.Timer = task.NewTimeModel(m.settingModel.Settings.TimerConfig().Apply(t.Timer.Snapshot(0), now), now)
+}
+
+// updateListItem 用修改后的任务替换列表中对应的条目
+func (m *App) updateListItem(t task.Task) tea.Cmd {
+	if i := m.listIndex(t.ID); i >= 0 {
+		return m.list.SetItem(i, t)
+	}
+	return nil
+}
+
+// handleTaskEdited 保存编辑后的字段，计时状态和历史记录保持不变。
+// 日期可能改变排序和筛选结果，因此重建列表。
+func handleTaskEdited(m *App, msg taskEditedMsg) (tea.Model, tea.Cmd) {
+	m.currentView = taskListView
+	m.taskInput = NewTaskInputModel()
+	t, ok := m.taskManager.Get(msg.id)
+	if !ok {
+		return m, nil
+	}
+	before := fieldsOf(t)
+	msg.apply(&t)
+	if err := m.taskManager.Update(t); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 保存任务失败: %v", err))
+	}
+	m.undo.push(editChange(t.ID, t.Title(), before, msg.taskFields))
+	return m, tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.taskEdited", t.Title()))))
+}
+
+func newItemDelegate(keys *keymap.DelegateKeyMap, compact bool) list.ItemDelegate {
 	d := list.NewDefaultDelegate()
+	styleDelegate(&d, compact)
 	d.UpdateFunc = func(msg tea.Msg, m *list.Model) tea.Cmd {
 		var title string
 		if i, ok := m.SelectedItem().(task.Task); ok {
@@ -60,7 +237,7 @@ func newItemDelegate(keys *keymap.DelegateKeyMap) list.ItemDelegate {
 		case tea.KeyMsg:
 			switch {
 			case key.Matches(msg, keys.Choose):
-				return m.NewStatusMessage("You chose " + title)
+				return m.NewStatusMessage(i18n.T("app.chose", title))
 			}
 		}
 		return nil
@@ -72,7 +249,7 @@ func newItemDelegate(keys *keymap.DelegateKeyMap) list.ItemDelegate {
 	d.FullHelpFunc = func() [][]key.Binding {
 		return [][]key.Binding{help}
 	}
-	return d
+	return taskDelegate{d}
 }
 
 func updateTaskListView(m *App, msg tea.Msg) tea.Cmd {
@@ -85,10 +262,73 @@ func updateTaskListView(m *App, msg tea.Msg) tea.Cmd {
 		}
 		switch {
 		case key.Matches(keyMsg, m.keys.Setting):
-			common.LoadSettings()
 			m.settingModel.ReloadInputsFromSettings()
 			m.currentView = settingView
 			return nil
+		case key.Matches(keyMsg, m.keys.Stats):
+			m.statsModel.Reload(m.history, m.taskManager, m.settingModel.Settings.Language)
+			m.currentView = statsView
+			return nil
+		case key.Matches(keyMsg, m.keys.Detail):
+			selected, ok := m.list.SelectedItem().(task.Task)
+			if !ok {
+				break
+			}
+			m.detailModel = NewDetailModel(selected, m.detailModel.keys)
+			m.currentView = detailView
+			return nil
+		case key.Matches(keyMsg, m.keys.Archive):
+			m.archiveModel.Reload(m.taskManager)
+			m.currentView = archiveView
+			return nil
+		case key.Matches(keyMsg, m.keys.ToggleDone):
+			selected, ok := m.list.SelectedItem().(task.Task)
+			if !ok {
+				break
+			}
+			done := !selected.Done
+			if err := m.taskManager.SetDone(selected.ID, done, m.now()); err != nil {
+				logging.Log(fmt.Sprintf("[Task] 更新任务完成状态失败: %v", err))
+			}
+			m.undo.push(doneChange(selected, done))
+			status := i18n.T("app.taskUndone", selected.Title())
+			if done {
+				status = i18n.T("app.taskDone", selected.Title())
+				if next := m.prepareNextOccurrence(selected.ID); next != nil {
+					status = i18n.T("app.taskDoneNext", selected.Title(), next.Dates(m.now()))
+				}
+			}
+			return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(status)))
+		case key.Matches(keyMsg, m.keys.Sort):
+			m.sortMode = task.SortModes[(int(m.sortMode)+1)%len(task.SortModes)]
+			// 排序方式保存在设置中，下次启动时沿用
+			m.settingModel.Settings.TaskSort = m.sortMode.String()
+			if err := m.settingModel.Settings.Save(); err != nil {
+				logging.Log(fmt.Sprintf("[Setting] 保存排序方式失败: %v", err))
+			}
+			status := i18n.T("app.sorted", i18n.T("sort."+m.sortMode.String()))
+			return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(status)))
+		case key.Matches(keyMsg, m.keys.MoveUp):
+			return m.moveSelected(-1)
+		case key.Matches(keyMsg, m.keys.MoveDown):
+			return m.moveSelected(1)
+		case key.Matches(keyMsg, m.keys.Undo):
+			return m.undoLast()
+		case key.Matches(keyMsg, m.keys.Redo):
+			return m.redoLast()
+		case key.Matches(keyMsg, m.keys.Lists):
+			m.listsModel.Reload(m.taskManager.List())
+			m.currentView = listsView
+			return nil
+		case key.Matches(keyMsg, m.keys.Trash):
+			m.trashModel.Reload(m.taskManager, m.settingModel.Settings.TrashDays)
+			m.currentView = trashView
+			return nil
+		case key.Matches(keyMsg, m.keys.DateFilter):
+			m.dateFilter = task.DateFilters[(int(m.dateFilter)+1)%len(task.DateFilters)]
+			m.list.Title = m.listTitle()
+			status := i18n.T("app.filtered", i18n.T("filter."+m.dateFilter.String()))
+			return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(status)))
 		case key.Matches(keyMsg, m.keys.ToggleTitleBar):
 			v := !m.list.ShowTitle()
 			m.list.SetShowTitle(v)
@@ -107,28 +347,57 @@ func updateTaskListView(m *App, msg tea.Msg) tea.Cmd {
 		case key.Matches(keyMsg, m.keys.InsertItem):
 			m.currentView = taskInputView
 			return nil
+		case key.Matches(keyMsg, m.keys.EditItem):
+			selected, ok := m.list.SelectedItem().(task.Task)
+			if !ok {
+				break
+			}
+			m.taskInput = NewTaskEditModel(selected)
+			m.currentView = taskInputView
+			return nil
 		case key.Matches(keyMsg, m.delegateKeys.Remove):
-			index := m.list.Index()
-			if index >= 0 && index < len(m.taskManager.Tasks) {
-				deletedTaskTitle := m.taskManager.Tasks[index].Title()
-				m.taskManager.DeleteItem(index)
-				m.list.RemoveItem(index)
-				if len(m.list.Items()) == 0 {
-					m.delegateKeys.Remove.SetEnabled(false)
-				}
-				statusCmd := m.list.NewStatusMessage(statusMessageStyle("删除了任务: " + deletedTaskTitle))
-				return statusCmd
+			// 列表筛选时 Index 是可见条目中的位置，因此按 ID 删除
+			selected, ok := m.list.SelectedItem().(task.Task)
+			if !ok {
+				break
+			}
+			// 删除的任务移到回收站，可以撤销
+			if err := m.taskManager.Delete(selected.ID); err != nil {
+				logging.Log(fmt.Sprintf("[Task] 删除任务失败: %v", err))
+				return nil
 			}
+			m.undo.push(deleteChange(selected))
+			m.list.RemoveItem(m.listIndex(selected.ID))
+			if len(m.list.Items()) == 0 {
+				m.delegateKeys.Remove.SetEnabled(false)
+			}
+			status := i18n.T("app.taskRemoved", selected.Title(), keymap.HelpKey("list.undo"))
+			return m.list.NewStatusMessage(statusMessageStyle(status))
 		case key.Matches(keyMsg, m.keys.ChooseTask):
-			m.currentTaskIndex = m.list.Index()
-			if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-				m.timeModel = m.taskManager.Tasks[m.currentTaskIndex].Timer
+			selected, ok := m.list.SelectedItem().(task.Task)
+			if !ok {
+				break
+			}
+			if m.remote != nil {
+				m.currentView = timeView
+				m.remoteDo(daemon.CmdStart, selected.ID)
+				return tea.Batch(append(m.flushTimerEvents(), m.startTicking())...)
+			}
+			if selected.ID != m.currentTaskID && m.engine.State() == timer.StateRunning {
+				// 切换任务时暂停原任务的会话，避免把切换期间计入其工作时长
+				m.pauseTimer()
+			}
+			m.currentTaskID = selected.ID
+			if t := m.currentTask(); t != nil {
+				// 任务保存的时长可能来自旧的设置，按当前设置调整
+				m.engine.Restore(t.Timer.Snapshot(m.engine.Cycle()))
+				m.engine.SetConfig(m.settingModel.Settings.TimerConfig())
 			}
 			m.currentView = timeView
-			m.timeModel.TimerIsRunning = true
+			m.startTimer()
 			return tea.Batch(
-				m.list.NewStatusMessage(statusMessageStyle("任务已选择，计时已开始！")),
-				tick(),
+				m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.taskChosen"))),
+				m.startTicking(),
 			)
 		}
 	}
@@ -0,0 +1,617 @@
+package gomato
+
+import (
+	"errors"
+	"gomato/pkg/common"
+	"gomato/pkg/keymap"
+	"gomato/pkg/notice"
+	"gomato/pkg/paths"
+	"gomato/pkg/safefile"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newListTestApp 在临时数据目录中创建包含 titles 的默认任务列表，
+// 返回显示该列表的 App
+func newListTestApp(t *testing.T, titles ...string) (*App, *fakeClock) {
+	t.Helper()
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	manager, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range titles {
+		if err := manager.AddItem(title, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, clock := newTickTestApp(60)
+	m.taskManager = manager
+	m.keys = keymap.NewListKeyMap()
+	m.delegateKeys = keymap.NewDelegateKeyMap()
+	m.list = NewTaskList(m.keys, m.delegateKeys, manager)
+	return m, clock
+}
+
+// listNames 返回列表中显示的任务标题，以逗号分隔
+func listNames(m *App) string {
+	var s []string
+	for _, item := range m.list.Items() {
+		s = append(s, item.(task.Task).Name)
+	}
+	return strings.Join(s, ",")
+}
+
+// TestRemoveFilteredTask 测试列表筛选后删除的是选中的任务，而不是同一位置上的其他任务
+func TestRemoveFilteredTask(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告", "读书", "跑步")
+	manager := m.taskManager
+	m.list.SetFilterText("跑步")
+
+	updateTaskListView(m, keyPress("x"))
+
+	if len(manager.Tasks) != 2 || manager.Tasks[0].Name != "写报告" || manager.Tasks[1].Name != "读书" {
+		t.Errorf("删除后剩余任务: %+v", manager.Tasks)
+	}
+	if items := m.list.Items(); len(items) != 2 || items[1].(task.Task).Name != "读书" {
+		t.Errorf("列表条目: %+v", items)
+	}
+}
+
+// TestToggleDoneAndRestore 测试在列表中完成任务，以及从归档视图恢复
+func TestToggleDoneAndRestore(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告", "读书")
+	manager := m.taskManager
+	m.archiveModel = NewArchiveModel(keymap.NewArchiveViewKeyMap())
+
+	updateTaskListView(m, keyPress("c"))
+	if !manager.Tasks[0].Done || manager.Tasks[0].CompletedAt.IsZero() {
+		t.Fatalf("任务未标记为完成: %+v", manager.Tasks[0])
+	}
+	if items := m.list.Items(); len(items) != 2 || !items[0].(task.Task).Done {
+		t.Errorf("今天完成的任务应留在列表中: %+v", items)
+	}
+
+	updateTaskListView(m, keyPress("A"))
+	if m.currentView != archiveView || len(m.archiveModel.tasks) != 1 {
+		t.Fatalf("归档视图: view=%d tasks=%+v", m.currentView, m.archiveModel.tasks)
+	}
+	_, cmd := m.archiveModel.Update(keyPress("r"))
+	m.Update(cmd())
+	if manager.Tasks[0].Done || len(m.archiveModel.tasks) != 0 {
+		t.Errorf("恢复后任务仍为完成状态: %+v", manager.Tasks[0])
+	}
+	if item := m.list.Items()[0].(task.Task); item.Done {
+		t.Errorf("列表条目未刷新: %+v", item)
+	}
+}
+
+// TestEditTask 测试编辑表单预先填入任务内容，保存后计时状态不变
+func TestEditTask(t *testing.T) {
+	m, _ := newListTestApp(t, "写抱告")
+	manager := m.taskManager
+	manager.Tasks[0].Detail = "周五前"
+	manager.Tasks[0].Timer.TimerRemaining = 600
+	m.refreshList()
+
+	updateTaskListView(m, keyPress("e"))
+	if m.currentView != taskInputView || m.taskInput.inputs[0].Value() != "写抱告" || m.taskInput.inputs[1].Value() != "周五前" {
+		t.Fatalf("编辑表单未填入任务内容: view=%d", m.currentView)
+	}
+	m.taskInput.inputs[0].SetValue("写报告")
+	m.taskInput.focused = len(m.taskInput.inputs)
+	_, cmd := m.taskInput.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(cmd())
+
+	got := manager.Tasks[0]
+	if len(manager.Tasks) != 1 || got.Name != "写报告" || got.Detail != "周五前" || got.Timer.TimerRemaining != 600 {
+		t.Errorf("编辑后的任务: %+v", manager.Tasks)
+	}
+	if item := m.list.Items()[0].(task.Task); item.Name != "写报告" {
+		t.Errorf("列表条目未更新: %+v", item)
+	}
+	if m.currentView != taskListView || m.taskInput.editID != "" {
+		t.Errorf("保存后应回到列表并重置表单")
+	}
+}
+
+// TestTaskFormLabels 测试表单解析项目、标签和优先级，无法识别的优先级不会提交
+func TestTaskFormLabels(t *testing.T) {
+	m := NewTaskInputModel()
+	m.inputs[inputTitle].SetValue("写报告")
+	m.inputs[inputProject].SetValue(" 工作 ")
+	m.inputs[inputTags].SetValue("#写作, 紧急")
+	m.inputs[inputPriority].SetValue("紧急")
+	m.focused = len(m.inputs)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.err == nil {
+		t.Fatal("无法识别的优先级应显示错误而不是提交")
+	}
+
+	m.inputs[inputPriority].SetValue("高")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(taskCreatedMsg)
+	if !ok || m.err != nil {
+		t.Fatalf("表单未提交: %v", m.err)
+	}
+	if msg.project != "工作" || len(msg.tags) != 2 || msg.tags[0] != "写作" || msg.priority != task.PriorityHigh {
+		t.Errorf("解析结果: %+v", msg.taskFields)
+	}
+}
+
+// TestSortAndFilterByDate 测试列表按截止日期排序、按日期筛选，以及到期提醒只发送一次
+func TestSortAndFilterByDate(t *testing.T) {
+	m, clock := newListTestApp(t)
+	today := task.StartOfDay(clock.Now())
+	m.taskManager.Tasks = []task.Task{
+		{ID: "a", Name: "没有期限"},
+		{ID: "b", Name: "下周", Due: today.AddDate(0, 0, 7)},
+		{ID: "c", Name: "已过期", Due: today.AddDate(0, 0, -1)},
+		{ID: "d", Name: "今天上午", Due: today.Add(9*time.Hour + 15*time.Minute)},
+	}
+	m.refreshList()
+	names := func() string { return listNames(m) }
+
+	// 手动顺序之后依次是优先级、截止日期
+	updateTaskListView(m, keyPress("o"))
+	updateTaskListView(m, keyPress("o"))
+	if got := names(); got != "已过期,今天上午,下周,没有期限" {
+		t.Errorf("按截止日期排序: %s", got)
+	}
+	updateTaskListView(m, keyPress("D"))
+	if got := names(); got != "已过期" || !strings.Contains(m.list.Title, "已过期") {
+		t.Errorf("筛选已过期: %s, 标题 %q", got, m.list.Title)
+	}
+	updateTaskListView(m, keyPress("D"))
+	if got := names(); got != "已过期,今天上午" {
+		t.Errorf("筛选今天: %s", got)
+	}
+
+	rec := &recordingNotifier{}
+	m.notifier = notice.NewDispatcher(rec)
+	m.settingModel.Settings.Notifications.DueBefore = 30
+	m.checkReminders()
+	clock.Advance(time.Minute)
+	m.checkReminders()
+	m.notifier.Wait()
+	if len(rec.messages) != 1 || rec.messages[0] != "任务将于 09:15 到期: 今天上午" {
+		t.Errorf("到期提醒: %q", rec.messages)
+	}
+}
+
+// TestTaskFormDates 测试表单解析相对日期，编辑时以可解析的形式预先填入
+func TestTaskFormDates(t *testing.T) {
+	now := time.Date(2025, 3, 5, 14, 30, 0, 0, time.Local)
+	m := NewTaskInputModel()
+	m.inputs[inputTitle].SetValue("写报告")
+	m.inputs[inputDue].SetValue("+3d 18:00")
+	m.inputs[inputScheduled].SetValue("someday")
+	if _, err := m.fields(now); err == nil {
+		t.Fatal("无法识别的日期应返回错误")
+	}
+	m.inputs[inputScheduled].SetValue("tomorrow")
+	f, err := m.fields(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 8, 18, 0, 0, 0, time.Local); !f.due.Equal(want) {
+		t.Errorf("截止日期: %v", f.due)
+	}
+	var edited task.Task
+	f.apply(&edited)
+	e := NewTaskEditModel(edited)
+	if e.inputs[inputDue].Value() != "2025-03-08 18:00" || e.inputs[inputScheduled].Value() != "2025-03-06" {
+		t.Errorf("编辑表单: %q %q", e.inputs[inputDue].Value(), e.inputs[inputScheduled].Value())
+	}
+}
+
+// TestCompleteRecurringTask 测试在列表中完成重复任务后出现下一次，计时采用当前设置的时长
+func TestCompleteRecurringTask(t *testing.T) {
+	m, _ := newListTestApp(t, "站会记录")
+	manager := m.taskManager
+	manager.Tasks[0].Recur, _ = task.ParseRecurrence("weekdays")
+	m.refreshList()
+
+	updateTaskListView(m, keyPress("c"))
+	items := m.list.Items()
+	if len(items) != 2 {
+		t.Fatalf("列表条目: %+v", items)
+	}
+	next := items[1].(task.Task)
+	if next.Done || next.Series != manager.Tasks[0].ID || next.Scheduled.IsZero() {
+		t.Errorf("下一次: %+v", next)
+	}
+	if next.Timer.TimerRemaining != 25*60 {
+		t.Errorf("下一次的计时应为设置中的时长: %+v", next.Timer)
+	}
+}
+
+// TestNewTaskUsesSettings 测试新任务和选中的任务按当前设置的番茄时长计时
+func TestNewTaskUsesSettings(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告")
+	m.settingModel.Settings.Pomodoro = 50
+
+	handleTaskCreated(m, taskCreatedMsg{taskFields{title: "读论文"}})
+	if got := m.taskManager.Tasks[1].Timer; got.TimerDuration != 50*60 || got.TimerRemaining != 50*60 {
+		t.Errorf("新任务的计时应为设置中的时长: %+v", got)
+	}
+
+	// 写报告保存的时长来自创建时的设置，选中后按当前设置计时
+	m.taskManager.Tasks[0].Timer = task.TimeModel{TimerDuration: 25 * 60, TimerRemaining: 25 * 60, IsWorkSession: true}
+	m.refreshList()
+	m.list.Select(m.listIndex(m.taskManager.Tasks[0].ID))
+	updateTaskListView(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if s := m.engine.Snapshot(); m.currentTaskID != m.taskManager.Tasks[0].ID || s.Planned != 50*time.Minute {
+		t.Errorf("选中任务后的会话: %s %+v", m.currentTaskID, s)
+	}
+}
+
+// TestMoveAndSortTasks 测试手动调整顺序会保存到 tasks.json，切换排序方式会记入设置且不影响正在计时的任务
+func TestMoveAndSortTasks(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告", "读书", "跑步")
+	manager := m.taskManager
+	manager.Tasks[2].Priority = task.PriorityHigh
+	m.refreshList()
+	m.currentTaskID = manager.Tasks[0].ID
+	names := func() string { return listNames(m) }
+
+	m.list.Select(2)
+	updateTaskListView(m, keyPress("K"))
+	updateTaskListView(m, keyPress("K"))
+	updateTaskListView(m, keyPress("K")) // 已在最上方
+	if got := names(); got != "跑步,写报告,读书" {
+		t.Errorf("上移后: %s", got)
+	}
+	if selected := m.list.SelectedItem().(task.Task); selected.Name != "跑步" {
+		t.Errorf("上移后应仍选中原任务: %s", selected.Name)
+	}
+	reloaded, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Tasks[0].Name != "跑步" {
+		t.Errorf("顺序未保存: %+v", reloaded.Tasks)
+	}
+
+	updateTaskListView(m, keyPress("o"))
+	if m.sortMode != task.SortPriority || m.settingModel.Settings.TaskSort != "priority" {
+		t.Errorf("排序方式 = %v, 设置 %q", m.sortMode, m.settingModel.Settings.TaskSort)
+	}
+	updateTaskListView(m, keyPress("J"))
+	if got := names(); got != "跑步,写报告,读书" {
+		t.Errorf("非手动顺序下不应调整位置: %s", got)
+	}
+	if m.currentTaskID != manager.Tasks[1].ID || m.currentTaskID != reloaded.Tasks[1].ID {
+		t.Errorf("排序后当前任务变了: %q", m.currentTaskID)
+	}
+}
+
+// TestUndoRedo 测试删除、完成、移动和编辑可以撤销和重做，删除的任务可在回收站中恢复
+func TestUndoRedo(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告", "读书", "跑步")
+	manager := m.taskManager
+	m.trashModel = NewTrashModel(keymap.NewTrashViewKeyMap(), keymap.NewConfirmKeyMap())
+	names := func() string { return listNames(m) }
+	undo, redo := keyPress("u"), tea.KeyMsg{Type: tea.KeyCtrlR}
+
+	m.list.Select(1)
+	updateTaskListView(m, keyPress("x"))
+	if got := names(); got != "写报告,跑步" || len(manager.Trash) != 1 {
+		t.Fatalf("删除后: %s, 回收站 %+v", got, manager.Trash)
+	}
+	updateTaskListView(m, undo)
+	if got := names(); got != "写报告,读书,跑步" || len(manager.Trash) != 0 {
+		t.Errorf("撤销删除后: %s", got)
+	}
+	updateTaskListView(m, redo)
+	if got := names(); got != "写报告,跑步" {
+		t.Errorf("重做删除后: %s", got)
+	}
+
+	m.list.Select(0)
+	updateTaskListView(m, keyPress("c"))
+	updateTaskListView(m, keyPress("J"))
+	if got := names(); got != "跑步,写报告" || !manager.Tasks[1].Done {
+		t.Fatalf("完成并移动后: %s", got)
+	}
+	updateTaskListView(m, undo)
+	updateTaskListView(m, undo)
+	if got := names(); got != "写报告,跑步" || manager.Tasks[0].Done {
+		t.Errorf("撤销移动和完成后: %s, %+v", got, manager.Tasks[0])
+	}
+
+	// 撤销编辑只恢复表单中的字段，期间完成的番茄数保留
+	id := manager.Tasks[0].ID
+	handleTaskEdited(m, taskEditedMsg{id: id, taskFields: taskFields{title: "写周报", estimate: 3}})
+	manager.Tasks[0].Pomodoros = 2
+	updateTaskListView(m, undo)
+	if got := manager.Tasks[0]; got.Name != "写报告" || got.Estimate != 0 || got.Pomodoros != 2 {
+		t.Errorf("撤销编辑后: %+v", got)
+	}
+	updateTaskListView(m, undo) // 撤销重做过的删除
+	if got := names(); got != "写报告,读书,跑步" || len(m.undo.done) != 0 {
+		t.Errorf("全部撤销后: %s", got)
+	}
+
+	// 在回收站中恢复的任务回到原位置
+	m.list.Select(2)
+	updateTaskListView(m, keyPress("x"))
+	updateTaskListView(m, keyPress("B"))
+	if m.currentView != trashView || len(m.trashModel.tasks) != 1 {
+		t.Fatalf("回收站: %+v", m.trashModel.tasks)
+	}
+	handleTrash(m, trashMsg{id: m.trashModel.tasks[0].ID, restore: true})
+	if got := names(); got != "写报告,读书,跑步" || len(m.trashModel.tasks) != 0 {
+		t.Errorf("从回收站恢复后: %s", got)
+	}
+}
+
+// TestEmptyTrashAsks 测试清空回收站前先确认，取消时不删除任何任务
+func TestEmptyTrashAsks(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告", "读书")
+	manager := m.taskManager
+	m.trashModel = NewTrashModel(keymap.NewTrashViewKeyMap(), keymap.NewConfirmKeyMap())
+	updateTaskListView(m, keyPress("x"))
+	updateTaskListView(m, keyPress("x"))
+	updateTaskListView(m, keyPress("B"))
+	// 确认后发出的 trashMsg 交给 App 处理
+	press := func(s string) {
+		var cmd tea.Cmd
+		m.trashModel, cmd = m.trashModel.Update(keyPress(s))
+		if cmd != nil {
+			if msg, ok := cmd().(trashMsg); ok {
+				handleTrash(m, msg)
+			}
+		}
+	}
+
+	press("X")
+	if !m.trashModel.confirming || len(manager.Trash) != 2 {
+		t.Fatalf("清空前应先确认: %+v", manager.Trash)
+	}
+	if view := m.trashModel.View(); !strings.Contains(view, "2") {
+		t.Errorf("确认提示应显示任务数: %s", view)
+	}
+	press("x") // 确认时忽略其他按键
+	press("n")
+	if m.trashModel.confirming || len(manager.Trash) != 2 {
+		t.Errorf("取消后不应删除: %+v", manager.Trash)
+	}
+
+	press("X")
+	press("y")
+	if m.trashModel.confirming || len(manager.Trash) != 0 || len(m.trashModel.tasks) != 0 {
+		t.Errorf("确认后应清空回收站: %+v", manager.Trash)
+	}
+}
+
+// TestSwitchList 测试新建和切换任务列表：运行中的会话被暂停，撤销记录清空，当前列表保存在设置中
+func TestSwitchList(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告")
+	manager := m.taskManager
+	m.currentTaskID = manager.Tasks[0].ID
+	m.listsModel = NewListsModel(keymap.NewListsViewKeyMap())
+	m.undo.push(addChange(manager.Tasks[0]))
+	send := func(msg tea.Msg) { m.Update(msg) }
+	// enter 返回的命令发出 listSwitchMsg，其他命令（如光标闪烁）不执行
+	enter := func() {
+		if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+			if msg, ok := cmd().(listSwitchMsg); ok {
+				m.Update(msg)
+			}
+		}
+	}
+
+	updateTaskListView(m, keyPress("L"))
+	if m.currentView != listsView || len(m.listsModel.lists) != 1 {
+		t.Fatalf("列表切换视图: %+v", m.listsModel.lists)
+	}
+	send(keyPress("n"))
+	send(keyPress("a/b"))
+	enter()
+	if m.listsModel.status == "" || !m.listsModel.adding || m.taskManager.List() != task.DefaultList {
+		t.Fatalf("无效的名称应当被拒绝: %q", m.listsModel.status)
+	}
+	m.listsModel.input.SetValue("")
+	send(keyPress("work"))
+	enter()
+	if m.currentView != taskListView || m.taskManager.List() != "work" || !task.ListExists("work") {
+		t.Fatalf("新建列表后: 视图 %d，列表 %s", m.currentView, m.taskManager.List())
+	}
+	if m.currentTaskID != "" || m.engine.State() == timer.StateRunning || len(m.undo.done) != 0 {
+		t.Errorf("切换后应暂停会话并清空撤销记录: %q %v %d", m.currentTaskID, m.engine.State(), len(m.undo.done))
+	}
+	if m.settingModel.Settings.TaskList != "work" || !strings.Contains(m.list.Title, "work") || len(m.list.Items()) != 0 {
+		t.Errorf("设置 %q，标题 %q，%d 个任务", m.settingModel.Settings.TaskList, m.list.Title, len(m.list.Items()))
+	}
+	m.taskManager.AddItem("周报", "")
+
+	updateTaskListView(m, keyPress("L"))
+	if len(m.listsModel.lists) != 2 || m.listsModel.cursor != 1 {
+		t.Fatalf("列表切换视图: %+v，光标 %d", m.listsModel.lists, m.listsModel.cursor)
+	}
+	send(keyPress("k"))
+	enter()
+	if m.taskManager.List() != task.DefaultList || len(m.list.Items()) != 1 || m.list.Items()[0].(task.Task).Name != "写报告" {
+		t.Errorf("切回默认列表后: %s %+v", m.taskManager.List(), m.list.Items())
+	}
+}
+
+// TestRecovery 测试任务文件损坏时，放弃不会修改文件，确认后从备份恢复
+func TestRecovery(t *testing.T) {
+	m, _ := newListTestApp(t, "写报告")
+	dir, err := paths.DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := safefile.Backup(filepath.Join(dir, "tasks.json"), func([]byte) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "tasks.json"), []byte("[{"), 0644)
+	_, err = task.NewManager("")
+	var corrupt *task.CorruptError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("NewManager = %v", err)
+	}
+
+	// 与启动时一样，文件损坏时使用不对应任何文件的占位列表
+	m.taskManager = &task.Manager{}
+	m.refreshList()
+	m.recoveryModel = NewRecoveryModel(corrupt, keymap.NewConfirmKeyMap())
+	m.currentView = recoveryView
+	if m.recoveryModel.backup == nil || m.recoveryModel.backup.Tasks != 1 {
+		t.Fatalf("应当找到备份: %+v", m.recoveryModel.backup)
+	}
+	press := func(s string) tea.Cmd {
+		_, cmd := m.Update(keyPress(s))
+		_, cmd = m.Update(cmd())
+		return cmd
+	}
+
+	if cmd := press("n"); cmd == nil {
+		t.Fatal("放弃时应当退出")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("放弃时应当退出")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "tasks.json")); string(data) != "[{" {
+		t.Errorf("放弃时不应修改文件: %q", data)
+	}
+
+	press("y")
+	if m.currentView != taskListView || len(m.list.Items()) != 1 || m.list.Items()[0].(task.Task).Name != "写报告" {
+		t.Fatalf("恢复后: 视图 %d，%+v", m.currentView, m.list.Items())
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "tasks.json.corrupt-*")); len(matches) != 1 {
+		t.Errorf("损坏的文件应当保留: %v", matches)
+	}
+}
+
+// TestSettingsRecovery 测试设置文件损坏时启动先询问恢复：恢复前不打开任务列表，
+// 放弃时不修改文件，恢复后按备份中的设置打开上次使用的列表
+func TestSettingsRecovery(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	defer func(interval time.Duration) { safefile.Interval = interval }(safefile.Interval)
+	safefile.Interval = 0
+	work, err := task.NewManager("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	work.AddItem("写报告", "")
+	settings := common.Settings{Pomodoro: 40, ShortBreak: 5, LongBreak: 15, Cycle: 4, Language: "zh", TaskList: "work"}
+	settings.Save()
+	settings.Save() // 第二次保存前备份了第一次的设置
+	configDir, _ := paths.ConfigDir()
+	path := filepath.Join(configDir, "setting.json")
+	os.WriteFile(path, []byte(`{"pomodoro": 4`), 0644)
+
+	m := NewApp()
+	if m.currentView != settingsRecoveryView || m.settingRecovery.backup == "" {
+		t.Fatalf("启动时应询问恢复设置: 视图 %d，%+v", m.currentView, m.settingRecovery)
+	}
+	dataDir, _ := paths.DataDir()
+	if _, err := os.Stat(filepath.Join(dataDir, "tasks.json")); !os.IsNotExist(err) {
+		t.Error("恢复设置前不应打开或创建默认列表")
+	}
+	var model tea.Model = m
+	press := func(s string) tea.Cmd {
+		var cmd tea.Cmd
+		model, cmd = model.Update(keyPress(s))
+		model, cmd = model.Update(cmd())
+		return cmd
+	}
+
+	if cmd := press("n"); cmd == nil {
+		t.Fatal("放弃时应当退出")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("放弃时应当退出")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"pomodoro": 4` {
+		t.Errorf("放弃时不应修改文件: %q", data)
+	}
+
+	press("y")
+	app := model.(*App)
+	if app.currentView != taskListView || app.settingModel.Settings.Pomodoro != 40 || app.taskManager.List() != "work" {
+		t.Fatalf("恢复后: 视图 %d，设置 %+v，列表 %q", app.currentView, app.settingModel.Settings, app.taskManager.List())
+	}
+	if matches, _ := filepath.Glob(path + ".corrupt-*"); len(matches) != 1 {
+		t.Errorf("损坏的文件应当保留: %v", matches)
+	}
+}
+
+// TestTickKeepsCLIChanges 测试本地计时期间，命令行对任务文件的修改不会被每秒的保存覆盖
+func TestTickKeepsCLIChanges(t *testing.T) {
+	m, clock := newListTestApp(t, "写报告", "读书")
+	m.currentTaskID = m.taskManager.Tasks[0].ID
+	m.startTimer()
+
+	// 与 gomato add、gomato done 一样，读取任务文件、修改并保存
+	cli, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.AddItem("跑步", "")
+	if err := cli.SetDone(cli.Tasks[1].ID, true, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
+
+	reloaded, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Tasks) != 3 || !reloaded.Tasks[1].Done {
+		t.Fatalf("命令行的修改被覆盖: %+v", reloaded.Tasks)
+	}
+	if tm := reloaded.Tasks[0].Timer; !tm.TimerIsRunning || tm.StartedAt.IsZero() {
+		t.Errorf("当前任务的计时未保存: %+v", tm)
+	}
+	if got := listNames(m); got != "写报告,读书,跑步" {
+		t.Errorf("列表未显示命令行添加的任务: %s", got)
+	}
+}
+
+// TestRemoteKeepsDaemonPomodoros 测试连接守护进程时，守护进程写入任务文件的番茄数
+// 不会被之后在 TUI 中编辑任务时的保存覆盖
+func TestRemoteKeepsDaemonPomodoros(t *testing.T) {
+	m, clock := newListTestApp(t, "写报告", "读书")
+	first, second := m.taskManager.Tasks[0], m.taskManager.Tasks[1]
+
+	// 与守护进程的 countPomodoro 一样，读取任务文件、计入番茄并保存
+	daemon, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counted, _ := daemon.Get(first.ID)
+	counted.CountPomodoro(clock.Now())
+	if err := daemon.Update(counted); err != nil {
+		t.Fatal(err)
+	}
+
+	m.applyRemote(&task.ActiveState{TaskID: first.ID, TaskName: first.Name, Timer: first.Timer})
+	handleTaskEdited(m, taskEditedMsg{id: second.ID, taskFields: taskFields{title: "读论文"}})
+
+	reloaded, err := task.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reloaded.Get(first.ID); got.Pomodoros != 1 {
+		t.Errorf("守护进程计入的番茄被覆盖: %+v", got)
+	}
+	if got, _ := reloaded.Get(second.ID); got.Name != "读论文" {
+		t.Errorf("编辑未保存: %+v", got)
+	}
+	if item := m.list.Items()[0].(task.Task); item.Pomodoros != 1 {
+		t.Errorf("列表未显示守护进程计入的番茄: %+v", item)
+	}
+}
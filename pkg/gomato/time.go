@@ -3,8 +3,13 @@ package gomato
 import (
 	"fmt"
 	"gomato/pkg/common"
+	"gomato/pkg/daemon"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
 	"gomato/pkg/logging"
 	"gomato/pkg/notice"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -15,94 +20,155 @@ var (
 	statusMessageStyle = common.StatusMessageStyle
 )
 
-type tickMsg struct{}
+// timerView 显示计时界面，settings 决定时间的显示方式和是否紧凑
+func timerView(t task.TimeModel, settings *common.Settings) string {
+	min := t.TimerRemaining / 60
+	sec := t.TimerRemaining % 60
+	remainStr := fmt.Sprintf("%02d:%02d", min, sec)
 
-func tick() tea.Cmd {
+	// 根据设置选择时间显示方式
+	var timeDisplay string
+	if settings != nil {
+		timeDisplay = settings.RenderTime(remainStr)
+	} else {
+		timeDisplay = common.TimeToAnsiArt(remainStr)
+	}
+
+	status := i18n.T("timer.status", i18n.T("timer.paused"))
+	if t.TimerIsRunning {
+		status = i18n.T("timer.status", i18n.T("timer.running"))
+	}
+	controls := i18n.T("timer.controls", keymap.HelpKey("timer.startPause"), keymap.HelpKey("timer.reset"),
+		keymap.HelpKey("timer.skip"), keymap.HelpKey("timer.back"))
+	if settings != nil && settings.Compact {
+		// 紧凑模式只显示时间、状态和按键提示
+		return timeDisplay + "\n" + status + "  " + common.StatusMessageStyle(controls)
+	}
+	if t.IsWorkSession {
+		return common.TitleStyle.Render(i18n.T("timer.title")) + "\n\n" +
+			timeDisplay + "\n\n" +
+			status + "\n\n" +
+			common.StatusMessageStyle(controls) + "\n\n" +
+			i18n.T("timer.focus")
+	} else if !t.IsWorkSession && t.TimerIsRunning {
+		return common.TitleStyle.Render(i18n.T("timer.title")) + "\n\n" +
+			timeDisplay + "\n\n" +
+			status + "\n\n" +
+			common.StatusMessageStyle(controls) + "\n\n" +
+			i18n.T("timer.relax")
+	} else {
+		return common.TitleStyle.Render(i18n.T("timer.title")) + "\n\n" +
+			timeDisplay + "\n\n" +
+			status + "\n\n" +
+			common.StatusMessageStyle(controls) + "\n\n" +
+			i18n.T("timer.relax") + "\n\n" +
+			i18n.T("timer.startHint", keymap.HelpKey("timer.startPause"), keymap.HelpKey("timer.reset"))
+	}
+}
+
+// tickMsg 只用于刷新显示，剩余时间始终由截止时间和当前时间计算。
+// gen 标记 tick 链的代数，过期链上的 tick 会被忽略。
+type tickMsg struct {
+	gen int
+}
+
+func tick(gen int) tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return tickMsg{}
+		return tickMsg{gen: gen}
 	})
 }
 
-func handleTick(m *App) tea.Cmd {
-	if m.timeModel.TimerIsRunning && m.timeModel.TimerRemaining > 0 {
-		m.timeModel.TimerRemaining--
-		logging.Log(fmt.Sprintf("[Tick] Timer ticked, remaining: %d", m.timeModel.TimerRemaining))
-		if m.timeModel.TimerRemaining == 0 {
-			if m.timeModel.IsWorkSession {
-				// 工作结束，cycle计数+1
-				m.CurrentCycleCount++
-				logging.Log(fmt.Sprintf("[Cycle] 完成一次工作，当前cycle计数: %d/%d", m.CurrentCycleCount, m.settingModel.Settings.Cycle))
-				if m.CurrentCycleCount < int(m.settingModel.Settings.Cycle) {
-					// 进入短休息
-					m.timeModel.IsWorkSession = false
-					m.timeModel.TimerRemaining = int(m.settingModel.Settings.ShortBreak) * 60
-					statusMsg := fmt.Sprintf("工作结束，开始休息！\n现在是休息时间！(第%d/%d次)", m.CurrentCycleCount, m.settingModel.Settings.Cycle)
-					// 通知：工作结束
-					notice.SendNotification("番茄钟", "工作时间结束，开始休息！")
-					if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-						m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-						m.taskManager.Save()
-					}
-					return tea.Batch(
-						m.list.NewStatusMessage(statusMessageStyle(statusMsg)),
-						tick(),
-					)
-				} else {
-					// 达到cycle，进入长休息
-					logging.Log("[Cycle] 达到cycle上限，进入长休息，重置cycle计数")
-					m.CurrentCycleCount = 0
-					m.timeModel.IsWorkSession = false
-					m.timeModel.TimerRemaining = int(m.settingModel.Settings.LongBreak) * 60
-					statusMsg := "本周期已完成，进入长休息！"
-					// 通知：本周期已完成，进入长休息
-					notice.SendNotification("番茄钟", "本周期已完成，进入长休息！")
-					if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-						m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-						m.taskManager.Save()
-					}
-					return tea.Batch(
-						m.list.NewStatusMessage(statusMessageStyle(statusMsg)),
-						tick(),
-					)
-				}
-			} else {
-				// 休息结束，回到工作
-				m.timeModel.IsWorkSession = true
-				m.timeModel.TimerIsRunning = true // 自动开始新一轮
-				m.timeModel.TimerRemaining = int(m.settingModel.Settings.Pomodoro) * 60
-				logging.Log(fmt.Sprintf("[Cycle] 休息结束，开始新一轮工作。当前cycle计数: %d/%d", m.CurrentCycleCount, m.settingModel.Settings.Cycle))
-				// 通知：休息结束，开始新一轮工作
-				notice.SendNotification("番茄钟", "休息结束，开始新一轮工作！")
-				if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-					m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-					m.taskManager.Save()
-				}
-				return tea.Batch(
-					m.list.NewStatusMessage(statusMessageStyle("休息结束，开始新一轮工作！")),
-					tick(),
-				)
-			}
-		}
+// startTicking 开启一条新的 tick 链，之前的链随之失效
+func (m *App) startTicking() tea.Cmd {
+	m.tickGen++
+	return tick(m.tickGen)
+}
+
+// now 返回当前时间，测试中可通过 clock 注入
+func (m *App) now() time.Time {
+	if m.clock != nil {
+		return m.clock.Now()
+	}
+	return time.Now()
+}
+
+// timeModel 返回当前计时器用于显示和持久化的形式
+func (m *App) timeModel() task.TimeModel {
+	return task.NewTimeModel(m.engine.Snapshot(), m.now())
+}
+
+func handleTick(m *App, msg tickMsg) tea.Cmd {
+	if m.remote != nil {
+		return handleRemoteTick(m, msg)
+	}
+	if msg.gen != m.tickGen || m.engine.State() != timer.StateRunning {
+		return nil
+	}
+	tm := m.timeModel()
+	logging.Log(fmt.Sprintf("[Tick] Timer ticked, remaining: %d", tm.TimerRemaining))
+
+	// 截止时间已过则结束会话并进入下一阶段，即使 tick 因挂起等原因迟到
+	m.engine.Tick()
+	cmds := m.flushTimerEvents()
+	if len(cmds) == 0 {
 		// 根据设置选择时间显示方式
-		var timeDisplay string
-		if m.settingModel.Settings.TimeDisplayMode == "normal" {
-			timeDisplay = fmt.Sprintf("%02d:%02d", m.timeModel.TimerRemaining/60, m.timeModel.TimerRemaining%60)
-		} else {
-			timeDisplay = common.TimeToAnsiArt(fmt.Sprintf("%02d:%02d", m.timeModel.TimerRemaining/60, m.timeModel.TimerRemaining%60))
-		}
-		statusMsg := fmt.Sprintf("剩余时间: %s", timeDisplay)
-		statusCmd := m.list.NewStatusMessage(statusMessageStyle(statusMsg))
-		if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-			m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-			m.taskManager.Save()
-		}
-		// 只有在计时器仍在运行时才返回tick命令
-		if m.timeModel.TimerIsRunning && m.timeModel.TimerRemaining > 0 {
-			return tea.Batch(statusCmd, tick())
-		}
-		return statusCmd
+		timeDisplay := m.settingModel.Settings.RenderTime(fmt.Sprintf("%02d:%02d", tm.TimerRemaining/60, tm.TimerRemaining%60))
+		statusMsg := i18n.T("app.remaining", timeDisplay)
+		cmds = append(cmds, m.list.NewStatusMessage(statusMessageStyle(statusMsg)))
 	}
-	return nil
+	m.saveCurrentTimer()
+	// 只有在计时器仍在运行时才返回tick命令
+	if m.engine.State() == timer.StateRunning {
+		cmds = append(cmds, tick(msg.gen))
+	}
+	return tea.Batch(cmds...)
+}
+
+// onTimerEvent 订阅计时器状态机的事件：记录历史、发送通知并准备状态栏消息
+func (m *App) onTimerEvent(ev timer.Event) {
+	switch ev.Type {
+	case timer.SessionCompleted, timer.Skipped, timer.Reset:
+		m.recordSession(ev.Session, ev.Type == timer.SessionCompleted)
+	}
+	if title, msg, ok := notice.ForEvent(m.settingModel.Settings.Notifications, ev); ok {
+		m.notifier.Send(title, msg)
+	}
+	if ev.Type != timer.SessionCompleted {
+		return
+	}
+	if ev.Session.Kind == timer.Work {
+		// 番茄数随之后的 saveCurrentTimer 一起保存，先读入其他进程的修改，
+		// 否则保存前重新读取时会丢掉这个番茄
+		m.reloadTasks()
+	}
+	if t := m.currentTask(); t != nil && ev.Session.Kind == timer.Work {
+		t.CountPomodoro(ev.Session.End)
+		m.pendingCmds = append(m.pendingCmds, m.updateListItem(*t))
+		m.offerChecklistPrompt()
+	}
+
+	cycle := m.settingModel.Settings.Cycle
+	var statusMsg string
+	switch ev.Next {
+	case timer.ShortBreak:
+		logging.Log(fmt.Sprintf("[Cycle] 完成一次工作，当前cycle计数: %d/%d", ev.Cycle, cycle))
+		statusMsg = i18n.T("app.workEnd", ev.Cycle, cycle)
+	case timer.LongBreak:
+		logging.Log(fmt.Sprintf("[Cycle] 完成一次工作，当前cycle计数: %d/%d", cycle, cycle))
+		logging.Log("[Cycle] 达到cycle上限，进入长休息，重置cycle计数")
+		statusMsg = i18n.T("app.cycleEnd")
+	default:
+		logging.Log(fmt.Sprintf("[Cycle] 休息结束，开始新一轮工作。当前cycle计数: %d/%d", ev.Cycle, cycle))
+		statusMsg = i18n.T("app.breakEnd")
+	}
+	m.pendingCmds = append(m.pendingCmds, m.list.NewStatusMessage(statusMessageStyle(statusMsg)))
+}
+
+// flushTimerEvents 取出事件处理过程中产生的命令
+func (m *App) flushTimerEvents() []tea.Cmd {
+	cmds := m.pendingCmds
+	m.pendingCmds = nil
+	return cmds
 }
 
 func updateTimeView(m *App, msg tea.Msg) tea.Cmd {
@@ -113,27 +179,119 @@ func updateTimeView(m *App, msg tea.Msg) tea.Cmd {
 
 	switch {
 	case key.Matches(keyMsg, m.timeViewKeys.Back):
-		if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-			m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-			m.taskManager.Save()
-		}
+		m.saveCurrentTimer()
 		m.currentView = taskListView
 		return nil
 	case key.Matches(keyMsg, m.timeViewKeys.StartPause):
-		m.timeModel.TimerIsRunning = !m.timeModel.TimerIsRunning
-		if m.timeModel.TimerIsRunning && m.timeModel.TimerRemaining > -2 {
-			return tick()
+		if m.engine.State() == timer.StateRunning {
+			m.pauseTimer()
+			return tea.Batch(m.flushTimerEvents()...)
 		}
-		return nil
-	case key.Matches(keyMsg, m.timeViewKeys.Reset):
-		m.timeModel.TimerIsRunning = false
-		m.timeModel.IsWorkSession = true
-		m.timeModel.TimerRemaining = int(m.settingModel.Settings.Pomodoro) * 60
-		if m.currentTaskIndex >= 0 && m.currentTaskIndex < len(m.taskManager.Tasks) {
-			m.taskManager.Tasks[m.currentTaskIndex].Timer = m.timeModel
-			m.taskManager.Save()
+		m.startTimer()
+		return tea.Batch(append(m.flushTimerEvents(), m.startTicking())...)
+	case key.Matches(keyMsg, m.timeViewKeys.Skip):
+		m.skipTimer()
+		cmds := m.flushTimerEvents()
+		if m.engine.State() == timer.StateRunning {
+			cmds = append(cmds, m.startTicking())
 		}
-		return nil
+		return tea.Batch(cmds...)
+	case key.Matches(keyMsg, m.timeViewKeys.Reset):
+		// 重置视为放弃当前会话
+		m.resetTimer()
+		return tea.Batch(m.flushTimerEvents()...)
 	}
 	return nil
 }
+
+// resetTimer 放弃当前会话，恢复为一个尚未开始的工作会话
+func (m *App) resetTimer() {
+	if m.remote != nil {
+		m.remoteDo(daemon.CmdReset, "")
+		return
+	}
+	m.engine.Reset()
+	m.saveCurrentTimer()
+}
+
+// startTimer 开始或继续当前会话，继续时截止时间顺延暂停的时长
+func (m *App) startTimer() {
+	if m.remote != nil {
+		m.remoteDo(daemon.CmdResume, "")
+		return
+	}
+	m.engine.Start()
+	m.saveCurrentTimer()
+}
+
+// pauseTimer 暂停当前会话，剩余时间冻结在暂停时刻
+func (m *App) pauseTimer() {
+	if m.remote != nil {
+		m.remoteDo(daemon.CmdPause, "")
+		return
+	}
+	m.engine.Pause()
+	m.saveCurrentTimer()
+}
+
+// skipTimer 结束当前阶段，直接进入下一阶段
+func (m *App) skipTimer() {
+	if m.remote != nil {
+		m.remoteDo(daemon.CmdSkip, "")
+		return
+	}
+	m.engine.Skip()
+	m.saveCurrentTimer()
+}
+
+// saveCurrentTimer 把当前计时器写回所属任务，并保存运行状态快照以便重启后恢复。
+// 连接守护进程时由守护进程负责保存。
+func (m *App) saveCurrentTimer() {
+	tm := m.timeModel()
+	if m.remote != nil {
+		if t := m.currentTask(); t != nil {
+			t.Timer = tm
+		}
+		return
+	}
+	// 计时期间每秒保存一次，先读入命令行的修改，只写回当前任务的计时
+	m.reloadTasks()
+	state := task.ActiveState{
+		TaskID:     m.currentTaskID,
+		TaskIndex:  m.taskManager.Index(m.currentTaskID),
+		List:       m.taskManager.List(),
+		Timer:      tm,
+		CycleCount: m.engine.Cycle(),
+		SavedAt:    m.now(),
+	}
+	if t := m.currentTask(); t != nil {
+		t.Timer = tm
+		m.taskManager.Save()
+		state.TaskName = t.Name
+	}
+	if m.stateStore == nil {
+		return
+	}
+	if err := m.stateStore.Save(state); err != nil {
+		logging.Log(fmt.Sprintf("[State] 保存计时状态失败: %v", err))
+	}
+}
+
+// recordSession 将结束的会话写入历史记录，completed 为 false 表示会话被放弃
+func (m *App) recordSession(s timer.Session, completed bool) {
+	if s.Start.IsZero() {
+		return
+	}
+	var t task.Task
+	if current := m.currentTask(); current != nil {
+		t = *current
+	}
+	session := task.NewSession(t, s, completed)
+	session.List = m.taskManager.List()
+	if m.history == nil {
+		return
+	}
+	if err := m.history.Append(session); err != nil {
+		logging.Log(fmt.Sprintf("[History] 写入会话记录失败: %v", err))
+	}
+}
@@ -4,20 +4,56 @@ import (
 	"fmt"
 	"gomato/pkg/common"
 	"gomato/pkg/logging"
+	"gomato/pkg/notice"
 	"gomato/pkg/task"
+	"gomato/pkg/timer"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 )
 
+// fakeClock 是可手动拨动的时钟，用于在测试中模拟时间流逝
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTickTestApp 构造一个剩余 remaining 秒、已开始计时的 App
+func newTickTestApp(remaining int) (*App, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)}
+	settings := common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4}
+	m := &App{
+		settingModel: SettingModel{Settings: settings},
+		taskManager:  &task.Manager{Tasks: []task.Task{}},
+		list:         list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
+		engine:       timer.New(settings.TimerConfig(), clock),
+		clock:        clock,
+	}
+	d := time.Duration(remaining) * time.Second
+	m.engine.Restore(timer.Snapshot{Kind: timer.Work, Planned: d, Remaining: d})
+	m.engine.Subscribe(m.onTimerEvent)
+	m.startTimer()
+	m.startTicking()
+	return m, clock
+}
+
+// currentTick 返回当前 tick 链上的 tick 消息
+func currentTick(m *App) tickMsg {
+	return tickMsg{gen: m.tickGen}
+}
+
 func TestHandleTick_Log(t *testing.T) {
-	// 清空日志文件（先删再初始化）
-	home, _ := os.UserHomeDir()
-	logPath := home + "/.gomato/gomato.log"
-	os.Remove(logPath)
+	// 日志写到临时目录，不影响用户的日志文件
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+	logPath := filepath.Join(dir, "gomato.log")
 
 	// 初始化日志
 	err := logging.Init()
@@ -26,22 +62,9 @@ func TestHandleTick_Log(t *testing.T) {
 	}
 	defer logging.Close()
 
-	// 构造 App
-	taskMgr := &task.Manager{Tasks: []task.Task{}}
-	m := &App{
-		timeModel: task.TimeModel{
-			TimerIsRunning: true,
-			TimerRemaining: 2,
-			IsWorkSession:  true,
-		},
-		settingModel: SettingModel{
-			Settings: common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4},
-		},
-		taskManager: taskMgr,
-		list:        list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-	}
-
-	handleTick(m)
+	m, clock := newTickTestApp(2)
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
 
 	// 检查日志内容
 	data, err := os.ReadFile(logPath)
@@ -53,12 +76,12 @@ func TestHandleTick_Log(t *testing.T) {
 	}
 }
 
-// TestTimerTickFrequency 测试计时器每秒只触发一次
+// TestTimerTickFrequency 测试剩余时间随墙上时钟每秒递减
 func TestTimerTickFrequency(t *testing.T) {
-	// 清空日志文件
-	home, _ := os.UserHomeDir()
-	logPath := home + "/.gomato/gomato.log"
-	os.Remove(logPath)
+	// 日志写到临时目录，不影响用户的日志文件
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+	logPath := filepath.Join(dir, "gomato.log")
 
 	// 初始化日志
 	err := logging.Init()
@@ -67,31 +90,14 @@ func TestTimerTickFrequency(t *testing.T) {
 	}
 	defer logging.Close()
 
-	// 构造 App
-	taskMgr := &task.Manager{Tasks: []task.Task{}}
-	m := &App{
-		timeModel: task.TimeModel{
-			TimerIsRunning: true,
-			TimerRemaining: 5, // 设置5秒
-			IsWorkSession:  true,
-		},
-		settingModel: SettingModel{
-			Settings: common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4},
-		},
-		taskManager: taskMgr,
-		list:        list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-	}
+	m, clock := newTickTestApp(5) // 设置5秒
+	initialRemaining := m.timeModel().TimerRemaining
 
 	// 模拟2秒的时间，每秒调用一次handleTick
-	initialRemaining := m.timeModel.TimerRemaining
-
-	// 第一次tick
-	handleTick(m)
-	time.Sleep(100 * time.Millisecond) // 短暂等待
-
-	// 第二次tick
-	handleTick(m)
-	time.Sleep(100 * time.Millisecond) // 短暂等待
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Second)
+		handleTick(m, currentTick(m))
+	}
 
 	// 检查日志文件
 	data, err := os.ReadFile(logPath)
@@ -109,20 +115,12 @@ func TestTimerTickFrequency(t *testing.T) {
 
 	// 检查剩余时间是否正确递减
 	expectedRemaining := initialRemaining - 2
-	if m.timeModel.TimerRemaining != expectedRemaining {
-		t.Errorf("期望剩余时间%d，但实际是%d", expectedRemaining, m.timeModel.TimerRemaining)
-	}
-
-	// 检查日志中的时间序列是否正确
-	lines := strings.Split(string(data), "\n")
-	tickLines := []string{}
-	for _, line := range lines {
-		if strings.Contains(line, "[Tick] Timer ticked") {
-			tickLines = append(tickLines, line)
-		}
+	if m.timeModel().TimerRemaining != expectedRemaining {
+		t.Errorf("期望剩余时间%d，但实际是%d", expectedRemaining, m.timeModel().TimerRemaining)
 	}
 
 	// 验证时间序列：应该是 4, 3 (从5开始，每次减1)
+	tickLines := tickLogLines(string(data))
 	if len(tickLines) >= 2 {
 		if !strings.Contains(tickLines[0], "remaining: 4") {
 			t.Errorf("第一次tick应该显示remaining: 4，但显示: %s", tickLines[0])
@@ -133,12 +131,35 @@ func TestTimerTickFrequency(t *testing.T) {
 	}
 }
 
-// TestBubbleTeaTickSimulation 模拟Bubble Tea事件循环中的重复tick问题
+// TestBubbleTeaTickSimulation 模拟Bubble Tea事件循环中快速到达的重复tick，
+// 剩余时间只取决于墙上时钟，不应被多余的tick加速
 func TestBubbleTeaTickSimulation(t *testing.T) {
-	// 清空日志文件
-	home, _ := os.UserHomeDir()
-	logPath := home + "/.gomato/gomato.log"
-	os.Remove(logPath)
+	m, clock := newTickTestApp(3)
+
+	// 同一秒内连续处理多个tick消息
+	for i := 0; i < 3; i++ {
+		if cmd := handleTick(m, currentTick(m)); cmd != nil {
+			t.Logf("第%d次tick返回了命令", i+1)
+		}
+	}
+	if m.timeModel().TimerRemaining != 3 {
+		t.Errorf("时钟未前进时剩余时间不应变化，期望3，实际%d", m.timeModel().TimerRemaining)
+	}
+
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
+	handleTick(m, currentTick(m))
+	if m.timeModel().TimerRemaining != 2 {
+		t.Errorf("期望剩余时间2，实际%d", m.timeModel().TimerRemaining)
+	}
+}
+
+// TestTimerNoDuplicateTicks 测试过期tick链上的tick会被忽略
+func TestTimerNoDuplicateTicks(t *testing.T) {
+	// 日志写到临时目录，不影响用户的日志文件
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+	logPath := filepath.Join(dir, "gomato.log")
 
 	// 初始化日志
 	err := logging.Init()
@@ -147,30 +168,22 @@ func TestBubbleTeaTickSimulation(t *testing.T) {
 	}
 	defer logging.Close()
 
-	// 构造 App
-	taskMgr := &task.Manager{Tasks: []task.Task{}}
-	m := &App{
-		timeModel: task.TimeModel{
-			TimerIsRunning: true,
-			TimerRemaining: 3,
-			IsWorkSession:  true,
-		},
-		settingModel: SettingModel{
-			Settings: common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4},
-		},
-		taskManager: taskMgr,
-		list:        list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-	}
-
-	// 模拟Bubble Tea事件循环：连续处理多个tick消息
-	// 这可能会触发重复的tick命令
+	m, clock := newTickTestApp(3)
+
+	// 暂停后立即继续会开启新的tick链，旧链上的tick应被丢弃
+	stale := currentTick(m)
+	m.pauseTimer()
+	m.startTimer()
+	m.startTicking()
+	if cmd := handleTick(m, stale); cmd != nil {
+		t.Errorf("过期的tick不应返回命令")
+	}
+
 	for i := 0; i < 3; i++ {
-		cmd := handleTick(m)
-		if cmd != nil {
-			// 模拟执行命令（这里只是记录，实际应该由Bubble Tea处理）
-			t.Logf("第%d次tick返回了命令", i+1)
-		}
-		time.Sleep(50 * time.Millisecond) // 模拟快速连续的处理
+		clock.Advance(time.Second)
+		handleTick(m, stale)
+		handleTick(m, currentTick(m))
+		t.Logf("第%d次tick后剩余时间: %d", i+1, m.timeModel().TimerRemaining)
 	}
 
 	// 检查日志文件
@@ -179,42 +192,62 @@ func TestBubbleTeaTickSimulation(t *testing.T) {
 		t.Fatalf("读取日志文件失败: %v", err)
 	}
 
-	// 计算tick日志的数量
-	tickCount := strings.Count(string(data), "[Tick] Timer ticked")
-	t.Logf("总共记录了%d次tick", tickCount)
-
-	// 检查剩余时间
-	if m.timeModel.TimerRemaining < 0 {
-		t.Errorf("剩余时间不应该为负数: %d", m.timeModel.TimerRemaining)
+	// 应该只有3次tick（因为TimerRemaining从3开始，减到0）
+	tickLines := tickLogLines(string(data))
+	if len(tickLines) != 3 {
+		t.Fatalf("期望3次tick，但实际有%d次tick", len(tickLines))
 	}
 
-	// 检查日志中的时间序列
-	lines := strings.Split(string(data), "\n")
-	tickLines := []string{}
-	for _, line := range lines {
-		if strings.Contains(line, "[Tick] Timer ticked") {
-			tickLines = append(tickLines, line)
+	// 验证时间序列：应该是 2, 1, 0 (从3开始，每次减1)
+	for i, want := range []int{2, 1, 0} {
+		if got := extractRemainingTime(tickLines[i]); got != want {
+			t.Errorf("第%d次tick应该显示remaining: %d，但显示: %s", i+1, want, tickLines[i])
 		}
 	}
 
-	// 输出所有tick日志用于调试
-	for i, line := range tickLines {
-		t.Logf("Tick %d: %s", i+1, line)
+	// 倒计时结束后自动进入短休息
+	if m.engine.Kind() != timer.ShortBreak || m.timeModel().TimerRemaining != 5*60 {
+		t.Errorf("期望进入5分钟的短休息，实际: %+v", m.timeModel())
 	}
+	if m.engine.Cycle() != 1 {
+		t.Errorf("期望cycle计数为1，实际%d", m.engine.Cycle())
+	}
+}
+
+// TestTimerSurvivesSuspendAndPause 测试挂起导致tick迟到、以及暂停期间的剩余时间
+func TestTimerSurvivesSuspendAndPause(t *testing.T) {
+	m, clock := newTickTestApp(25 * 60)
 
-	// 验证时间序列应该是递减的
-	for i := 1; i < len(tickLines); i++ {
-		prevLine := tickLines[i-1]
-		currLine := tickLines[i]
+	// 系统挂起10分钟后才收到下一次tick
+	clock.Advance(10 * time.Minute)
+	handleTick(m, currentTick(m))
+	if m.timeModel().TimerRemaining != 15*60 {
+		t.Errorf("期望剩余15分钟，实际%d秒", m.timeModel().TimerRemaining)
+	}
 
-		// 提取剩余时间数字
-		prevRemaining := extractRemainingTime(prevLine)
-		currRemaining := extractRemainingTime(currLine)
+	// 暂停5分钟不应消耗剩余时间
+	m.pauseTimer()
+	clock.Advance(5 * time.Minute)
+	m.startTimer()
+	clock.Advance(30 * time.Second)
+	handleTick(m, currentTick(m))
+	if m.timeModel().TimerRemaining != 15*60-30 {
+		t.Errorf("期望剩余%d秒，实际%d秒", 15*60-30, m.timeModel().TimerRemaining)
+	}
+	if m.timeModel().PausedSeconds != 5*60 {
+		t.Errorf("期望累计暂停300秒，实际%d秒", m.timeModel().PausedSeconds)
+	}
+}
 
-		if prevRemaining != -1 && currRemaining != -1 && prevRemaining <= currRemaining {
-			t.Errorf("时间序列应该递减，但 %d <= %d", prevRemaining, currRemaining)
+// tickLogLines 返回日志中的所有tick行
+func tickLogLines(data string) []string {
+	var tickLines []string
+	for _, line := range strings.Split(data, "\n") {
+		if strings.Contains(line, "[Tick] Timer ticked") {
+			tickLines = append(tickLines, line)
 		}
 	}
+	return tickLines
 }
 
 // extractRemainingTime 从日志行中提取剩余时间数字
@@ -235,105 +268,83 @@ func extractRemainingTime(line string) int {
 	return num
 }
 
-// TestTimerNoDuplicateTicks 测试修复后的计时器不会重复tick
-func TestTimerNoDuplicateTicks(t *testing.T) {
-	// 清空日志文件
-	home, _ := os.UserHomeDir()
-	logPath := home + "/.gomato/gomato.log"
-	os.Remove(logPath)
-
-	// 初始化日志
-	err := logging.Init()
-	if err != nil {
-		t.Fatalf("日志初始化失败: %v", err)
+// TestResumeExpiredSession 测试程序关闭期间已到期的会话在恢复后按截止时间结束
+func TestResumeExpiredSession(t *testing.T) {
+	m, clock := newTickTestApp(25 * 60)
+	m.taskManager.Tasks = []task.Task{{Name: "写报告"}}
+	state := task.ActiveState{TaskIndex: 0, TaskName: "写报告", Timer: m.timeModel(), CycleCount: 1}
+
+	// 模拟重启：新的 App 在 40 分钟后恢复上次的状态
+	clock.Advance(40 * time.Minute)
+	restarted := &App{
+		settingModel: m.settingModel,
+		taskManager:  m.taskManager,
+		list:         list.New([]list.Item{task.Task{Name: "写报告"}}, list.NewDefaultDelegate(), 0, 0),
+		engine:       timer.New(m.settingModel.Settings.TimerConfig(), clock),
+		clock:        clock,
+		resumeModel:  NewResumeModel(state, nil, clock.Now()),
 	}
-	defer logging.Close()
-
-	// 构造 App
-	taskMgr := &task.Manager{Tasks: []task.Task{}}
-	m := &App{
-		timeModel: task.TimeModel{
-			TimerIsRunning: true,
-			TimerRemaining: 3,
-			IsWorkSession:  true,
-		},
-		settingModel: SettingModel{
-			Settings: common.Settings{Pomodoro: 25, ShortBreak: 5, LongBreak: 15, Cycle: 4},
-		},
-		taskManager: taskMgr,
-		list:        list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-	}
-
-	// 模拟Bubble Tea事件循环：快速连续处理tick消息
-	// 这应该不会导致重复的tick
-	initialRemaining := m.timeModel.TimerRemaining
-	t.Logf("初始剩余时间: %d", initialRemaining)
-
-	for i := 0; i < 3; i++ {
-		cmd := handleTick(m)
-		if cmd != nil {
-			t.Logf("第%d次tick返回了命令", i+1)
-		}
-		t.Logf("第%d次tick后剩余时间: %d", i+1, m.timeModel.TimerRemaining)
-		// 不等待，模拟快速连续的处理
+	restarted.engine.Subscribe(restarted.onTimerEvent)
+	_, cmd := handleResume(restarted, resumeMsg{accept: true})
+	if cmd == nil || restarted.currentView != timeView {
+		t.Fatalf("恢复运行中的会话应进入计时界面并开始tick")
 	}
 
-	// 检查日志文件
-	data, err := os.ReadFile(logPath)
-	if err != nil {
-		t.Fatalf("读取日志文件失败: %v", err)
+	handleTick(restarted, currentTick(restarted))
+	if restarted.engine.Cycle() != 2 {
+		t.Errorf("期望cycle计数为2，实际%d", restarted.engine.Cycle())
 	}
+	tm := restarted.timeModel()
+	if tm.Type() != task.SessionShortBreak || !tm.StartedAt.Equal(clock.Now()) {
+		t.Errorf("期望从现在开始短休息，实际: %+v", tm)
+	}
+}
 
-	// 计算tick日志的数量
-	tickCount := strings.Count(string(data), "[Tick] Timer ticked")
-	t.Logf("总共记录了%d次tick", tickCount)
+// recordingNotifier 记录收到的通知
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []string
+}
 
-	// 应该只有3次tick（因为TimerRemaining从3开始，减到0）
-	if tickCount != 3 {
-		t.Errorf("期望3次tick，但实际有%d次tick", tickCount)
-	}
+func (r *recordingNotifier) Notify(title, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	return nil
+}
 
-	// 检查剩余时间
-	if m.timeModel.TimerRemaining != 0 {
-		t.Errorf("期望剩余时间为0，但实际是%d", m.timeModel.TimerRemaining)
-	}
+// TestSessionEndNotifies 测试会话结束时通过配置的后端发送通知
+func TestSessionEndNotifies(t *testing.T) {
+	m, clock := newTickTestApp(1)
+	rec := &recordingNotifier{}
+	m.notifier = notice.NewDispatcher(rec)
+	m.settingModel.Settings.Notifications.WorkEnd = true
 
-	// 检查日志中的时间序列
-	lines := strings.Split(string(data), "\n")
-	tickLines := []string{}
-	for _, line := range lines {
-		if strings.Contains(line, "[Tick] Timer ticked") {
-			tickLines = append(tickLines, line)
-		}
-	}
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
+	m.notifier.Wait()
 
-	// 输出所有tick日志用于调试
-	for i, line := range tickLines {
-		t.Logf("Tick %d: %s", i+1, line)
+	if len(rec.messages) != 1 || rec.messages[0] != "工作时间结束，开始休息！" {
+		t.Errorf("通知内容: %q", rec.messages)
 	}
+}
 
-	// 验证时间序列：应该是 2, 1, 0 (从3开始，每次减1)
-	if len(tickLines) >= 3 {
-		if !strings.Contains(tickLines[0], "remaining: 2") {
-			t.Errorf("第一次tick应该显示remaining: 2，但显示: %s", tickLines[0])
-		}
-		if !strings.Contains(tickLines[1], "remaining: 1") {
-			t.Errorf("第二次tick应该显示remaining: 1，但显示: %s", tickLines[1])
-		}
-		if !strings.Contains(tickLines[2], "remaining: 0") {
-			t.Errorf("第三次tick应该显示remaining: 0，但显示: %s", tickLines[2])
-		}
+// TestWorkSessionCountsPomodoro 测试工作会话结束时当前任务的番茄数加一，休息结束时不变
+func TestWorkSessionCountsPomodoro(t *testing.T) {
+	m, clock := newTickTestApp(1)
+	m.taskManager.Tasks = []task.Task{{ID: "a", Name: "写报告", Estimate: 1}}
+	m.currentTaskID = "a"
+
+	clock.Advance(time.Second)
+	handleTick(m, currentTick(m))
+	if got := m.taskManager.Tasks[0].Pomodoros; got != 1 {
+		t.Fatalf("工作会话结束后番茄数为 %d，期望 1", got)
 	}
 
-	// 验证没有重复的时间值
-	seenRemaining := make(map[int]bool)
-	for _, line := range tickLines {
-		remaining := extractRemainingTime(line)
-		if remaining != -1 {
-			if seenRemaining[remaining] {
-				t.Errorf("发现重复的剩余时间: %d", remaining)
-			}
-			seenRemaining[remaining] = true
-		}
+	// 休息阶段结束不计入番茄数
+	clock.Advance(5 * time.Minute)
+	handleTick(m, currentTick(m))
+	if got := m.taskManager.Tasks[0].Pomodoros; got != 1 {
+		t.Errorf("休息结束后番茄数为 %d，期望 1", got)
 	}
 }
@@ -0,0 +1,173 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/keymap"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TrashModel 列出回收站中的任务，可以恢复或永久删除
+type TrashModel struct {
+	tasks  []task.TrashedTask // 按删除时间倒序
+	days   uint               // 保留天数，0 表示不自动清除
+	cursor int
+	status string
+	keys   *keymap.TrashViewKeyMap
+
+	// 清空回收站前先确认，避免误按一个键就永久删除所有任务
+	confirming bool
+	confirm    *keymap.ConfirmKeyMap
+}
+
+// trashMsg 请求 App 恢复或永久删除回收站中的任务，id 为空表示清空回收站
+type trashMsg struct {
+	id      string
+	restore bool
+}
+
+func NewTrashModel(keys *keymap.TrashViewKeyMap, confirm *keymap.ConfirmKeyMap) TrashModel {
+	return TrashModel{keys: keys, confirm: confirm}
+}
+
+// Reload 重新读取回收站，并保持光标在有效范围内
+func (m *TrashModel) Reload(manager *task.Manager, days uint) {
+	m.tasks = manager.Trashed()
+	m.days = days
+	if m.cursor >= len(m.tasks) {
+		m.cursor = len(m.tasks) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m TrashModel) Update(msg tea.Msg) (TrashModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.confirming {
+		switch {
+		case key.Matches(keyMsg, m.confirm.Yes):
+			m.confirming = false
+			return m, func() tea.Msg { return trashMsg{} }
+		case key.Matches(keyMsg, m.confirm.No):
+			m.confirming = false
+			m.status = i18n.T("trash.purgeAllCanceled")
+		}
+		return m, nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		m.status = ""
+		return m, func() tea.Msg { return backMsg{} }
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.cursor < len(m.tasks)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, m.keys.Restore):
+		if len(m.tasks) > 0 {
+			id := m.tasks[m.cursor].ID
+			return m, func() tea.Msg { return trashMsg{id: id, restore: true} }
+		}
+	case key.Matches(keyMsg, m.keys.Purge):
+		if len(m.tasks) > 0 {
+			id := m.tasks[m.cursor].ID
+			return m, func() tea.Msg { return trashMsg{id: id} }
+		}
+	case key.Matches(keyMsg, m.keys.PurgeAll):
+		if len(m.tasks) > 0 {
+			m.confirming = true
+			m.status = ""
+		}
+	}
+	return m, nil
+}
+
+func (m TrashModel) View() string {
+	var b strings.Builder
+	b.WriteString(common.TitleStyle.Render(i18n.T("trash.title")))
+	b.WriteString("\n\n")
+	if m.days > 0 {
+		b.WriteString(helpStyle.Render(i18n.T("trash.keep", m.days)) + "\n\n")
+	} else {
+		b.WriteString(helpStyle.Render(i18n.T("trash.keepForever")) + "\n\n")
+	}
+
+	if len(m.tasks) == 0 {
+		b.WriteString(helpStyle.Render(i18n.T("trash.empty")) + "\n")
+	}
+	for i, t := range m.tasks {
+		line := fmt.Sprintf("%s  %s", t.Title(),
+			helpStyle.Render(i18n.T("trash.deletedAt", t.DeletedAt.Format("2006-01-02 15:04"))))
+		if i == m.cursor {
+			b.WriteString(focusedStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.confirming {
+		b.WriteString("\n" + statusMessageStyle(i18n.T("trash.confirmPurgeAll", len(m.tasks),
+			keymap.HelpKey("confirm.yes"), keymap.HelpKey("confirm.no"))))
+		return b.String()
+	}
+	if m.status != "" {
+		b.WriteString("\n" + statusMessageStyle(m.status) + "\n")
+	}
+	b.WriteString("\n" + helpStyle.Render(i18n.T("trash.help", keymap.HelpKey("trash.restore"),
+		keymap.HelpKey("trash.purge"), keymap.HelpKey("trash.purgeAll"), keymap.HelpKey("trash.back"))))
+	return b.String()
+}
+
+// handleTrash 恢复或永久删除回收站中的任务，然后刷新回收站和任务列表
+func handleTrash(m *App, msg trashMsg) (tea.Model, tea.Cmd) {
+	var err error
+	var title string
+	if i := m.taskManager.TrashIndex(msg.id); i >= 0 {
+		title = m.taskManager.Trash[i].Title()
+	}
+	switch {
+	case msg.id == "":
+		n := len(m.taskManager.Trash)
+		if err = m.taskManager.EmptyTrash(); err == nil {
+			m.trashModel.status = i18n.T("trash.purgedAll", n)
+		}
+	case msg.restore:
+		if err = m.taskManager.Restore(msg.id); err == nil {
+			m.trashModel.status = i18n.T("trash.restored", title)
+		}
+	default:
+		if err = m.taskManager.Purge(msg.id); err == nil {
+			m.trashModel.status = i18n.T("trash.purged", title)
+		}
+	}
+	if err != nil {
+		logging.Log(fmt.Sprintf("[Task] 更新回收站失败: %v", err))
+	}
+	m.trashModel.Reload(m.taskManager, m.settingModel.Settings.TrashDays)
+	m.refreshList()
+	return m, nil
+}
+
+// purgeExpiredTrash 永久删除超过保留天数的任务
+func (m *App) purgeExpiredTrash() {
+	days := m.settingModel.Settings.TrashDays
+	if days == 0 {
+		return
+	}
+	if _, err := m.taskManager.PurgeTrash(m.now().AddDate(0, 0, -int(days))); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 清除回收站中过期的任务失败: %v", err))
+	}
+}
@@ -0,0 +1,130 @@
+package gomato
+
+import (
+	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
+	"gomato/pkg/task"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUndo 是最多保留的可撤销操作数
+const maxUndo = 100
+
+// change 是任务列表中一次可以撤销的操作。撤销和重做只改动操作涉及的字段，
+// 期间的计时和番茄数不会丢失。
+type change struct {
+	label string // 状态栏中显示的操作，如“删除任务 写报告”
+	undo  func(m *App) error
+	redo  func(m *App) error
+}
+
+// undoStack 保存可以撤销和重做的操作，新的操作会清空重做栈
+type undoStack struct {
+	done   []change
+	undone []change
+}
+
+func (s *undoStack) push(c change) {
+	s.done = append(s.done, c)
+	if len(s.done) > maxUndo {
+		s.done = s.done[len(s.done)-maxUndo:]
+	}
+	s.undone = nil
+}
+
+// addChange 撤销添加时把任务移到回收站，重做时再恢复
+func addChange(t task.Task) change {
+	return change{
+		label: i18n.T("undo.add", t.Title()),
+		undo:  func(m *App) error { return m.taskManager.Delete(t.ID) },
+		redo:  func(m *App) error { return m.taskManager.Restore(t.ID) },
+	}
+}
+
+func editChange(id, title string, before, after taskFields) change {
+	apply := func(f taskFields) func(m *App) error {
+		return func(m *App) error {
+			t, ok := m.taskManager.Get(id)
+			if !ok {
+				return fmt.Errorf("task %s not found", id)
+			}
+			f.apply(&t)
+			return m.taskManager.Update(t)
+		}
+	}
+	return change{label: i18n.T("undo.edit", title), undo: apply(before), redo: apply(after)}
+}
+
+func deleteChange(t task.Task) change {
+	return change{
+		label: i18n.T("undo.delete", t.Title()),
+		undo:  func(m *App) error { return m.taskManager.Restore(t.ID) },
+		redo:  func(m *App) error { return m.taskManager.Delete(t.ID) },
+	}
+}
+
+// doneChange 记录完成或取消完成，撤销取消完成时沿用原来的完成时间
+func doneChange(t task.Task, done bool) change {
+	setDone := func(done bool, at time.Time) func(m *App) error {
+		return func(m *App) error {
+			if at.IsZero() {
+				at = m.now()
+			}
+			if err := m.taskManager.SetDone(t.ID, done, at); err != nil {
+				return err
+			}
+			if done {
+				m.prepareNextOccurrence(t.ID)
+			}
+			return nil
+		}
+	}
+	if done {
+		return change{label: i18n.T("undo.done", t.Title()), undo: setDone(false, time.Time{}), redo: setDone(true, time.Time{})}
+	}
+	return change{label: i18n.T("undo.undone", t.Title()), undo: setDone(true, t.CompletedAt), redo: setDone(false, time.Time{})}
+}
+
+// moveChange 记录任务在 tasks.json 中的位置从 from 移到 to
+func moveChange(t task.Task, from, to int) change {
+	return change{
+		label: i18n.T("undo.move", t.Title()),
+		undo:  func(m *App) error { return m.taskManager.Move(t.ID, from) },
+		redo:  func(m *App) error { return m.taskManager.Move(t.ID, to) },
+	}
+}
+
+// undoLast 撤销最近一次操作。操作涉及的任务已被永久删除时无法撤销，该操作被丢弃。
+func (m *App) undoLast() tea.Cmd {
+	n := len(m.undo.done)
+	if n == 0 {
+		return m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.nothingToUndo")))
+	}
+	c := m.undo.done[n-1]
+	m.undo.done = m.undo.done[:n-1]
+	if err := c.undo(m); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 撤销失败: %v", err))
+		return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.undoFailed", c.label))))
+	}
+	m.undo.undone = append(m.undo.undone, c)
+	return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.undone", c.label))))
+}
+
+// redoLast 重做最近一次撤销的操作
+func (m *App) redoLast() tea.Cmd {
+	n := len(m.undo.undone)
+	if n == 0 {
+		return m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.nothingToRedo")))
+	}
+	c := m.undo.undone[n-1]
+	m.undo.undone = m.undo.undone[:n-1]
+	if err := c.redo(m); err != nil {
+		logging.Log(fmt.Sprintf("[Task] 重做失败: %v", err))
+		return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.undoFailed", c.label))))
+	}
+	m.undo.done = append(m.undo.done, c)
+	return tea.Batch(m.refreshList(), m.list.NewStatusMessage(statusMessageStyle(i18n.T("app.redone", c.label))))
+}
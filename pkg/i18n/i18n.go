@@ -0,0 +1,138 @@
+// Package i18n 提供按语言区分的界面文字。
+//
+// 每种语言是 locales 目录下的一个 JSON 文件，文件名即语言代码，内容为
+// 键到文字的映射。增加语言只需添加一个文件。某种语言缺少的键回退到
+// 默认语言，默认语言也没有时返回键本身。
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Default 是默认语言，也是缺少翻译时的回退语言
+const Default = "zh"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	catalogs = loadCatalogs()
+
+	mu      sync.RWMutex
+	current = Default
+)
+
+// loadCatalogs 读取内嵌的全部语言文件
+func loadCatalogs() map[string]map[string]string {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", e.Name(), err))
+		}
+		result[strings.TrimSuffix(e.Name(), ".json")] = messages
+	}
+	return result
+}
+
+// Languages returns the codes of the available languages, the default
+// language first and the rest sorted.
+func Languages() []string {
+	langs := []string{Default}
+	for lang := range catalogs {
+		if lang != Default {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// Name returns the display name of a language in that language.
+func Name(lang string) string {
+	return Tr(lang, "language.name")
+}
+
+// SetLanguage sets the language used by T. Unknown languages fall back to
+// the closest available one.
+func SetLanguage(lang string) {
+	mu.Lock()
+	current = Match(lang)
+	mu.Unlock()
+}
+
+// Language returns the language used by T.
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T returns the message for key in the current language. Arguments are
+// formatted into the message with fmt.Sprintf.
+func T(key string, args ...any) string {
+	return Tr(Language(), key, args...)
+}
+
+// Tr returns the message for key in the given language.
+func Tr(lang, key string, args ...any) string {
+	msg, ok := catalogs[Match(lang)][key]
+	if !ok {
+		if msg, ok = catalogs[Default][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Match returns the available language closest to lang, e.g. "en" for
+// "en-GB", or the default language.
+func Match(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return Default
+}
+
+// Detect returns the language selected by the LC_ALL, LC_MESSAGES or LANG
+// environment variables, in that order of precedence.
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		// 去掉编码和修饰部分，例如 en_US.UTF-8@euro
+		v, _, _ = strings.Cut(v, ".")
+		v, _, _ = strings.Cut(v, "@")
+		if v == "C" || v == "POSIX" {
+			return Default
+		}
+		return Match(v)
+	}
+	return Default
+}
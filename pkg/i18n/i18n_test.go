@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCatalogsComplete 检查每种语言都翻译了默认语言中的全部文字，
+// 且格式化参数的数量一致
+func TestCatalogsComplete(t *testing.T) {
+	for lang, messages := range catalogs {
+		for key, want := range catalogs[Default] {
+			got, ok := messages[key]
+			if !ok {
+				t.Errorf("%s: 缺少 %s", lang, key)
+				continue
+			}
+			if strings.Count(got, "%") != strings.Count(want, "%") {
+				t.Errorf("%s: %s 的格式化参数与默认语言不一致: %q", lang, key, got)
+			}
+		}
+		for key := range messages {
+			if _, ok := catalogs[Default][key]; !ok {
+				t.Errorf("%s: 默认语言中没有 %s", lang, key)
+			}
+		}
+	}
+}
+
+func TestTrFallback(t *testing.T) {
+	if got := Tr("en", "timer.status", "running"); got != "Status: running" {
+		t.Errorf("Tr(en) = %q", got)
+	}
+	if got := Tr("en-GB", "language.name"); got != "English" {
+		t.Errorf("Tr(en-GB) = %q", got)
+	}
+	if got, want := Tr("xx", "timer.title"), catalogs[Default]["timer.title"]; got != want {
+		t.Errorf("Tr(xx) = %q, want %q", got, want)
+	}
+	if got := Tr("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q", got)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	langs := Languages()
+	if len(langs) < 2 || langs[0] != Default {
+		t.Fatalf("Languages() = %v", langs)
+	}
+	SetLanguage("en_US")
+	defer SetLanguage(Default)
+	if Language() != "en" || T("keys.back") != "back" {
+		t.Errorf("SetLanguage(en_US): %s, %q", Language(), T("keys.back"))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        string
+	}{
+		{"", "en_US.UTF-8", "en"},
+		{"zh_CN.UTF-8", "en_US.UTF-8", "zh"},
+		{"", "de_DE@euro", Default},
+		{"C", "en_US.UTF-8", Default},
+		{"", "", Default},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(); got != tt.want {
+			t.Errorf("Detect(LC_ALL=%q, LANG=%q) = %q, want %q", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
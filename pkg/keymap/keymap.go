@@ -1,6 +1,13 @@
 package keymap
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"gomato/pkg/i18n"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// 按键的帮助文字使用创建时的界面语言，切换语言后需重新创建。
+// 按键可以在按键文件中覆盖，见 Load
 
 // 任务列表视图的按键映射
 // ListKeyMap 用于任务列表视图
@@ -12,39 +19,44 @@ type ListKeyMap struct {
 	TogglePagination key.Binding
 	ToggleHelpMenu   key.Binding
 	InsertItem       key.Binding
+	EditItem         key.Binding
 	ChooseTask       key.Binding
+	Stats            key.Binding
+	ToggleDone       key.Binding
+	Archive          key.Binding
+	Detail           key.Binding
+	Sort             key.Binding
+	DateFilter       key.Binding
+	MoveUp           key.Binding
+	MoveDown         key.Binding
+	Undo             key.Binding
+	Redo             key.Binding
+	Trash            key.Binding
+	Lists            key.Binding
 }
 
 func NewListKeyMap() *ListKeyMap {
 	return &ListKeyMap{
-		InsertItem: key.NewBinding(
-			key.WithKeys("a"),
-			key.WithHelp("a", "add item"),
-		),
-		Setting: key.NewBinding(
-			key.WithKeys("s"),
-			key.WithHelp("s", "setting"),
-		),
-		ToggleTitleBar: key.NewBinding(
-			key.WithKeys("T"),
-			key.WithHelp("T", "toggle title"),
-		),
-		ToggleStatusBar: key.NewBinding(
-			key.WithKeys("S"),
-			key.WithHelp("S", "toggle status"),
-		),
-		TogglePagination: key.NewBinding(
-			key.WithKeys("P"),
-			key.WithHelp("P", "toggle pagination"),
-		),
-		ChooseTask: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "choose a task"),
-		),
-		ToggleHelpMenu: key.NewBinding(
-			key.WithKeys("H"),
-			key.WithHelp("H", "toggle help"),
-		),
+		InsertItem:       bind("list.add", i18n.T("keys.addItem")),
+		EditItem:         bind("list.edit", i18n.T("keys.editItem")),
+		Setting:          bind("list.setting", i18n.T("keys.setting")),
+		ToggleTitleBar:   bind("list.toggleTitle", i18n.T("keys.toggleTitle")),
+		ToggleStatusBar:  bind("list.toggleStatus", i18n.T("keys.toggleStatus")),
+		TogglePagination: bind("list.togglePagination", i18n.T("keys.togglePagination")),
+		ChooseTask:       bind("list.choose", i18n.T("keys.chooseTask")),
+		ToggleHelpMenu:   bind("list.toggleHelp", i18n.T("keys.toggleHelp")),
+		Stats:            bind("list.stats", i18n.T("keys.stats")),
+		ToggleDone:       bind("list.toggleDone", i18n.T("keys.toggleDone")),
+		Archive:          bind("list.archive", i18n.T("keys.archive")),
+		Detail:           bind("list.detail", i18n.T("keys.detail")),
+		Sort:             bind("list.sort", i18n.T("keys.sort")),
+		DateFilter:       bind("list.dateFilter", i18n.T("keys.dateFilter")),
+		MoveUp:           bind("list.moveUp", i18n.T("keys.moveTaskUp")),
+		MoveDown:         bind("list.moveDown", i18n.T("keys.moveTaskDown")),
+		Undo:             bind("list.undo", i18n.T("keys.undo")),
+		Redo:             bind("list.redo", i18n.T("keys.redo")),
+		Trash:            bind("list.trash", i18n.T("keys.trash")),
+		Lists:            bind("list.lists", i18n.T("keys.lists")),
 	}
 }
 
@@ -57,14 +69,8 @@ type DelegateKeyMap struct {
 
 func NewDelegateKeyMap() *DelegateKeyMap {
 	return &DelegateKeyMap{
-		Choose: key.NewBinding(
-			key.WithKeys("enter"),           // 使用回车键选择
-			key.WithHelp("enter", "choose"), // 帮助文本
-		),
-		Remove: key.NewBinding(
-			key.WithKeys("x", "backspace"), // 使用x或退格键删除
-			key.WithHelp("x", "delete"),    // 帮助文本
-		),
+		Choose: bind("list.choose", i18n.T("keys.choose")),
+		Remove: bind("list.remove", i18n.T("keys.delete")),
 	}
 }
 
@@ -92,21 +98,144 @@ type TimeViewKeyMap struct {
 	Back       key.Binding
 	StartPause key.Binding
 	Reset      key.Binding
+	Skip       key.Binding
 }
 
 func NewTimeViewKeyMap() *TimeViewKeyMap {
 	return &TimeViewKeyMap{
-		Back: key.NewBinding(
-			key.WithKeys("q", "esc"),
-			key.WithHelp("q/esc", "返回任务列表"),
-		),
-		StartPause: key.NewBinding(
-			key.WithKeys(" "),
-			key.WithHelp("space", "开始/暂停"),
-		),
-		Reset: key.NewBinding(
-			key.WithKeys("r"),
-			key.WithHelp("r", "重置计时"),
-		),
+		Back:       bind("timer.back", i18n.T("keys.backToList")),
+		StartPause: bind("timer.startPause", i18n.T("keys.startPause")),
+		Reset:      bind("timer.reset", i18n.T("keys.reset")),
+		Skip:       bind("timer.skip", i18n.T("keys.skip")),
+	}
+}
+
+// 统计视图的按键映射
+// StatsViewKeyMap 用于统计视图
+type StatsViewKeyMap struct {
+	Back       key.Binding
+	PrevPeriod key.Binding
+	NextPeriod key.Binding
+	Group      key.Binding
+	Scope      key.Binding
+}
+
+func NewStatsViewKeyMap() *StatsViewKeyMap {
+	return &StatsViewKeyMap{
+		Back:       bind("stats.back", i18n.T("keys.back")),
+		PrevPeriod: bind("stats.prev", i18n.T("keys.prevPeriod")),
+		NextPeriod: bind("stats.next", i18n.T("keys.nextPeriod")),
+		Group:      bind("stats.group", i18n.T("keys.group")),
+		Scope:      bind("stats.scope", i18n.T("keys.statsScope")),
+	}
+}
+
+// 归档视图的按键映射
+// ArchiveViewKeyMap 用于已完成任务的归档视图
+type ArchiveViewKeyMap struct {
+	Back     key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Restore  key.Binding
+	Purge    key.Binding
+	PurgeAll key.Binding
+}
+
+func NewArchiveViewKeyMap() *ArchiveViewKeyMap {
+	return &ArchiveViewKeyMap{
+		Back:     bind("archive.back", i18n.T("keys.back")),
+		Up:       bind("archive.up", i18n.T("keys.up")),
+		Down:     bind("archive.down", i18n.T("keys.down")),
+		Restore:  bind("archive.restore", i18n.T("keys.restore")),
+		Purge:    bind("archive.purge", i18n.T("keys.delete")),
+		PurgeAll: bind("archive.purgeAll", i18n.T("keys.purgeAll")),
+	}
+}
+
+// 回收站视图的按键映射
+// TrashViewKeyMap 用于回收站视图
+type TrashViewKeyMap struct {
+	Back     key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Restore  key.Binding
+	Purge    key.Binding
+	PurgeAll key.Binding
+}
+
+func NewTrashViewKeyMap() *TrashViewKeyMap {
+	return &TrashViewKeyMap{
+		Back:     bind("trash.back", i18n.T("keys.back")),
+		Up:       bind("trash.up", i18n.T("keys.up")),
+		Down:     bind("trash.down", i18n.T("keys.down")),
+		Restore:  bind("trash.restore", i18n.T("keys.restore")),
+		Purge:    bind("trash.purge", i18n.T("keys.purge")),
+		PurgeAll: bind("trash.purgeAll", i18n.T("keys.emptyTrash")),
+	}
+}
+
+// 任务列表切换视图的按键映射
+// ListsViewKeyMap 用于选择或新建任务列表
+type ListsViewKeyMap struct {
+	Back   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	New    key.Binding
+}
+
+func NewListsViewKeyMap() *ListsViewKeyMap {
+	return &ListsViewKeyMap{
+		Back:   bind("lists.back", i18n.T("keys.back")),
+		Up:     bind("lists.up", i18n.T("keys.up")),
+		Down:   bind("lists.down", i18n.T("keys.down")),
+		Choose: bind("lists.choose", i18n.T("keys.switchList")),
+		New:    bind("lists.new", i18n.T("keys.newList")),
+	}
+}
+
+// 任务详情视图的按键映射
+// DetailViewKeyMap 用于任务详情中的清单，Pick、Done 和 Skip 用于番茄结束后的清单提示
+type DetailViewKeyMap struct {
+	Back     key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Toggle   key.Binding
+	Add      key.Binding
+	Remove   key.Binding
+	MoveUp   key.Binding
+	MoveDown key.Binding
+	Pick     key.Binding
+	Done     key.Binding
+	Skip     key.Binding
+}
+
+func NewDetailViewKeyMap() *DetailViewKeyMap {
+	return &DetailViewKeyMap{
+		Back:     bind("detail.back", i18n.T("keys.back")),
+		Up:       bind("detail.up", i18n.T("keys.up")),
+		Down:     bind("detail.down", i18n.T("keys.down")),
+		Toggle:   bind("detail.toggle", i18n.T("keys.toggleDone")),
+		Add:      bind("detail.add", i18n.T("keys.addSubtask")),
+		Remove:   bind("detail.remove", i18n.T("keys.delete")),
+		MoveUp:   bind("detail.moveUp", i18n.T("keys.moveUp")),
+		MoveDown: bind("detail.moveDown", i18n.T("keys.moveDown")),
+		Pick:     bind("prompt.pick", i18n.T("keys.pick")),
+		Done:     bind("prompt.done", i18n.T("keys.pickDone")),
+		Skip:     bind("prompt.skip", i18n.T("keys.skipPrompt")),
+	}
+}
+
+// 确认提示的按键映射
+// ConfirmKeyMap 用于恢复会话等是/否提示
+type ConfirmKeyMap struct {
+	Yes key.Binding
+	No  key.Binding
+}
+
+func NewConfirmKeyMap() *ConfirmKeyMap {
+	return &ConfirmKeyMap{
+		Yes: bind("confirm.yes", i18n.T("keys.yes")),
+		No:  bind("confirm.no", i18n.T("keys.no")),
 	}
 }
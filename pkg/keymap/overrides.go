@@ -0,0 +1,278 @@
+package keymap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// defaultKeys 是每个动作的默认按键，动作名可在按键文件中使用
+var defaultKeys = map[string][]string{
+	"list.add":              {"a"},
+	"list.edit":             {"e"},
+	"list.setting":          {"s"},
+	"list.toggleTitle":      {"T"},
+	"list.toggleStatus":     {"S"},
+	"list.togglePagination": {"P"},
+	"list.toggleHelp":       {"H"},
+	"list.choose":           {"enter"},
+	"list.stats":            {"t"},
+	"list.remove":           {"x", "backspace"},
+	"list.toggleDone":       {"c"},
+	"list.archive":          {"A"},
+	"list.detail":           {"v"},
+	"list.sort":             {"o"},
+	"list.dateFilter":       {"D"},
+	"list.moveUp":           {"K", "shift+up"},
+	"list.moveDown":         {"J", "shift+down"},
+	"list.undo":             {"u"},
+	"list.redo":             {"ctrl+r"},
+	"list.trash":            {"B"},
+	"list.lists":            {"L"},
+
+	"timer.back":       {"q", "esc"},
+	"timer.startPause": {" "},
+	"timer.reset":      {"r"},
+	"timer.skip":       {"n"},
+
+	"stats.back":  {"q", "esc"},
+	"stats.prev":  {"left", "h"},
+	"stats.next":  {"right", "l", "tab"},
+	"stats.group": {"g"},
+	"stats.scope": {"a"},
+
+	"archive.back":     {"q", "esc"},
+	"archive.up":       {"up", "k"},
+	"archive.down":     {"down", "j"},
+	"archive.restore":  {"r", "enter"},
+	"archive.purge":    {"x", "backspace"},
+	"archive.purgeAll": {"X"},
+
+	"trash.back":     {"q", "esc"},
+	"trash.up":       {"up", "k"},
+	"trash.down":     {"down", "j"},
+	"trash.restore":  {"r", "enter"},
+	"trash.purge":    {"x", "backspace"},
+	"trash.purgeAll": {"X"},
+
+	"lists.back":   {"q", "esc"},
+	"lists.up":     {"up", "k"},
+	"lists.down":   {"down", "j"},
+	"lists.choose": {"enter"},
+	"lists.new":    {"n"},
+
+	"detail.back":     {"q", "esc"},
+	"detail.up":       {"up", "k"},
+	"detail.down":     {"down", "j"},
+	"detail.toggle":   {" ", "x"},
+	"detail.add":      {"a"},
+	"detail.remove":   {"d", "backspace"},
+	"detail.moveUp":   {"K", "shift+up"},
+	"detail.moveDown": {"J", "shift+down"},
+
+	"prompt.pick": {"enter"},
+	"prompt.done": {" "},
+	"prompt.skip": {"esc"},
+
+	"confirm.yes": {"y", "enter"},
+	"confirm.no":  {"n", "esc"},
+}
+
+// views 列出同一视图中同时生效的动作，它们的按键不能重复
+var views = map[string][]string{
+	"list": {
+		"list.add", "list.edit", "list.setting", "list.toggleTitle", "list.toggleStatus",
+		"list.togglePagination", "list.toggleHelp", "list.choose", "list.stats", "list.remove",
+		"list.toggleDone", "list.archive", "list.detail", "list.sort", "list.dateFilter",
+		"list.moveUp", "list.moveDown", "list.undo", "list.redo", "list.trash", "list.lists",
+	},
+	"timer": {"timer.back", "timer.startPause", "timer.reset", "timer.skip"},
+	"stats": {"stats.back", "stats.prev", "stats.next", "stats.group", "stats.scope"},
+	"archive": {
+		"archive.back", "archive.up", "archive.down", "archive.restore", "archive.purge", "archive.purgeAll",
+	},
+	"trash": {
+		"trash.back", "trash.up", "trash.down", "trash.restore", "trash.purge", "trash.purgeAll",
+	},
+	"lists": {"lists.back", "lists.up", "lists.down", "lists.choose", "lists.new"},
+	"detail": {
+		"detail.back", "detail.up", "detail.down", "detail.toggle", "detail.add", "detail.remove",
+		"detail.moveUp", "detail.moveDown",
+	},
+	"prompt":  {"detail.up", "detail.down", "prompt.pick", "prompt.done", "prompt.skip"},
+	"confirm": {"confirm.yes", "confirm.no"},
+}
+
+// overrides 是按键文件中覆盖的按键，由 Load 设置
+var overrides map[string][]string
+
+// keyNames 是按键文件中可读的按键名称与 bubbletea 按键字符串的对应
+var keyNames = map[string]string{"space": " "}
+
+// helpNames 是帮助文字中按键的显示方式
+var helpNames = map[string]string{" ": "space", "left": "←", "right": "→", "up": "↑", "down": "↓"}
+
+// keyList 接受单个按键字符串或按键数组
+type keyList []string
+
+func (k *keyList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*k = keyList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New(i18n.T("keymap.badKeys", data))
+	}
+	*k = many
+	return nil
+}
+
+// DefaultPath returns the path of the keymap file, keymap.json in the
+// config directory.
+func DefaultPath() (string, error) {
+	return paths.ConfigFile("keymap.json")
+}
+
+// Load reads key overrides from the keymap file at path and uses them for
+// key maps created afterwards. A missing file means no overrides. The file
+// maps action names such as "list.add" to one key or a list of keys; it is
+// rejected if it names an unknown action or binds a key twice in one view.
+func Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		overrides = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file map[string]keyList
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	o := make(map[string][]string, len(file))
+	for action, keys := range file {
+		o[action] = normalize(keys)
+	}
+	if err := validate(o); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	overrides = o
+	return nil
+}
+
+// normalize 把按键名称转换为 bubbletea 的按键字符串
+func normalize(keys []string) []string {
+	result := make([]string, len(keys))
+	for i, k := range keys {
+		if name, ok := keyNames[strings.ToLower(k)]; ok {
+			k = name
+		}
+		result[i] = k
+	}
+	return result
+}
+
+// validate 检查覆盖的动作是否存在，以及同一视图中是否有按键冲突
+func validate(o map[string][]string) error {
+	var errs []string
+	for action, keys := range o {
+		if _, ok := defaultKeys[action]; !ok {
+			errs = append(errs, i18n.T("keymap.unknownAction", action))
+		} else if len(keys) == 0 {
+			errs = append(errs, i18n.T("keymap.noKeys", action))
+		}
+	}
+	for view, actions := range views {
+		owner := map[string]string{}
+		if view == "list" {
+			// 列表自带的导航按键也不能被占用
+			for name, b := range builtinListKeys() {
+				for _, k := range b {
+					owner[k] = i18n.T("keymap.builtin", name)
+				}
+			}
+		}
+		for _, action := range actions {
+			for _, k := range keysFor(action, o) {
+				if other, ok := owner[k]; ok && other != action {
+					errs = append(errs, i18n.T("keymap.conflict", helpName(k), other, action))
+					continue
+				}
+				owner[k] = action
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return errors.New(i18n.T("keymap.invalid") + "\n  " + strings.Join(errs, "\n  "))
+}
+
+// ListModelKeyMap returns the key map of the bubbles list in the task list
+// view. It is the default one without "u" for the previous page, which
+// undoes the last change instead.
+func ListModelKeyMap() list.KeyMap {
+	k := list.DefaultKeyMap()
+	k.PrevPage = key.NewBinding(key.WithKeys("left", "h", "pgup", "b"), key.WithHelp("←/h/pgup", "prev page"))
+	return k
+}
+
+// builtinListKeys 返回 bubbles 列表在非筛选状态下使用的按键
+func builtinListKeys() map[string][]string {
+	k := ListModelKeyMap()
+	return map[string][]string{
+		"cursor up":   k.CursorUp.Keys(),
+		"cursor down": k.CursorDown.Keys(),
+		"prev page":   k.PrevPage.Keys(),
+		"next page":   k.NextPage.Keys(),
+		"go to start": k.GoToStart.Keys(),
+		"go to end":   k.GoToEnd.Keys(),
+		"filter":      k.Filter.Keys(),
+		"help":        k.ShowFullHelp.Keys(),
+		"quit":        k.Quit.Keys(),
+		"force quit":  k.ForceQuit.Keys(),
+	}
+}
+
+// keysFor 返回动作的按键，优先使用覆盖的按键
+func keysFor(action string, o map[string][]string) []string {
+	if keys, ok := o[action]; ok {
+		return keys
+	}
+	return defaultKeys[action]
+}
+
+// bind 创建动作的按键绑定，帮助文字中显示实际生效的按键
+func bind(action, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(keysFor(action, overrides)...), key.WithHelp(HelpKey(action), desc))
+}
+
+// HelpKey returns the keys bound to action as shown in help text, e.g.
+// "q/esc".
+func HelpKey(action string) string {
+	keys := keysFor(action, overrides)
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = helpName(k)
+	}
+	return strings.Join(names, "/")
+}
+
+func helpName(k string) string {
+	if name, ok := helpNames[k]; ok {
+		return name
+	}
+	return k
+}
@@ -0,0 +1,89 @@
+package keymap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeymap(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keymap.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultKeysValid(t *testing.T) {
+	if err := validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, actions := range views {
+		for _, action := range actions {
+			if _, ok := defaultKeys[action]; !ok {
+				t.Errorf("%s 没有默认按键", action)
+			}
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	t.Cleanup(func() { overrides = nil })
+	path := writeKeymap(t, `{"list.add": ["+", "i"], "timer.startPause": "p", "timer.skip": ["space"]}`)
+	if err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	k := NewListKeyMap()
+	if got := k.InsertItem.Keys(); strings.Join(got, ",") != "+,i" {
+		t.Errorf("list.add keys = %q", got)
+	}
+	if got := k.InsertItem.Help().Key; got != "+/i" {
+		t.Errorf("list.add help = %q", got)
+	}
+	if got := k.Setting.Keys(); len(got) != 1 || got[0] != "s" {
+		t.Errorf("未覆盖的按键应保持默认值: %q", got)
+	}
+	tk := NewTimeViewKeyMap()
+	if tk.StartPause.Keys()[0] != "p" || tk.Skip.Keys()[0] != " " || tk.Skip.Help().Key != "space" {
+		t.Errorf("timer keys = %q, %q", tk.StartPause.Keys(), tk.Skip.Keys())
+	}
+
+	// 文件不存在时恢复默认按键
+	if err := Load(filepath.Join(t.TempDir(), "none.json")); err != nil {
+		t.Fatal(err)
+	}
+	if NewListKeyMap().InsertItem.Keys()[0] != "a" {
+		t.Error("expected default keys without a keymap file")
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	t.Cleanup(func() { overrides = nil })
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`{"list.add": "s"}`, `按键 "s" 同时绑定到 list.add 和 list.setting`},
+		{`{"timer.reset": "space"}`, `按键 "space" 同时绑定到 timer.startPause 和 timer.reset`},
+		{`{"list.stats": "j"}`, "列表内置的 cursor down"},
+		{`{"list.bogus": "z"}`, "未知的动作: list.bogus"},
+		{`{"list.add": []}`, "list.add 至少需要一个按键"},
+		{`{"list.add": 1}`, "按键应为字符串或字符串数组"},
+	}
+	for _, tt := range tests {
+		err := Load(writeKeymap(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Load(%s) error = %v, want %q", tt.content, err, tt.want)
+		}
+		if overrides != nil {
+			t.Errorf("Load(%s) 失败时不应应用覆盖", tt.content)
+		}
+	}
+	// 不同视图中可以使用相同的按键
+	if err := Load(writeKeymap(t, `{"timer.reset": "x"}`)); err != nil {
+		t.Errorf("keys may repeat across views: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"fmt"
+	"gomato/pkg/paths"
 	"log"
 	"os"
 	"path/filepath"
@@ -15,12 +16,10 @@ var (
 
 // Init sets up the logger to write to a file.
 func Init() error {
-	homeDir, err := os.UserHomeDir()
+	logDir, err := paths.StateDir()
 	if err != nil {
-		return fmt.Errorf("failed to get user home directory: %w", err)
+		return fmt.Errorf("failed to get state directory: %w", err)
 	}
-
-	logDir := filepath.Join(homeDir, ".gomato")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return fmt.Errorf("failed to create log directory: %w", err)
 	}
@@ -0,0 +1,148 @@
+package notice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gomato/pkg/common"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandTimeout 是通知命令允许运行的最长时间
+const commandTimeout = 10 * time.Second
+
+// Bell 向终端输出响铃字符
+type Bell struct {
+	W io.Writer
+}
+
+func (b Bell) Notify(title, message string) error {
+	_, err := io.WriteString(b.W, "\a")
+	return err
+}
+
+// OSC9 通过 OSC 9 转义序列发送终端通知（iTerm2、Windows Terminal、WezTerm 等支持）
+type OSC9 struct {
+	W io.Writer
+}
+
+func (o OSC9) Notify(title, message string) error {
+	_, err := fmt.Fprintf(o.W, "\x1b]9;%s: %s\a", sanitize(title), sanitize(message))
+	return err
+}
+
+// OSC777 通过 OSC 777 转义序列发送终端通知（rxvt-unicode、foot、Ghostty 等支持）
+type OSC777 struct {
+	W io.Writer
+}
+
+func (o OSC777) Notify(title, message string) error {
+	// 标题中的分号会被当作字段分隔符
+	t := strings.ReplaceAll(sanitize(title), ";", ",")
+	_, err := fmt.Fprintf(o.W, "\x1b]777;notify;%s;%s\a", t, sanitize(message))
+	return err
+}
+
+// sanitize 去掉控制字符，避免通知内容提前结束转义序列
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Command 通过 shell 执行用户配置的命令，标题和内容通过环境变量
+// GOMATO_TITLE 和 GOMATO_MESSAGE 传入
+type Command struct {
+	Line string
+}
+
+func (c Command) Notify(title, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.Line)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Line)
+	}
+	cmd.Env = append(os.Environ(), "GOMATO_TITLE="+title, "GOMATO_MESSAGE="+message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// File 把通知以 JSON 行的形式追加写入文件
+type File struct {
+	Path string
+}
+
+func (f File) Notify(title, message string) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(struct {
+		Time    time.Time `json:"time"`
+		Title   string    `json:"title"`
+		Message string    `json:"message"`
+	}{time.Now(), title, message})
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// FromSettings builds a dispatcher for the backends enabled in s. Terminal
+// backends write to term.
+func FromSettings(s common.NotificationSettings, term io.Writer) *Dispatcher {
+	var notifiers []Notifier
+	if s.Desktop {
+		notifiers = append(notifiers, Desktop{})
+	}
+	if s.Sound {
+		notifiers = append(notifiers, Sound{File: expandHome(s.SoundFile)})
+	}
+	if s.Bell {
+		notifiers = append(notifiers, Bell{W: term})
+	}
+	switch s.OSC {
+	case "9":
+		notifiers = append(notifiers, OSC9{W: term})
+	case "777":
+		notifiers = append(notifiers, OSC777{W: term})
+	}
+	if s.Command != "" {
+		notifiers = append(notifiers, Command{Line: s.Command})
+	}
+	if s.File != "" {
+		notifiers = append(notifiers, File{Path: expandHome(s.File)})
+	}
+	return NewDispatcher(notifiers...)
+}
+
+// expandHome 把路径开头的 ~ 展开为用户主目录
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
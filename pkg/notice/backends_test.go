@@ -0,0 +1,94 @@
+package notice
+
+import (
+	"bytes"
+	"errors"
+	"gomato/pkg/common"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeNotifier 记录收到的通知
+type fakeNotifier struct {
+	mu   sync.Mutex
+	got  []string
+	fail bool
+}
+
+func (f *fakeNotifier) Notify(title, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.got = append(f.got, title+": "+message)
+	if f.fail {
+		return errors.New("fake failure")
+	}
+	return nil
+}
+
+func TestDispatcherSendsToAll(t *testing.T) {
+	a, b := &fakeNotifier{}, &fakeNotifier{fail: true}
+	d := NewDispatcher(a, b)
+	d.Send("番茄钟", "休息一下")
+	d.Wait()
+	for _, f := range []*fakeNotifier{a, b} {
+		if len(f.got) != 1 || f.got[0] != "番茄钟: 休息一下" {
+			t.Errorf("got %q", f.got)
+		}
+	}
+
+	// nil 的 Dispatcher 不发送任何通知
+	var none *Dispatcher
+	none.Send("a", "b")
+	none.Wait()
+}
+
+func TestTerminalBackends(t *testing.T) {
+	var buf bytes.Buffer
+	Bell{W: &buf}.Notify("t", "m")
+	OSC9{W: &buf}.Notify("番茄钟", "休息\a一下")
+	OSC777{W: &buf}.Notify("a;b", "m")
+	want := "\a" + "\x1b]9;番茄钟: 休息一下\a" + "\x1b]777;notify;a,b;m\a"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFileAndCommandBackends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "notices.jsonl")
+	if err := (File{Path: path}).Notify("番茄钟", "完成"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"message":"完成"`) {
+		t.Errorf("file content = %s, %v", data, err)
+	}
+
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no shell")
+	}
+	out := filepath.Join(dir, "cmd.txt")
+	if err := (Command{Line: `printf '%s|%s' "$GOMATO_TITLE" "$GOMATO_MESSAGE" > ` + out}).Notify("番茄钟", "完成"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "番茄钟|完成" {
+		t.Errorf("command output = %q", data)
+	}
+	if err := (Command{Line: "exit 3"}).Notify("t", "m"); err == nil {
+		t.Error("expected error from failing command")
+	}
+}
+
+func TestFromSettings(t *testing.T) {
+	d := FromSettings(common.NotificationSettings{Bell: true, OSC: "777", File: "/tmp/x"}, &bytes.Buffer{})
+	var names []string
+	for _, n := range d.notifiers {
+		names = append(names, name(n))
+	}
+	if got := strings.Join(names, ","); got != "notice.Bell,notice.OSC777,notice.File" {
+		t.Errorf("notifiers = %s", got)
+	}
+}
@@ -0,0 +1,41 @@
+package notice
+
+import (
+	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/task"
+	"gomato/pkg/timer"
+)
+
+// ForEvent returns the notification for a timer event in the current
+// language. ok is false if the event has no notification or it is turned
+// off in s.
+func ForEvent(s common.NotificationSettings, ev timer.Event) (title, message string, ok bool) {
+	switch ev.Type {
+	case timer.SessionCompleted:
+		switch ev.Next {
+		case timer.ShortBreak:
+			return i18n.T("notice.title"), i18n.T("notice.workEnd"), s.WorkEnd
+		case timer.LongBreak:
+			return i18n.T("notice.title"), i18n.T("notice.cycleEnd"), s.CycleEnd
+		default:
+			return i18n.T("notice.title"), i18n.T("notice.breakEnd"), s.BreakEnd
+		}
+	case timer.Warning:
+		key := "notice.warnBreak"
+		if ev.Session.Kind == timer.Work {
+			key = "notice.warnWork"
+		}
+		return i18n.T("notice.title"), i18n.T(key, s.WarnBefore), s.WarnBefore > 0
+	}
+	return "", "", false
+}
+
+// ForDue returns the reminder for a task whose due date is approaching, in
+// the current language.
+func ForDue(t task.Task) (title, message string) {
+	if t.Due.Equal(task.StartOfDay(t.Due)) {
+		return i18n.T("notice.title"), i18n.T("notice.dueToday", t.Title())
+	}
+	return i18n.T("notice.title"), i18n.T("notice.dueAt", t.Due.Format("15:04"), t.Title())
+}
@@ -1,51 +1,139 @@
 package notice
 
 import (
+	"errors"
 	"fmt"
+	"gomato/pkg/i18n"
+	"gomato/pkg/logging"
 	"os/exec"
 	"runtime"
-	"time"
+	"strings"
+	"sync"
 
 	"github.com/gen2brain/beeep"
 )
 
 const noticeMaintainTime = 10000
 
-// playSound 播放系统声音，自动适配操作系统
-func playSound() {
+// Notifier 是一种通知后端
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// Dispatcher 把一条通知同时发给多个后端。发送是异步的，不会阻塞调用方，
+// 后端返回的错误写入日志。
+type Dispatcher struct {
+	notifiers []Notifier
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher sending to the given notifiers.
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers}
+}
+
+// Send delivers the notification to every notifier in the background. It
+// is safe to call on a nil dispatcher, which sends nothing.
+func (d *Dispatcher) Send(title, message string) {
+	if d == nil {
+		return
+	}
+	for _, n := range d.notifiers {
+		d.wg.Add(1)
+		go func(n Notifier) {
+			defer d.wg.Done()
+			if err := n.Notify(title, message); err != nil {
+				logging.Log(fmt.Sprintf("[Notice] %s 发送通知失败: %v", name(n), err))
+			}
+		}(n)
+	}
+}
+
+// Wait blocks until all notifications sent so far have been delivered.
+func (d *Dispatcher) Wait() {
+	if d != nil {
+		d.wg.Wait()
+	}
+}
+
+// name 返回后端的类型名，用于日志
+func name(n Notifier) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*")
+}
+
+// Desktop 发送系统桌面通知，自动适配操作系统
+type Desktop struct{}
+
+func (Desktop) Notify(title, message string) error {
 	switch runtime.GOOS {
 	case "linux":
-		// 使用 paplay 播放系统声音（PulseAudio）
-		exec.Command("paplay", "/usr/share/sounds/freedesktop/stereo/complete.oga").Run()
-		// 如果 paplay 不可用，尝试使用 aplay
-		if exec.Command("which", "paplay").Run() != nil {
-			exec.Command("aplay", "/usr/share/sounds/sound-icons/complete.wav").Run()
-		}
-	case "darwin":
-		// macOS 使用 afplay 播放系统声音
-		exec.Command("afplay", "/System/Library/Sounds/Glass.aiff").Run()
+		return exec.Command("notify-send", "-t", fmt.Sprintf("%d", noticeMaintainTime), title, message).Run()
 	case "windows":
-		// Windows 使用 PowerShell 播放系统声音
-		exec.Command("powershell", "-c", "[console]::beep(800,200)").Run()
+		return beeep.Notify(title, message, "")
+	case "darwin":
+		// macOS 使用 osascript 发送通知
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`, appleScriptEscape(message), appleScriptEscape(title))
+		return exec.Command("osascript", "-e", script).Run()
 	}
+	return errors.New(i18n.T("notice.unsupported", runtime.GOOS))
 }
 
-// SendNotification 发送桌面通知，自动适配操作系统
-func SendNotification(title, message string) {
-	// 播放声音
-	go playSound()
+// appleScriptEscape 转义 AppleScript 字符串中的反斜杠和双引号
+func appleScriptEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// Sound 播放提示音，File 为空时使用系统声音
+type Sound struct {
+	File string
+}
 
-	// 短暂延迟确保声音和通知同步
-	time.Sleep(100 * time.Millisecond)
+func (s Sound) Notify(title, message string) error {
+	if s.File != "" {
+		return playFile(s.File)
+	}
+	return playSound()
+}
 
+// playFile 播放指定的声音文件
+func playFile(path string) error {
 	switch runtime.GOOS {
 	case "linux":
-		exec.Command("notify-send", "-t", fmt.Sprintf("%d", noticeMaintainTime), title, message).Run()
+		if _, err := exec.LookPath("paplay"); err == nil {
+			return exec.Command("paplay", path).Run()
+		}
+		return exec.Command("aplay", path).Run()
+	case "darwin":
+		return exec.Command("afplay", path).Run()
 	case "windows":
-		beeep.Notify(title, message, "")
+		script := fmt.Sprintf("(New-Object Media.SoundPlayer '%s').PlaySync()", strings.ReplaceAll(path, "'", "''"))
+		return exec.Command("powershell", "-c", script).Run()
+	}
+	return errors.New(i18n.T("notice.unsupported", runtime.GOOS))
+}
+
+// playSound 播放系统声音，自动适配操作系统
+func playSound() error {
+	switch runtime.GOOS {
+	case "linux":
+		// 使用 paplay 播放系统声音（PulseAudio），不可用时尝试 aplay
+		if _, err := exec.LookPath("paplay"); err == nil {
+			return exec.Command("paplay", "/usr/share/sounds/freedesktop/stereo/complete.oga").Run()
+		}
+		return exec.Command("aplay", "/usr/share/sounds/sound-icons/complete.wav").Run()
 	case "darwin":
-		// macOS 使用 osascript 发送通知
-		script := fmt.Sprintf(`display notification "%s" with title "%s"`, message, title)
-		exec.Command("osascript", "-e", script).Run()
+		// macOS 使用 afplay 播放系统声音
+		return exec.Command("afplay", "/System/Library/Sounds/Glass.aiff").Run()
+	case "windows":
+		// Windows 使用 PowerShell 播放系统声音
+		return exec.Command("powershell", "-c", "[console]::beep(800,200)").Run()
 	}
+	return errors.New(i18n.T("notice.unsupported", runtime.GOOS))
+}
+
+var defaultDispatcher = NewDispatcher(Desktop{}, Sound{})
+
+// SendNotification 通过默认后端（桌面通知和提示音）发送通知，不阻塞调用方
+func SendNotification(title, message string) {
+	defaultDispatcher.Send(title, message)
 }
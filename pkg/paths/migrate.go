@@ -0,0 +1,91 @@
+package paths
+
+import (
+	"fmt"
+	"gomato/pkg/i18n"
+	"os"
+	"path/filepath"
+)
+
+// legacyDir 是旧版本保存所有文件的目录
+const legacyDir = ".gomato"
+
+// configFiles 和 stateFiles 是旧目录中要移到设置目录和状态目录的文件，
+// 其他文件（任务、回收站、历史和其他任务列表）都移到数据目录
+var (
+	configFiles = map[string]bool{"setting.json": true, "keymap.json": true}
+	stateFiles  = map[string]bool{"state.json": true, "gomato.log": true}
+)
+
+// move 是迁移中移动的一个文件或目录
+type move struct {
+	from, to string
+}
+
+// Migrate moves the contents of ~/.gomato, where earlier versions kept every
+// file, into the XDG directories. It does nothing when a single directory is
+// set with SetDir or GOMATO_HOME, or when ~/.gomato does not exist. Files
+// that already exist at the destination are left in place, and the socket
+// of a daemon started by an earlier version is not moved. If a move fails
+// the files moved so far are put back and ~/.gomato keeps being used, as if
+// it had been passed to SetDir. Migrate returns the number of files and
+// directories moved.
+func Migrate() (int, error) {
+	if Override() != "" {
+		return 0, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return 0, err
+	}
+	legacy := filepath.Join(home, legacyDir)
+	entries, err := os.ReadDir(legacy)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var moves []move
+	for _, e := range entries {
+		if e.Type()&os.ModeSocket != 0 {
+			continue
+		}
+		dir := DataDir
+		switch {
+		case configFiles[e.Name()]:
+			dir = ConfigDir
+		case stateFiles[e.Name()]:
+			dir = StateDir
+		}
+		to, err := join(dir, e.Name())
+		if err != nil {
+			return 0, err
+		}
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+		moves = append(moves, move{from: filepath.Join(legacy, e.Name()), to: to})
+	}
+
+	for i, mv := range moves {
+		err := os.MkdirAll(filepath.Dir(mv.to), 0755)
+		if err == nil {
+			err = os.Rename(mv.from, mv.to)
+		}
+		if err != nil {
+			// 放回已移动的文件，继续使用旧目录也不会丢失数据
+			for _, done := range moves[:i] {
+				os.Rename(done.to, done.from)
+			}
+			SetDir(legacy)
+			return 0, fmt.Errorf("%s: %w", i18n.T("paths.migrateFailed", mv.from), err)
+		}
+	}
+	// 旧目录为空时删除，还有未迁移的文件时保留
+	if rest, err := os.ReadDir(legacy); err == nil && len(rest) == 0 {
+		os.Remove(legacy)
+	}
+	return len(moves), nil
+}
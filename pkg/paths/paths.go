@@ -0,0 +1,98 @@
+// Package paths 决定 gomato 的设置、数据和运行状态保存在哪里。
+//
+// 默认遵循 XDG 基本目录规范：
+//
+//   - 设置和按键文件在 $XDG_CONFIG_HOME/gomato（默认 ~/.config/gomato）
+//   - 任务、回收站和会话历史在 $XDG_DATA_HOME/gomato（默认 ~/.local/share/gomato）
+//   - 计时状态和日志在 $XDG_STATE_HOME/gomato（默认 ~/.local/state/gomato）
+//   - 守护进程的 socket 在 $XDG_RUNTIME_DIR/gomato，未设置时与计时状态放在一起
+//
+// 设置环境变量 GOMATO_HOME 或使用 --data-dir 参数时，所有文件都放在该目录中，
+// 布局与旧版本的 ~/.gomato 相同。
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dir 是 --data-dir 指定的目录，优先于 GOMATO_HOME
+var dir string
+
+// SetDir puts every file in dir, overriding GOMATO_HOME and the XDG
+// directories. An empty dir restores the default resolution.
+func SetDir(d string) {
+	dir = d
+}
+
+// Override returns the single directory set with SetDir or GOMATO_HOME, or
+// "" when the XDG directories are used.
+func Override() string {
+	if dir != "" {
+		return dir
+	}
+	return os.Getenv("GOMATO_HOME")
+}
+
+// xdg 返回 XDG 环境变量 env 指定的目录下的 gomato 目录，
+// 未设置或不是绝对路径时使用主目录下的 fallback
+func xdg(env, fallback string) (string, error) {
+	if d := Override(); d != "" {
+		return d, nil
+	}
+	if d := os.Getenv(env); filepath.IsAbs(d) {
+		return filepath.Join(d, "gomato"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, "gomato"), nil
+}
+
+// ConfigDir returns the directory of the settings and keymap files.
+func ConfigDir() (string, error) {
+	return xdg("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir returns the directory of the tasks, trash and history files.
+func DataDir() (string, error) {
+	return xdg("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the directory of the timer state and the log file.
+func StateDir() (string, error) {
+	return xdg("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// RuntimeDir returns the directory of the daemon socket.
+func RuntimeDir() (string, error) {
+	if d := Override(); d != "" {
+		return d, nil
+	}
+	if d := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(d) {
+		return filepath.Join(d, "gomato"), nil
+	}
+	return StateDir()
+}
+
+// join 返回目录 dir 中名为 name 的文件
+func join(dir func() (string, error), name string) (string, error) {
+	d, err := dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, name), nil
+}
+
+// ConfigFile returns the path of the named file in the config directory.
+func ConfigFile(name string) (string, error) { return join(ConfigDir, name) }
+
+// DataFile returns the path of the named file in the data directory.
+func DataFile(name string) (string, error) { return join(DataDir, name) }
+
+// StateFile returns the path of the named file in the state directory.
+func StateFile(name string) (string, error) { return join(StateDir, name) }
+
+// RuntimeFile returns the path of the named file in the runtime directory.
+func RuntimeFile(name string) (string, error) { return join(RuntimeDir, name) }
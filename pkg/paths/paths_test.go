@@ -0,0 +1,130 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolate 使用临时主目录并清除会影响目录选择的环境变量
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"GOMATO_HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_RUNTIME_DIR"} {
+		t.Setenv(env, "")
+	}
+	t.Cleanup(func() { SetDir("") })
+	return home
+}
+
+func TestDirs(t *testing.T) {
+	home := isolate(t)
+	check := func(name string, dir func() (string, error), want string) {
+		t.Helper()
+		if got, err := dir(); err != nil || got != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	check("ConfigDir", ConfigDir, filepath.Join(home, ".config", "gomato"))
+	check("DataDir", DataDir, filepath.Join(home, ".local", "share", "gomato"))
+	check("StateDir", StateDir, filepath.Join(home, ".local", "state", "gomato"))
+	check("RuntimeDir", RuntimeDir, filepath.Join(home, ".local", "state", "gomato"))
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_DATA_HOME", "relative") // 相对路径按规范忽略
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	check("ConfigDir", ConfigDir, "/xdg/config/gomato")
+	check("DataDir", DataDir, filepath.Join(home, ".local", "share", "gomato"))
+	check("RuntimeDir", RuntimeDir, "/run/user/1000/gomato")
+
+	t.Setenv("GOMATO_HOME", "/gomato")
+	check("ConfigDir", ConfigDir, "/gomato")
+	check("StateDir", StateDir, "/gomato")
+	check("RuntimeDir", RuntimeDir, "/gomato")
+
+	SetDir("/data-dir")
+	check("DataDir", DataDir, "/data-dir")
+	if got, _ := DataFile("tasks.json"); got != "/data-dir/tasks.json" {
+		t.Errorf("DataFile = %q", got)
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// TestMigrate 测试把旧版本的 ~/.gomato 按类型迁移到各 XDG 目录，已存在的文件不被覆盖
+func TestMigrate(t *testing.T) {
+	home := isolate(t)
+	legacy := filepath.Join(home, ".gomato")
+	write(t, filepath.Join(legacy, "setting.json"), "settings")
+	write(t, filepath.Join(legacy, "keymap.json"), "old keymap")
+	write(t, filepath.Join(legacy, "tasks.json"), "tasks")
+	write(t, filepath.Join(legacy, "history.jsonl"), "history")
+	write(t, filepath.Join(legacy, "lists", "work", "tasks.json"), "work")
+	write(t, filepath.Join(legacy, "state.json"), "state")
+	write(t, filepath.Join(legacy, "gomato.log"), "log")
+	config, data, state := filepath.Join(home, ".config", "gomato"), filepath.Join(home, ".local", "share", "gomato"), filepath.Join(home, ".local", "state", "gomato")
+	write(t, filepath.Join(config, "keymap.json"), "new keymap")
+
+	n, err := Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("迁移了 %d 项，want 6", n)
+	}
+	for path, want := range map[string]string{
+		filepath.Join(config, "setting.json"):              "settings",
+		filepath.Join(config, "keymap.json"):               "new keymap",
+		filepath.Join(data, "tasks.json"):                  "tasks",
+		filepath.Join(data, "history.jsonl"):               "history",
+		filepath.Join(data, "lists", "work", "tasks.json"): "work",
+		filepath.Join(state, "state.json"):                 "state",
+		filepath.Join(state, "gomato.log"):                 "log",
+		filepath.Join(legacy, "keymap.json"):               "old keymap",
+	} {
+		if got := read(path); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	// 再次运行时只剩下冲突的文件，不会重复迁移
+	if n, err := Migrate(); n != 0 || err != nil {
+		t.Errorf("第二次迁移: %d, %v", n, err)
+	}
+	os.Remove(filepath.Join(legacy, "keymap.json"))
+	if _, err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("空的 ~/.gomato 应当被删除: %v", err)
+	}
+}
+
+func TestMigrateSkippedWithOverride(t *testing.T) {
+	home := isolate(t)
+	write(t, filepath.Join(home, ".gomato", "tasks.json"), "tasks")
+	SetDir(filepath.Join(home, ".gomato"))
+	if n, err := Migrate(); n != 0 || err != nil {
+		t.Errorf("指定目录时不应迁移: %d, %v", n, err)
+	}
+	if got, _ := DataFile("tasks.json"); read(got) != "tasks" {
+		t.Errorf("DataFile = %q", got)
+	}
+}
@@ -0,0 +1,161 @@
+// Package safefile 提供不会在崩溃或磁盘写满时留下半个文件的写入，
+// 以及保存前对旧版本的滚动备份。
+package safefile
+
+import (
+	"errors"
+	"gomato/pkg/i18n"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keep 是每个文件最多保留的备份数
+var Keep = 5
+
+// Interval 是两次备份之间的最短间隔。计时期间每秒都会保存任务，
+// 不限制的话所有备份都只是最近几秒的版本。
+var Interval = time.Minute
+
+// backupDir 是备份所在的子目录
+const backupDir = "backups"
+
+// stampFormat 是备份文件名中的时间格式，按文件名排序即按时间排序
+const stampFormat = "20060102-150405"
+
+// WriteFile writes data to a temporary file in the directory of path,
+// flushes it to disk and renames it over path. Readers see either the old or
+// the new content, and a crash or a full disk never leaves a truncated file.
+// The directory is created if needed.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	// 出错时删除临时文件，成功重命名后删除会失败，可以忽略
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir 把目录项的改动（重命名）写入磁盘，不支持时忽略
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Backup copies the current content of path into the backups directory
+// next to it, keeping the newest Keep copies. Content that valid rejects is
+// not backed up, so the backups only hold versions that can be restored.
+// Nothing is done when path does not exist or the newest backup is younger
+// than Interval.
+func Backup(path string, valid func([]byte) bool) error {
+	if Keep <= 0 {
+		return nil
+	}
+	backups, err := Backups(path)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if len(backups) > 0 {
+		if info, err := os.Stat(backups[0]); err == nil && now.Sub(info.ModTime()) < Interval {
+			return nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !valid(data) {
+		return nil
+	}
+	dest := filepath.Join(filepath.Dir(path), backupDir, filepath.Base(path)+"."+now.Format(stampFormat))
+	if err := WriteFile(dest, data, 0644); err != nil {
+		return err
+	}
+
+	backups, err = Backups(path)
+	if err != nil {
+		return err
+	}
+	for _, old := range backups[min(Keep, len(backups)):] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backups returns the backup files of path, newest first.
+func Backups(path string) ([]string, error) {
+	dir := filepath.Join(filepath.Dir(path), backupDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(path) + "."
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+			if _, err := BackupTime(e.Name()); err == nil {
+				backups = append(backups, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// BackupTime returns the time a backup file was taken, read from its name.
+func BackupTime(backup string) (time.Time, error) {
+	name := filepath.Base(backup)
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return time.Time{}, errors.New(i18n.T("safefile.notBackup", backup))
+	}
+	return time.ParseInLocation(stampFormat, name[i+1:], time.Local)
+}
+
+// SetAside renames a damaged file to <path>.corrupt-<time> so that it can
+// be inspected later instead of being overwritten, and returns the new path.
+func SetAside(path string) (string, error) {
+	dest := path + ".corrupt-" + time.Now().Format(stampFormat)
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
@@ -0,0 +1,93 @@
+package safefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func valid(data []byte) bool { return string(data) != "bad" }
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "tasks.json")
+	for _, content := range []string{"one", "two"} {
+		if err := WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("内容 = %q, want %q", data, content)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("权限: %v %v", info.Mode(), err)
+	}
+	// 不留下临时文件
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("目录中有 %d 项，want 1", len(entries))
+	}
+}
+
+// TestBackup 测试备份的间隔、数量上限，以及无效内容不会被备份
+func TestBackup(t *testing.T) {
+	defer func(keep int, interval time.Duration) { Keep, Interval = keep, interval }(Keep, Interval)
+	Keep, Interval = 2, time.Hour
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.json")
+
+	if err := Backup(path, valid); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := Backups(path); len(backups) != 0 {
+		t.Fatalf("文件不存在时不应备份: %v", backups)
+	}
+	os.WriteFile(path, []byte("v1"), 0644)
+	Backup(path, valid)
+	os.WriteFile(path, []byte("v2"), 0644)
+	Backup(path, valid)
+	if backups, _ := Backups(path); len(backups) != 1 {
+		t.Fatalf("间隔内只应备份一次: %v", backups)
+	}
+
+	// 用不同时间的文件名模拟多次备份
+	Interval = 0
+	backupsDir := filepath.Join(dir, backupDir)
+	for i, stamp := range []string{"20240101-080000", "20240102-080000"} {
+		os.WriteFile(filepath.Join(backupsDir, "tasks.json."+stamp), []byte{byte('a' + i)}, 0644)
+	}
+	os.WriteFile(path, []byte("bad"), 0644)
+	Backup(path, valid)
+	backups, _ := Backups(path)
+	if len(backups) != 3 {
+		t.Fatalf("无效内容不应备份: %v", backups)
+	}
+	os.WriteFile(path, []byte("v3"), 0644)
+	if err := Backup(path, valid); err != nil {
+		t.Fatal(err)
+	}
+	backups, _ = Backups(path)
+	if len(backups) != Keep {
+		t.Fatalf("应只保留 %d 个备份: %v", Keep, backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "v3" {
+		t.Errorf("最新的备份 = %q", data)
+	}
+	if filepath.Base(backups[1]) != "tasks.json.20240102-080000" {
+		t.Errorf("最旧的备份应当被删除: %v", backups)
+	}
+}
+
+func TestSetAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	os.WriteFile(path, []byte("bad"), 0644)
+	aside, err := SetAside(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("原文件应当被移走: %v", err)
+	}
+	if data, _ := os.ReadFile(aside); string(data) != "bad" {
+		t.Errorf("%s = %q", aside, data)
+	}
+}
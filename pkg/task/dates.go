@@ -0,0 +1,224 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"gomato/pkg/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 日期只精确到天时保存为当天零点，没有具体时间的截止日期在当天结束时才算过期
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+var (
+	relativeDate = regexp.MustCompile(`^\+(\d+)([dwm天周月])$`)
+	clockTime    = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+// dayWords 是相对今天的日期写法
+var dayWords = map[string]int{
+	"today": 0, "tod": 0, "今天": 0,
+	"tomorrow": 1, "tmr": 1, "明天": 1,
+	"后天": 2,
+}
+
+// weekdayWords 是星期的写法，表示之后七天内的那一天
+var weekdayWords = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "周日": time.Sunday, "周天": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "周一": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "周二": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "周三": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "周四": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "周五": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "周六": time.Saturday,
+}
+
+// ParseDate parses a date relative to now. It accepts "today", "tomorrow",
+// "+3d", "+2w", "+1m", weekday names such as "fri" (the next Friday),
+// "2025-03-05" and "03-05", each optionally followed by a time such as
+// "18:00". A time on its own means today. An empty string gives the zero
+// time.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return time.Time{}, nil
+	}
+	hour, min, timed := 0, 0, false
+	if m := clockTime.FindStringSubmatch(fields[len(fields)-1]); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		min, _ = strconv.Atoi(m[2])
+		if hour > 23 || min > 59 {
+			return time.Time{}, errors.New(i18n.T("task.badTime", fields[len(fields)-1]))
+		}
+		fields, timed = fields[:len(fields)-1], true
+	}
+
+	var day time.Time
+	switch {
+	case len(fields) == 0 && timed:
+		day = StartOfDay(now)
+	case len(fields) != 1:
+		return time.Time{}, errors.New(i18n.T("task.badDate", s))
+	default:
+		var err error
+		if day, err = parseDay(fields[0], now); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute), nil
+}
+
+// parseDay 解析不含时间的日期，返回当天零点
+func parseDay(s string, now time.Time) (time.Time, error) {
+	today := StartOfDay(now)
+	if n, ok := dayWords[s]; ok {
+		return today.AddDate(0, 0, n), nil
+	}
+	if wd, ok := weekdayWords[s]; ok {
+		n := (int(wd) - int(today.Weekday()) + 7) % 7
+		if n == 0 {
+			n = 7
+		}
+		return today.AddDate(0, 0, n), nil
+	}
+	if m := relativeDate.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d", "天":
+			return today.AddDate(0, 0, n), nil
+		case "w", "周":
+			return today.AddDate(0, 0, 7*n), nil
+		default:
+			return today.AddDate(0, n, 0), nil
+		}
+	}
+	if d, err := time.ParseInLocation(dateLayout, s, now.Location()); err == nil {
+		return d, nil
+	}
+	if d, err := time.ParseInLocation(dateLayout, fmt.Sprintf("%d-%s", now.Year(), s), now.Location()); err == nil {
+		return d, nil
+	}
+	return time.Time{}, errors.New(i18n.T("task.badDate", s))
+}
+
+// FormatDate formats d so that ParseDate reads it back, omitting the time
+// for dates without one.
+func FormatDate(d time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+	if isWholeDay(d) {
+		return d.Format(dateLayout)
+	}
+	return d.Format(dateTimeLayout)
+}
+
+func isWholeDay(d time.Time) bool {
+	return d.Equal(StartOfDay(d))
+}
+
+// deadline 返回截止日期实际到期的时刻
+func deadline(d time.Time) time.Time {
+	if isWholeDay(d) {
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// Overdue reports whether the task is open and past its due date.
+func (t Task) Overdue(now time.Time) bool {
+	return !t.Done && !t.Due.IsZero() && !now.Before(deadline(t.Due))
+}
+
+// DueSoon reports whether an open task is within lead of its due time and
+// not yet overdue. Tasks due on a whole day are due soon all that day.
+func (t Task) DueSoon(now time.Time, lead time.Duration) bool {
+	if t.Done || t.Due.IsZero() {
+		return false
+	}
+	remindAt := t.Due
+	if !isWholeDay(t.Due) {
+		remindAt = t.Due.Add(-lead)
+	}
+	return !now.Before(remindAt) && now.Before(deadline(t.Due))
+}
+
+// DateFilter 是任务列表按日期筛选的方式
+type DateFilter int
+
+const (
+	FilterAll       DateFilter = iota
+	FilterOverdue              // 已过期
+	FilterToday                // 今天到期或计划在今天及之前
+	FilterThisWeek             // 本周内到期
+	FilterScheduled            // 有计划日期
+)
+
+// DateFilters 是可选的日期筛选方式，按切换顺序排列
+var DateFilters = []DateFilter{FilterAll, FilterOverdue, FilterToday, FilterThisWeek, FilterScheduled}
+
+var dateFilterNames = []string{"all", "overdue", "today", "week", "scheduled"}
+
+// String returns the filter name used in the message catalog.
+func (f DateFilter) String() string {
+	return dateFilterNames[f]
+}
+
+// Match reports whether the task passes the filter at now.
+func (f DateFilter) Match(t Task, now time.Time) bool {
+	tomorrow := StartOfDay(now).AddDate(0, 0, 1)
+	switch f {
+	case FilterOverdue:
+		return t.Overdue(now)
+	case FilterToday:
+		return (!t.Due.IsZero() && t.Due.Before(tomorrow)) || (!t.Scheduled.IsZero() && t.Scheduled.Before(tomorrow))
+	case FilterThisWeek:
+		return !t.Due.IsZero() && t.Due.Before(StartOfWeek(now).AddDate(0, 0, 7))
+	case FilterScheduled:
+		return !t.Scheduled.IsZero()
+	}
+	return true
+}
+
+// relativeDay 以“今天”“明天”或日期的形式显示 d，有具体时间时附上时间
+func relativeDay(d, now time.Time) string {
+	var day string
+	switch StartOfDay(d).Sub(StartOfDay(now)).Round(time.Hour) / (24 * time.Hour) {
+	case -1:
+		day = i18n.T("date.yesterday")
+	case 0:
+		day = i18n.T("date.today")
+	case 1:
+		day = i18n.T("date.tomorrow")
+	default:
+		if d.Year() == now.Year() {
+			day = d.Format("01-02")
+		} else {
+			day = d.Format(dateLayout)
+		}
+	}
+	if !isWholeDay(d) {
+		day += " " + d.Format("15:04")
+	}
+	return day
+}
+
+// Dates returns the due and scheduled dates as shown in the task list,
+// e.g. "到期 明天 18:00  计划 今天".
+func (t Task) Dates(now time.Time) string {
+	var parts []string
+	if !t.Due.IsZero() {
+		parts = append(parts, i18n.T("date.due", relativeDay(t.Due, now)))
+	}
+	if !t.Scheduled.IsZero() {
+		parts = append(parts, i18n.T("date.scheduled", relativeDay(t.Scheduled, now)))
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,149 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseDate 测试相对日期、星期、绝对日期和时间的解析
+func TestParseDate(t *testing.T) {
+	// 2025-03-05 是星期三
+	now := time.Date(2025, 3, 5, 14, 30, 0, 0, time.Local)
+	day := func(m time.Month, d, h, min int) time.Time { return time.Date(2025, m, d, h, min, 0, 0, time.Local) }
+	cases := map[string]time.Time{
+		"":                 {},
+		"today":            day(3, 5, 0, 0),
+		"Tomorrow":         day(3, 6, 0, 0),
+		"明天 18:00":         day(3, 6, 18, 0),
+		"+3d":              day(3, 8, 0, 0),
+		"+2w":              day(3, 19, 0, 0),
+		"+1m":              day(4, 5, 0, 0),
+		"fri":              day(3, 7, 0, 0),
+		"wed":              day(3, 12, 0, 0),
+		"周一":               day(3, 10, 0, 0),
+		"2025-04-01":       day(4, 1, 0, 0),
+		"04-01 9:15":       day(4, 1, 9, 15),
+		"2025-03-05 18:00": day(3, 5, 18, 0),
+		"20:00":            day(3, 5, 20, 0),
+	}
+	for in, want := range cases {
+		got, err := ParseDate(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v; want %v", in, got, err, want)
+		}
+		if back, _ := ParseDate(FormatDate(got), now); !back.Equal(got) {
+			t.Errorf("FormatDate(%v) = %q 无法解析回原值", got, FormatDate(got))
+		}
+	}
+	for _, in := range []string{"someday", "25:00", "2025-13-01", "today tomorrow"} {
+		if _, err := ParseDate(in, now); err == nil {
+			t.Errorf("ParseDate(%q) 应返回错误", in)
+		}
+	}
+}
+
+// TestOverdueAndDueSoon 测试只有日期的截止日期在当天结束时过期，有时间的提前提醒
+func TestOverdueAndDueSoon(t *testing.T) {
+	now := time.Date(2025, 3, 5, 17, 40, 0, 0, time.Local)
+	lead := 30 * time.Minute
+	wholeDay := Task{Due: time.Date(2025, 3, 5, 0, 0, 0, 0, time.Local)}
+	timed := Task{Due: time.Date(2025, 3, 5, 18, 0, 0, 0, time.Local)}
+	later := Task{Due: time.Date(2025, 3, 5, 19, 0, 0, 0, time.Local)}
+	past := Task{Due: time.Date(2025, 3, 4, 0, 0, 0, 0, time.Local)}
+
+	if wholeDay.Overdue(now) || !wholeDay.DueSoon(now, lead) {
+		t.Error("今天到期的任务当天应提醒而不算过期")
+	}
+	if timed.Overdue(now) || !timed.DueSoon(now, lead) || later.DueSoon(now, lead) {
+		t.Error("有时间的截止日期应在提前量之内才提醒")
+	}
+	if !past.Overdue(now) || past.DueSoon(now, lead) {
+		t.Error("昨天到期的任务应算过期且不再提醒")
+	}
+	past.Done = true
+	if past.Overdue(now) {
+		t.Error("已完成的任务不算过期")
+	}
+}
+
+// TestSortAndDateFilter 测试按截止日期排序和按日期筛选
+func TestSortAndDateFilter(t *testing.T) {
+	now := time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local)
+	date := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.Local) }
+	tasks := []Task{
+		{ID: "none"},
+		{ID: "next", Due: date(12)},
+		{ID: "late", Due: date(3)},
+		{ID: "plan", Scheduled: date(5)},
+		{ID: "week", Due: date(7)},
+	}
+	Sort(tasks, SortDue)
+	var ids []string
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if want := "late week next none plan"; fmt.Sprint(ids) != "["+want+"]" {
+		t.Errorf("按截止日期排序: %v, 想要 %s", ids, want)
+	}
+
+	want := map[DateFilter]string{
+		FilterAll:       "[late week next none plan]",
+		FilterOverdue:   "[late]",
+		FilterToday:     "[late plan]",
+		FilterThisWeek:  "[late week]",
+		FilterScheduled: "[plan]",
+	}
+	for _, f := range DateFilters {
+		var got []string
+		for _, task := range tasks {
+			if f.Match(task, now) {
+				got = append(got, task.ID)
+			}
+		}
+		if fmt.Sprint(got) != want[f] {
+			t.Errorf("筛选 %s: %v, 想要 %s", f, got, want[f])
+		}
+	}
+}
+
+// TestSortModes 测试按优先级、剩余番茄数和最近完成时间排序，以及排序方式的解析
+func TestSortModes(t *testing.T) {
+	worked := time.Date(2025, 3, 5, 10, 0, 0, 0, time.Local)
+	tasks := []Task{
+		{Name: "a"},
+		{Name: "b", Priority: PriorityLow, Estimate: 4, Pomodoros: 1, WorkedAt: worked},
+		{Name: "c", Priority: PriorityHigh, Estimate: 2, Pomodoros: 1},
+		{Name: "d", Estimate: 3, Pomodoros: 5, WorkedAt: worked.Add(time.Hour)},
+	}
+	names := func(tasks []Task) string {
+		var s []string
+		for _, t := range tasks {
+			s = append(s, t.Name)
+		}
+		return strings.Join(s, ",")
+	}
+	cases := map[SortMode]string{
+		SortManual:    "a,b,c,d",
+		SortPriority:  "c,b,a,d",
+		SortRemaining: "d,c,b,a",
+		SortRecent:    "d,b,a,c",
+	}
+	for mode, want := range cases {
+		sorted := append([]Task(nil), tasks...)
+		Sort(sorted, mode)
+		if got := names(sorted); got != want {
+			t.Errorf("%s: %s, want %s", mode, got, want)
+		}
+	}
+
+	for _, mode := range SortModes {
+		if got, err := ParseSortMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseSortMode(%q) = %v, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseSortMode("random"); err == nil {
+		t.Error("未知的排序方式应返回错误")
+	}
+}
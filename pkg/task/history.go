@@ -0,0 +1,163 @@
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"gomato/pkg/paths"
+	"gomato/pkg/timer"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SessionType 表示一次会话的类型
+type SessionType string
+
+const (
+	SessionWork       SessionType = "work"
+	SessionShortBreak SessionType = "shortBreak"
+	SessionLongBreak  SessionType = "longBreak"
+)
+
+// Session 是一次工作/休息会话的历史记录
+type Session struct {
+	TaskName  string      `json:"task"`
+	Type      SessionType `json:"type"`
+	Planned   int         `json:"planned"` // 计划时长（秒）
+	Start     time.Time   `json:"start"`
+	End       time.Time   `json:"end"`
+	Paused    int         `json:"paused"`    // 暂停总时长（秒）
+	Completed bool        `json:"completed"` // false 表示中途放弃
+
+	// 会话所属任务的 ID；重复任务的各次另外记录共用的 Series，便于关联历史
+	TaskID string `json:"taskId,omitempty"`
+	Series string `json:"series,omitempty"`
+	List   string `json:"list,omitempty"` // 任务所在的列表，旧记录为空
+
+	// 会话开始时任务的项目、标签和优先级，用于分组统计
+	Project  string   `json:"project,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority Priority `json:"priority,omitempty"`
+}
+
+// Actual returns the time actually spent in the session, excluding pauses.
+func (s Session) Actual() time.Duration {
+	d := s.End.Sub(s.Start) - time.Duration(s.Paused)*time.Second
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ListName returns the task list the session was recorded in. Sessions
+// recorded before task lists were introduced belong to the default list.
+func (s Session) ListName() string {
+	return listName(s.List)
+}
+
+// NewSession converts a session reported by the timer engine into a
+// history record for task t. Sessions without a task pass the zero Task.
+func NewSession(t Task, s timer.Session, completed bool) Session {
+	var series string
+	if !t.Recur.IsZero() || t.Series != "" {
+		series = t.SeriesID()
+	}
+	return Session{
+		TaskName:  t.Name,
+		TaskID:    t.ID,
+		Series:    series,
+		Project:   t.Project,
+		Tags:      t.Tags,
+		Priority:  t.Priority,
+		Type:      SessionType(s.Kind),
+		Planned:   int(s.Planned / time.Second),
+		Start:     s.Start,
+		End:       s.End,
+		Paused:    int(s.Paused / time.Second),
+		Completed: completed,
+	}
+}
+
+// History is an append-only log of sessions stored as JSON lines.
+type History struct {
+	filePath string
+}
+
+// NewHistory creates a history backed by the default history file.
+func NewHistory() (*History, error) {
+	path, err := paths.DataFile("history.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	return &History{filePath: path}, nil
+}
+
+// Append writes a session record to the end of the history file.
+func (h *History) Append(s Session) error {
+	if err := os.MkdirAll(filepath.Dir(h.filePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads every recorded session in the order they were written.
+// Lines that cannot be parsed are skipped so a single bad record does not
+// hide the rest of the history.
+func (h *History) Load() ([]Session, error) {
+	f, err := os.Open(h.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var sessions []Session
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var s Session
+		if err := json.Unmarshal(line, &s); err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, scanner.Err()
+}
+
+// Between returns the sessions that started within [from, to).
+func (h *History) Between(from, to time.Time) ([]Session, error) {
+	all, err := h.Load()
+	if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	for _, s := range all {
+		if !s.Start.Before(from) && s.Start.Before(to) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+// Today returns the sessions that started today in local time.
+func (h *History) Today() ([]Session, error) {
+	start := StartOfDay(time.Now())
+	return h.Between(start, start.AddDate(0, 0, 1))
+}
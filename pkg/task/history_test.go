@@ -0,0 +1,54 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryAppendAndLoad(t *testing.T) {
+	h := &History{filePath: filepath.Join(t.TempDir(), "history.jsonl")}
+
+	// 文件不存在时返回空记录
+	sessions, err := h.Load()
+	if err != nil {
+		t.Fatalf("读取空历史失败: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("期望0条记录，实际%d条", len(sessions))
+	}
+
+	start := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	records := []Session{
+		{TaskName: "写报告", Type: SessionWork, Planned: 1500, Start: start, End: start.Add(27 * time.Minute), Paused: 120, Completed: true},
+		{TaskName: "写报告", Type: SessionShortBreak, Planned: 300, Start: start.Add(27 * time.Minute), End: start.Add(29 * time.Minute), Completed: false},
+		{TaskName: "复盘", Type: SessionWork, Planned: 1500, Start: start.AddDate(0, 0, 1), End: start.AddDate(0, 0, 1).Add(25 * time.Minute), Completed: true},
+	}
+	for _, s := range records {
+		if err := h.Append(s); err != nil {
+			t.Fatalf("写入历史失败: %v", err)
+		}
+	}
+
+	sessions, err = h.Load()
+	if err != nil {
+		t.Fatalf("读取历史失败: %v", err)
+	}
+	if len(sessions) != len(records) {
+		t.Fatalf("期望%d条记录，实际%d条", len(records), len(sessions))
+	}
+	if sessions[0].Actual() != 25*time.Minute {
+		t.Errorf("期望实际时长25分钟，实际%v", sessions[0].Actual())
+	}
+	if sessions[1].Completed {
+		t.Errorf("第二条记录应为放弃的会话")
+	}
+
+	day, err := h.Between(start.Add(-time.Hour), start.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("按时间查询失败: %v", err)
+	}
+	if len(day) != 2 {
+		t.Errorf("期望当天2条记录，实际%d条", len(day))
+	}
+}
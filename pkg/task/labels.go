@@ -0,0 +1,108 @@
+package task
+
+import (
+	"errors"
+	"gomato/pkg/i18n"
+	"strings"
+)
+
+// Priority 是任务的优先级，零值表示未设置
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = []string{"", "low", "medium", "high"}
+
+// priorityAliases 是解析优先级时接受的其他写法
+var priorityAliases = map[string]Priority{
+	"none": PriorityNone, "0": PriorityNone,
+	"l": PriorityLow, "1": PriorityLow, "!": PriorityLow, "低": PriorityLow,
+	"m": PriorityMedium, "2": PriorityMedium, "!!": PriorityMedium, "中": PriorityMedium,
+	"h": PriorityHigh, "3": PriorityHigh, "!!!": PriorityHigh, "高": PriorityHigh,
+}
+
+// ParsePriority parses a priority such as "high", "h", "3", "!!!" or "高".
+// An empty string means no priority.
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for p, name := range priorityNames {
+		if s == name {
+			return Priority(p), nil
+		}
+	}
+	if p, ok := priorityAliases[s]; ok {
+		return p, nil
+	}
+	return PriorityNone, errors.New(i18n.T("task.badPriority", s))
+}
+
+// String returns "high", "medium", "low", or "" if no priority is set.
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityHigh {
+		return ""
+	}
+	return priorityNames[p]
+}
+
+// Marker returns the priority as exclamation marks, e.g. "!!!" for high.
+func (p Priority) Marker() string {
+	if p <= PriorityNone || p > PriorityHigh {
+		return ""
+	}
+	return strings.Repeat("!", int(p))
+}
+
+// MarshalText 让优先级在 tasks.json 中以名称保存
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText 接受名称，也接受数字形式的优先级
+func (p *Priority) UnmarshalText(text []byte) error {
+	v, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// ParseTags splits s on commas and whitespace into tags, dropping a leading
+// "#" and duplicates.
+func ParseTags(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == ' ' || r == '\t'
+	})
+	var tags []string
+	seen := map[string]bool{}
+	for _, f := range fields {
+		f = strings.TrimLeft(f, "#")
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		tags = append(tags, f)
+	}
+	return tags
+}
+
+// Labels returns the project, tags and priority of the task as shown in the
+// task list, e.g. "@工作 #写作 #紧急 !!!".
+func (t Task) Labels() string {
+	var parts []string
+	if t.Project != "" {
+		parts = append(parts, "@"+t.Project)
+	}
+	for _, tag := range t.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	if m := t.Priority.Marker(); m != "" {
+		parts = append(parts, m)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"errors"
+	"gomato/pkg/i18n"
+	"gomato/pkg/paths"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultList 是默认任务列表的名称，它的任务保存在数据目录中的 tasks.json，
+// 其他列表保存在数据目录中的 lists/<名称>/tasks.json
+const DefaultList = "default"
+
+// maxListName 是列表名称的最大长度（字符数）
+const maxListName = 64
+
+// listName 把空名称视为默认列表
+func listName(name string) string {
+	if name == "" {
+		return DefaultList
+	}
+	return name
+}
+
+// ValidateListName reports whether name can be used as a task list name.
+// Names become directory names, so path separators and leading dots are
+// rejected.
+func ValidateListName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return errors.New(i18n.T("task.listEmpty"))
+	case strings.TrimSpace(name) != name:
+		return errors.New(i18n.T("task.listSpace", name))
+	case strings.HasPrefix(name, "."):
+		return errors.New(i18n.T("task.listDot", name))
+	case strings.ContainsAny(name, `/\:`):
+		return errors.New(i18n.T("task.listChars", name))
+	case utf8.RuneCountInString(name) > maxListName:
+		return errors.New(i18n.T("task.listLong", maxListName))
+	}
+	return nil
+}
+
+// listPath 返回列表的任务文件路径
+func listPath(name string) (string, error) {
+	name = listName(name)
+	if err := ValidateListName(name); err != nil {
+		return "", err
+	}
+	dir, err := paths.DataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultList {
+		return filepath.Join(dir, "tasks.json"), nil
+	}
+	return filepath.Join(dir, "lists", name, "tasks.json"), nil
+}
+
+// Lists returns the names of the existing task lists, the default list
+// first and the others sorted by name. The default list is always
+// included.
+func Lists() ([]string, error) {
+	dir, err := paths.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "lists"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != DefaultList && ValidateListName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultList}, names...), nil
+}
+
+// ListExists reports whether the task list with the given name has been
+// created. The default list always exists.
+func ListExists(name string) bool {
+	name = listName(name)
+	if name == DefaultList {
+		return true
+	}
+	path, err := listPath(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Dir(path))
+	return err == nil
+}
+
+// List returns the name of the task list the manager holds.
+func (m *Manager) List() string {
+	return listName(m.list)
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateListName(t *testing.T) {
+	for _, name := range []string{"work", "side-project", "家务", "a b"} {
+		if err := ValidateListName(name); err != nil {
+			t.Errorf("ValidateListName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", " ", " work", "work ", ".hidden", "a/b", `a\b`, "a:b", strings.Repeat("x", 65)} {
+		if err := ValidateListName(name); err == nil {
+			t.Errorf("ValidateListName(%q) should fail", name)
+		}
+	}
+}
+
+// TestLists 测试每个列表使用自己的文件，默认列表沿用数据目录中的 tasks.json
+func TestLists(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+
+	def, err := NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.List() != DefaultList || def.filePath != filepath.Join(dir, "tasks.json") {
+		t.Errorf("默认列表: %s %s", def.List(), def.filePath)
+	}
+	if err := def.AddItem("写报告", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if ListExists("work") {
+		t.Error("work 在保存前不应存在")
+	}
+	work, err := NewManager("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.filePath != filepath.Join(dir, "lists", "work", "tasks.json") {
+		t.Errorf("work 的路径 = %s", work.filePath)
+	}
+	if err := work.AddItem("周报", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager("home"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager("../escape"); err == nil {
+		t.Error("无效的列表名称应当报错")
+	}
+
+	lists, err := Lists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{DefaultList, "work"}; !reflect.DeepEqual(lists, want) {
+		t.Errorf("Lists() = %v, want %v", lists, want)
+	}
+	if !ListExists("work") || !ListExists("") {
+		t.Error("work 和默认列表应当存在")
+	}
+
+	def, _ = NewManager(DefaultList)
+	work, _ = NewManager("work")
+	if names(def) != "写报告" || names(work) != "周报" {
+		t.Errorf("默认列表 %s，work %s", names(def), names(work))
+	}
+}
+
+func TestInList(t *testing.T) {
+	sessions := []Session{
+		{TaskName: "写报告", Type: SessionWork},
+		{TaskName: "周报", Type: SessionWork, List: "work"},
+		{TaskName: "读书", Type: SessionWork, List: DefaultList},
+	}
+	var got []string
+	for _, s := range InList(sessions, "") {
+		got = append(got, s.TaskName)
+	}
+	if want := []string{"写报告", "读书"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("默认列表的会话 = %v, want %v", got, want)
+	}
+	if got := InList(sessions, "work"); len(got) != 1 || got[0].TaskName != "周报" {
+		t.Errorf("work 的会话 = %+v", got)
+	}
+
+	groups := Group(sessions, GroupByList)
+	if len(groups) != 2 || groups[0].Name != DefaultList || groups[1].Name != "work" {
+		t.Errorf("按列表分组 = %+v", groups)
+	}
+}
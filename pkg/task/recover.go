@@ -0,0 +1,89 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"gomato/pkg/i18n"
+	"gomato/pkg/safefile"
+	"os"
+	"time"
+)
+
+// CorruptError reports a task file that exists but cannot be parsed.
+// NewManager returns it instead of an empty list so that the next save does
+// not overwrite the tasks; see Recover.
+type CorruptError struct {
+	List string // 任务列表名称
+	Path string
+	Err  error
+}
+
+func (e *CorruptError) Error() string {
+	return i18n.T("task.corrupt", e.Path, e.Err)
+}
+
+func (e *CorruptError) Unwrap() error { return e.Err }
+
+// Backup 是任务文件的一个可以恢复的备份
+type Backup struct {
+	Path  string
+	Time  time.Time
+	Tasks int // 备份中的任务数
+}
+
+// validTasks 报告 data 是否是可以读取的任务文件
+func validTasks(data []byte) bool {
+	var tasks []Task
+	return json.Unmarshal(data, &tasks) == nil
+}
+
+// ListBackups returns the backups of the named task list that can be
+// restored, newest first.
+func ListBackups(list string) ([]Backup, error) {
+	path, err := listPath(list)
+	if err != nil {
+		return nil, err
+	}
+	files, err := safefile.Backups(path)
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var tasks []Task
+		if json.Unmarshal(data, &tasks) != nil {
+			continue
+		}
+		at, _ := safefile.BackupTime(f)
+		backups = append(backups, Backup{Path: f, Time: at, Tasks: len(tasks)})
+	}
+	return backups, nil
+}
+
+// Recover replaces the damaged task file of the named list with the backup
+// at path backup, or with an empty list when backup is "". The damaged file
+// is kept next to it as tasks.json.corrupt-<time>; Recover returns its path.
+func Recover(list, backup string) (string, error) {
+	path, err := listPath(list)
+	if err != nil {
+		return "", err
+	}
+	data := []byte("[]")
+	if backup != "" {
+		if data, err = os.ReadFile(backup); err != nil {
+			return "", err
+		}
+		if !validTasks(data) {
+			return "", errors.New(i18n.T("task.badBackup", backup))
+		}
+	}
+	aside, err := safefile.SetAside(path)
+	if err != nil {
+		return "", err
+	}
+	return aside, safefile.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"errors"
+	"gomato/pkg/safefile"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRecover 测试损坏的任务文件不会被覆盖，并且可以从最新的有效备份恢复
+func TestRecover(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+	defer func(interval time.Duration) { safefile.Interval = interval }(safefile.Interval)
+	safefile.Interval = 0
+
+	m, err := NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddItem("写报告", "")
+	m.AddItem("读论文", "") // 保存前备份了只有一个任务的版本
+	path := filepath.Join(dir, "tasks.json")
+	os.WriteFile(path, []byte(`[{"name": "写`), 0644)
+
+	_, err = NewManager("")
+	var corrupt *CorruptError
+	if !errors.As(err, &corrupt) || corrupt.List != DefaultList || corrupt.Path != path {
+		t.Fatalf("NewManager = %v, want CorruptError", err)
+	}
+	if err := m.Load(); err == nil || len(m.Tasks) != 2 {
+		t.Errorf("Load 失败时不应修改任务: %v %d", err, len(m.Tasks))
+	}
+
+	backups, err := ListBackups("")
+	if err != nil || len(backups) != 1 || backups[0].Tasks != 1 {
+		t.Fatalf("ListBackups = %+v, %v", backups, err)
+	}
+	aside, err := Recover("", backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(aside); string(data) != `[{"name": "写` {
+		t.Errorf("损坏的文件应当保留: %q", data)
+	}
+	m, err = NewManager("")
+	if err != nil || len(m.Tasks) != 1 || m.Tasks[0].Name != "写报告" {
+		t.Fatalf("恢复后: %+v, %v", m, err)
+	}
+
+	// 没有备份时使用空的列表
+	os.MkdirAll(filepath.Join(dir, "lists", "work"), 0755)
+	os.WriteFile(filepath.Join(dir, "lists", "work", "tasks.json"), []byte("{"), 0644)
+	if backups, _ := ListBackups("work"); len(backups) != 0 {
+		t.Errorf("work 不应有备份: %+v", backups)
+	}
+	if _, err := Recover("work", ""); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := NewManager("work"); err != nil || len(m.Tasks) != 0 {
+		t.Errorf("恢复为空列表后: %v", err)
+	}
+}
+
+// TestCorruptTrash 测试损坏的回收站被移到一旁，不影响打开任务
+func TestCorruptTrash(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOMATO_HOME", dir)
+	os.WriteFile(filepath.Join(dir, "tasks.json"), []byte("[]"), 0644)
+	os.WriteFile(filepath.Join(dir, "trash.json"), []byte("["), 0644)
+	m, err := NewManager("")
+	if err != nil || len(m.Trash) != 0 {
+		t.Fatalf("NewManager = %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "trash.json.corrupt-*")); len(matches) != 1 {
+		t.Errorf("损坏的回收站应当保留: %v", matches)
+	}
+}
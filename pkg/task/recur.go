@@ -0,0 +1,296 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"gomato/pkg/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency 是重复规则的类型
+type Frequency int
+
+const (
+	RepeatNone     Frequency = iota
+	RepeatDaily              // 每天
+	RepeatWeekdays           // 周一到周五
+	RepeatWeekly             // 每周的指定几天，未指定时为起始日期的星期
+	RepeatEvery              // 每隔 Interval 天
+	RepeatMonthly            // 每月的 Day 日，未指定时为起始日期的那一天
+)
+
+// Recurrence 是任务的重复规则，零值表示不重复
+type Recurrence struct {
+	Freq     Frequency
+	Interval int            // RepeatEvery 的间隔天数
+	Days     []time.Weekday // RepeatWeekly 的星期，按周日到周六排列
+	Day      int            // RepeatMonthly 的日期，月份没有这一天时取最后一天
+}
+
+var everyDays = regexp.MustCompile(`^(?:every|每)\s*(\d+)\s*(?:d|days?|天)$`)
+
+var monthlyDay = regexp.MustCompile(`^(?:monthly|每月)\s*(\d+)\s*(?:日|号)?$`)
+
+// recurrenceWords 是不带参数的重复规则的写法
+var recurrenceWords = map[string]Frequency{
+	"none": RepeatNone, "不重复": RepeatNone,
+	"daily": RepeatDaily, "每天": RepeatDaily,
+	"weekdays": RepeatWeekdays, "工作日": RepeatWeekdays,
+	"weekly": RepeatWeekly, "每周": RepeatWeekly,
+	"monthly": RepeatMonthly, "每月": RepeatMonthly,
+}
+
+// ParseRecurrence parses a rule such as "daily", "weekdays", "weekly",
+// "weekly mon,thu", "every 3d", "monthly" or "monthly 31". An empty string
+// means the task does not repeat.
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Recurrence{}, nil
+	}
+	if f, ok := recurrenceWords[s]; ok {
+		return Recurrence{Freq: f}, nil
+	}
+	if m := everyDays.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 {
+			return Recurrence{}, errors.New(i18n.T("task.badInterval", s))
+		}
+		if n == 1 {
+			return Recurrence{Freq: RepeatDaily}, nil
+		}
+		return Recurrence{Freq: RepeatEvery, Interval: n}, nil
+	}
+	if m := monthlyDay.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > 31 {
+			return Recurrence{}, errors.New(i18n.T("task.badMonthDay", s))
+		}
+		return Recurrence{Freq: RepeatMonthly, Day: n}, nil
+	}
+	for _, prefix := range []string{"weekly", "每周"} {
+		rest, ok := strings.CutPrefix(s, prefix)
+		if !ok {
+			continue
+		}
+		var on [7]bool
+		for _, d := range strings.FieldsFunc(rest, func(r rune) bool {
+			return r == ',' || r == '，' || r == '、' || r == ' '
+		}) {
+			wd, ok := weekdayWords[d]
+			if !ok {
+				return Recurrence{}, errors.New(i18n.T("task.badWeekday", d))
+			}
+			on[wd] = true
+		}
+		r := Recurrence{Freq: RepeatWeekly}
+		for wd, ok := range on {
+			if ok {
+				r.Days = append(r.Days, time.Weekday(wd))
+			}
+		}
+		return r, nil
+	}
+	return Recurrence{}, errors.New(i18n.T("task.badRepeat", s))
+}
+
+// IsZero reports whether the rule means no recurrence.
+func (r Recurrence) IsZero() bool {
+	return r.Freq == RepeatNone
+}
+
+// String returns the rule in the form ParseRecurrence accepts.
+func (r Recurrence) String() string {
+	switch r.Freq {
+	case RepeatDaily:
+		return "daily"
+	case RepeatWeekdays:
+		return "weekdays"
+	case RepeatWeekly:
+		if len(r.Days) == 0 {
+			return "weekly"
+		}
+		days := make([]string, len(r.Days))
+		for i, d := range r.Days {
+			days[i] = strings.ToLower(d.String()[:3])
+		}
+		return "weekly " + strings.Join(days, ",")
+	case RepeatEvery:
+		return fmt.Sprintf("every %dd", r.Interval)
+	case RepeatMonthly:
+		if r.Day > 0 {
+			return fmt.Sprintf("monthly %d", r.Day)
+		}
+		return "monthly"
+	}
+	return ""
+}
+
+// Label returns the rule as shown in the task list, e.g. "每周一、四".
+func (r Recurrence) Label() string {
+	switch r.Freq {
+	case RepeatDaily:
+		return i18n.T("repeat.daily")
+	case RepeatWeekdays:
+		return i18n.T("repeat.weekdays")
+	case RepeatWeekly:
+		if len(r.Days) == 0 {
+			return i18n.T("repeat.weekly")
+		}
+		days := make([]string, len(r.Days))
+		for i, d := range r.Days {
+			days[i] = i18n.T(fmt.Sprintf("weekday.%d", d))
+		}
+		return i18n.T("repeat.weeklyOn", strings.Join(days, i18n.T("repeat.separator")))
+	case RepeatEvery:
+		return i18n.T("repeat.every", r.Interval)
+	case RepeatMonthly:
+		if r.Day > 0 {
+			return i18n.T("repeat.monthlyOn", r.Day)
+		}
+		return i18n.T("repeat.monthly")
+	}
+	return ""
+}
+
+// RecurrenceLabel returns the recurrence as shown in the task list, e.g.
+// "↻ 每天", or "" if the task does not repeat.
+func (t Task) RecurrenceLabel() string {
+	if t.Recur.IsZero() {
+		return ""
+	}
+	return "↻ " + t.Recur.Label()
+}
+
+// MarshalText 让重复规则在 tasks.json 中以 String 的形式保存
+func (r Recurrence) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText 接受 ParseRecurrence 能解析的规则
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	v, err := ParseRecurrence(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Next returns the first occurrence after the day of d, keeping the time of
+// day. It returns the zero time if the rule does not repeat.
+func (r Recurrence) Next(d time.Time) time.Time {
+	switch r.Freq {
+	case RepeatDaily:
+		return d.AddDate(0, 0, 1)
+	case RepeatWeekdays:
+		next := d.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case RepeatWeekly:
+		if len(r.Days) == 0 {
+			return d.AddDate(0, 0, 7)
+		}
+		for n := 1; ; n++ {
+			next := d.AddDate(0, 0, n)
+			for _, wd := range r.Days {
+				if next.Weekday() == wd {
+					return next
+				}
+			}
+		}
+	case RepeatEvery:
+		return d.AddDate(0, 0, r.Interval)
+	case RepeatMonthly:
+		// 下个月没有这一天时取该月最后一天，避免 1 月 31 日跳到 3 月。
+		// 每个月都从 Day 算起，2 月取了 28 日之后 3 月仍回到 31 日
+		first := time.Date(d.Year(), d.Month()+1, 1, d.Hour(), d.Minute(), d.Second(), 0, d.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		day := r.Day
+		if day == 0 {
+			day = d.Day()
+		}
+		if day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+	return time.Time{}
+}
+
+// SeriesID returns the ID shared by all occurrences of a recurring task,
+// which is the ID of the first occurrence.
+func (t Task) SeriesID() string {
+	if t.Series != "" {
+		return t.Series
+	}
+	return t.ID
+}
+
+// nextOccurrence 返回重复任务在 now 完成后的下一次，计时、番茄数和清单进度都重新开始。
+// 日期按规则顺延到今天之后，错过的几次不再补上；没有日期的任务从完成当天起算。
+func (t Task) nextOccurrence(id string, now time.Time) Task {
+	next := t
+	next.ID = id
+	next.Series = t.SeriesID()
+	next.Next = ""
+	next.Done = false
+	next.CompletedAt = time.Time{}
+	next.Pomodoros = 0
+	next.Timer = TimeModel{IsWorkSession: true, SessionType: SessionWork}
+	next.Checklist = nil
+	for _, s := range t.Checklist {
+		next.Checklist = append(next.Checklist, Subtask{Title: s.Title})
+	}
+	next.Tags = append([]string(nil), t.Tags...)
+
+	base := t.Due
+	if base.IsZero() {
+		base = t.Scheduled
+	}
+	// 每月重复的任务记下起始的日期，之后各次都从这一天算起
+	if next.Recur.Freq == RepeatMonthly && next.Recur.Day == 0 {
+		if base.IsZero() {
+			next.Recur.Day = now.Day()
+		} else {
+			next.Recur.Day = base.Day()
+		}
+	}
+	if base.IsZero() {
+		next.Scheduled = next.Recur.Next(StartOfDay(now))
+		return next
+	}
+	date := next.Recur.Next(base)
+	for date.Before(StartOfDay(now).AddDate(0, 0, 1)) {
+		date = next.Recur.Next(date)
+	}
+	shift := func(d time.Time) time.Time {
+		if d.IsZero() {
+			return d
+		}
+		// 按日历天数顺延，跨越夏令时也保持原来的时刻
+		days := int(StartOfDay(date).Sub(StartOfDay(base)).Round(time.Hour) / (24 * time.Hour))
+		return d.AddDate(0, 0, days)
+	}
+	next.Due = shift(t.Due)
+	next.Scheduled = shift(t.Scheduled)
+	return next
+}
+
+// untouched 报告自动生成的下一次是否还没有开始处理，取消完成时可以安全地删除
+func (t Task) untouched() bool {
+	if t.Done || t.Pomodoros > 0 || !t.Timer.StartedAt.IsZero() {
+		return false
+	}
+	for _, s := range t.Checklist {
+		if s.Done || s.Pomodoros > 0 {
+			return false
+		}
+	}
+	return true
+}
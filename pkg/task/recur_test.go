@@ -0,0 +1,147 @@
+package task
+
+import (
+	"encoding/json"
+	"gomato/pkg/timer"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseRecurrence 测试各种重复规则的写法，以及 String 能被解析回原规则
+func TestParseRecurrence(t *testing.T) {
+	cases := map[string]string{
+		"":                "",
+		"Daily":           "daily",
+		"每天":              "daily",
+		"every 1 day":     "daily",
+		"工作日":             "weekdays",
+		"weekly":          "weekly",
+		"weekly thu, mon": "weekly mon,thu",
+		"每周 周一、周四":        "weekly mon,thu",
+		"every 3d":        "every 3d",
+		"每14天":            "every 14d",
+		"monthly":         "monthly",
+		"monthly 31":      "monthly 31",
+		"每月 15 日":         "monthly 15",
+	}
+	for in, want := range cases {
+		r, err := ParseRecurrence(in)
+		if err != nil || r.String() != want {
+			t.Errorf("ParseRecurrence(%q) = %q, %v; want %q", in, r, err, want)
+			continue
+		}
+		if back, _ := ParseRecurrence(r.String()); back.String() != want {
+			t.Errorf("%q 无法解析回原规则", r)
+		}
+	}
+	for _, in := range []string{"hourly", "every 0d", "weekly someday", "monthly 32"} {
+		if _, err := ParseRecurrence(in); err == nil {
+			t.Errorf("ParseRecurrence(%q) 应返回错误", in)
+		}
+	}
+}
+
+// TestRecurrenceNext 测试各规则的下一次日期，保留原来的时刻
+func TestRecurrenceNext(t *testing.T) {
+	// 2025-01-31 是星期五
+	d := time.Date(2025, 1, 31, 9, 30, 0, 0, time.Local)
+	day := func(m time.Month, n int) time.Time { return time.Date(2025, m, n, 9, 30, 0, 0, time.Local) }
+	cases := map[string]time.Time{
+		"daily":          day(2, 1),
+		"weekdays":       day(2, 3),
+		"weekly":         day(2, 7),
+		"weekly mon,sat": day(2, 1),
+		"weekly fri":     day(2, 7),
+		"every 10d":      day(2, 10),
+		"monthly":        day(2, 28),
+		"monthly 15":     day(2, 15),
+	}
+	for rule, want := range cases {
+		r, _ := ParseRecurrence(rule)
+		if got := r.Next(d); !got.Equal(want) {
+			t.Errorf("%s: Next = %v, want %v", rule, got, want)
+		}
+	}
+}
+
+// TestMonthlyKeepsDay 测试每月重复的任务在短月取最后一天之后，下个月仍回到原来的日期
+func TestMonthlyKeepsDay(t *testing.T) {
+	m := newTestManager(t)
+	m.AddItem("交房租", "")
+	m.Tasks[0].Recur, _ = ParseRecurrence("monthly")
+	m.Tasks[0].Due = time.Date(2025, 1, 31, 18, 0, 0, 0, time.Local)
+
+	id := m.Tasks[0].ID
+	for _, want := range []time.Time{
+		time.Date(2025, 2, 28, 18, 0, 0, 0, time.Local),
+		time.Date(2025, 3, 31, 18, 0, 0, 0, time.Local),
+		time.Date(2025, 4, 30, 18, 0, 0, 0, time.Local),
+		time.Date(2025, 5, 31, 18, 0, 0, 0, time.Local),
+	} {
+		// 在每次截止的当天完成
+		i := m.Index(id)
+		if err := m.SetDone(id, true, m.Tasks[i].Due); err != nil {
+			t.Fatal(err)
+		}
+		next := m.Tasks[m.Index(m.Tasks[i].Next)]
+		if !next.Due.Equal(want) || next.Recur.String() != "monthly 31" {
+			t.Fatalf("下一次 = %v %q, want %v", next.Due, next.Recur, want)
+		}
+		id = next.ID
+	}
+}
+
+// TestCompleteRecurringTask 测试完成重复任务时生成下一次，取消完成时删除未开始的下一次
+func TestCompleteRecurringTask(t *testing.T) {
+	m := newTestManager(t)
+	m.AddItem("周报", "")
+	m.AddItem("读书", "")
+	now := time.Date(2025, 3, 5, 17, 0, 0, 0, time.Local) // 星期三
+	first := &m.Tasks[0]
+	first.Recur, _ = ParseRecurrence("weekly fri")
+	first.Due = time.Date(2025, 2, 21, 18, 0, 0, 0, time.Local) // 错过了两次
+	first.Pomodoros = 3
+	first.Checklist = []Subtask{{Title: "汇总", Done: true, Pomodoros: 2}}
+	id := first.ID
+
+	if err := m.SetDone(id, true, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tasks) != 3 || m.Tasks[1].ID != m.Tasks[0].Next {
+		t.Fatalf("下一次应插在原任务之后: %+v", m.Tasks)
+	}
+	next := m.Tasks[1]
+	if next.Done || next.Pomodoros != 0 || next.Series != id || next.Checklist[0].Done || next.Checklist[0].Pomodoros != 0 {
+		t.Errorf("下一次应重新开始: %+v", next)
+	}
+	if want := time.Date(2025, 3, 7, 18, 0, 0, 0, time.Local); !next.Due.Equal(want) {
+		t.Errorf("下一次截止日期 = %v, want %v", next.Due, want)
+	}
+
+	// 重复规则以文本保存，读取后仍可继续生成
+	data, _ := json.Marshal(next)
+	if !strings.Contains(string(data), `"repeat":"weekly fri"`) {
+		t.Errorf("保存的任务: %s", data)
+	}
+	if data, _ := json.Marshal(m.Tasks[2]); strings.Contains(string(data), "repeat") {
+		t.Errorf("不重复的任务不应保存 repeat: %s", data)
+	}
+	reloaded := &Manager{filePath: m.filePath}
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if r := reloaded.Tasks[1].Recur; r.String() != "weekly fri" {
+		t.Errorf("读取的重复规则: %q", r)
+	}
+	if s := NewSession(next, timer.Session{Kind: timer.Work}, true); s.Series != id || s.TaskID != next.ID {
+		t.Errorf("历史记录应关联到同一系列: %+v", s)
+	}
+
+	if err := m.SetDone(id, false, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tasks) != 2 || m.Tasks[0].Next != "" {
+		t.Errorf("取消完成后应删除未开始的下一次: %+v", m.Tasks)
+	}
+}
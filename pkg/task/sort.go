@@ -0,0 +1,86 @@
+package task
+
+import (
+	"errors"
+	"gomato/pkg/i18n"
+	"sort"
+	"time"
+)
+
+// SortMode 是任务列表的排序方式
+type SortMode int
+
+const (
+	SortManual    SortMode = iota // 按 tasks.json 中的顺序，可手动调整
+	SortPriority                  // 按优先级从高到低，未设置的排在最后
+	SortDue                       // 按截止日期，没有截止日期的排在最后
+	SortScheduled                 // 按计划日期，没有计划日期的排在最后
+	SortRemaining                 // 按剩余预估番茄数从少到多，没有预估的排在最后
+	SortRecent                    // 按最近完成番茄的时间，最近的在前
+)
+
+// SortModes 是可选的排序方式，按切换顺序排列
+var SortModes = []SortMode{SortManual, SortPriority, SortDue, SortScheduled, SortRemaining, SortRecent}
+
+var sortModeNames = []string{"manual", "priority", "due", "scheduled", "remaining", "recent"}
+
+// String returns the sort mode name used in the message catalog and the
+// settings file.
+func (s SortMode) String() string {
+	if s < 0 || int(s) >= len(sortModeNames) {
+		return sortModeNames[SortManual]
+	}
+	return sortModeNames[s]
+}
+
+// ParseSortMode parses a sort mode name such as "due" or "recent".
+func ParseSortMode(s string) (SortMode, error) {
+	for i, name := range sortModeNames {
+		if s == name {
+			return SortMode(i), nil
+		}
+	}
+	return SortManual, errors.New(i18n.T("task.badSort", s))
+}
+
+// Sort sorts tasks in place by mode. Tasks that compare equal keep their
+// order, so the manual order breaks ties.
+func Sort(tasks []Task, mode SortMode) {
+	if mode == SortManual {
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return Less(tasks[i], tasks[j], mode) })
+}
+
+// Less reports whether a sorts before b in mode. Tasks missing the sort key
+// sort last, and no task sorts before another in the manual order.
+func Less(a, b Task, mode SortMode) bool {
+	switch mode {
+	case SortPriority:
+		return a.Priority > b.Priority
+	case SortDue:
+		return dateLess(a.Due, b.Due)
+	case SortScheduled:
+		return dateLess(a.Scheduled, b.Scheduled)
+	case SortRemaining:
+		if a.Estimate == 0 || b.Estimate == 0 {
+			return a.Estimate != 0 && b.Estimate == 0
+		}
+		return a.Remaining() < b.Remaining()
+	case SortRecent:
+		x, y := a.WorkedAt, b.WorkedAt
+		if x.IsZero() || y.IsZero() {
+			return !x.IsZero() && y.IsZero()
+		}
+		return x.After(y)
+	}
+	return false
+}
+
+// dateLess 按时间先后比较，零值排在最后
+func dateLess(x, y time.Time) bool {
+	if x.IsZero() || y.IsZero() {
+		return !x.IsZero() && y.IsZero()
+	}
+	return x.Before(y)
+}
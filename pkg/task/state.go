@@ -0,0 +1,85 @@
+package task
+
+import (
+	"encoding/json"
+	"gomato/pkg/paths"
+	"gomato/pkg/safefile"
+	"gomato/pkg/timer"
+	"os"
+	"time"
+)
+
+// ActiveState 记录正在进行的计时状态，用于程序重启后恢复
+type ActiveState struct {
+	TaskID     string    `json:"taskId"`
+	TaskIndex  int       `json:"taskIndex"` // 保存时任务的位置，仅用于读取没有 TaskID 的旧状态
+	TaskName   string    `json:"taskName"`
+	List       string    `json:"list,omitempty"` // 任务所在的列表，为空表示默认列表
+	Timer      TimeModel `json:"timer"`
+	CycleCount int       `json:"cycleCount"`
+	SavedAt    time.Time `json:"savedAt"`
+}
+
+// InProgress reports whether the snapshot holds a session that was started
+// and not yet finished or abandoned.
+func (s ActiveState) InProgress() bool {
+	return !s.Timer.StartedAt.IsZero()
+}
+
+// ListName returns the task list the state's task belongs to.
+func (s ActiveState) ListName() string {
+	return listName(s.List)
+}
+
+// Snapshot returns the saved timer as an engine snapshot.
+func (s ActiveState) Snapshot() timer.Snapshot {
+	return s.Timer.Snapshot(s.CycleCount)
+}
+
+// StateStore persists the active timer state to a JSON file.
+type StateStore struct {
+	filePath string
+}
+
+// NewStateStore creates a store backed by the default state file.
+func NewStateStore() (*StateStore, error) {
+	path, err := paths.StateFile("state.json")
+	if err != nil {
+		return nil, err
+	}
+	return &StateStore{filePath: path}, nil
+}
+
+// Load reads the saved state. It returns nil without error if nothing has
+// been saved yet.
+func (s *StateStore) Load() (*ActiveState, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state ActiveState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save writes the state, replacing any previous snapshot.
+func (s *StateStore) Save(state ActiveState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return safefile.WriteFile(s.filePath, data, 0644)
+}
+
+// Clear removes the saved state.
+func (s *StateStore) Clear() error {
+	if err := os.Remove(s.filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
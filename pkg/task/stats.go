@@ -0,0 +1,185 @@
+package task
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats 汇总一组会话的统计数据
+type Stats struct {
+	WorkSessions int           // 完成的工作会话数
+	Abandoned    int           // 放弃的工作会话数
+	Breaks       int           // 完成的休息次数
+	FocusTime    time.Duration // 工作会话的实际专注时长
+	PerTask      []TaskStats   // 按专注时长降序排列
+}
+
+// TaskStats 是单个任务（或一个分组）的统计数据
+type TaskStats struct {
+	Name      string
+	Sessions  int
+	FocusTime time.Duration
+}
+
+// CompletionRate returns the share of started work sessions that were
+// completed, in the range [0, 1].
+func (s Stats) CompletionRate() float64 {
+	total := s.WorkSessions + s.Abandoned
+	if total == 0 {
+		return 0
+	}
+	return float64(s.WorkSessions) / float64(total)
+}
+
+// TaskProgress 统计一段时间内任务的完成情况
+type TaskProgress struct {
+	Completed int // 在这段时间内完成的任务数
+	Open      int // 尚未完成的任务数
+}
+
+// Rate returns the share of tasks completed, in the range [0, 1].
+func (p TaskProgress) Rate() float64 {
+	total := p.Completed + p.Open
+	if total == 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(total)
+}
+
+// Progress counts the tasks completed at or after from and the tasks not
+// yet completed.
+func Progress(tasks []Task, from time.Time) TaskProgress {
+	var p TaskProgress
+	for _, t := range tasks {
+		switch {
+		case !t.Done:
+			p.Open++
+		case !t.CompletedAt.Before(from):
+			p.Completed++
+		}
+	}
+	return p
+}
+
+// Grouping 是分组统计的依据
+type Grouping string
+
+const (
+	GroupByTask     Grouping = "task"
+	GroupByProject  Grouping = "project"
+	GroupByTag      Grouping = "tag"
+	GroupByPriority Grouping = "priority"
+	GroupByList     Grouping = "list"
+)
+
+// Groupings 是可选的分组方式
+var Groupings = []Grouping{GroupByTask, GroupByProject, GroupByTag, GroupByPriority, GroupByList}
+
+// keys 返回会话所属的分组，带多个标签的会话计入每个标签；
+// 没有对应属性的会话归入名称为空的分组
+func (g Grouping) keys(s Session) []string {
+	switch g {
+	case GroupByProject:
+		return []string{s.Project}
+	case GroupByTag:
+		if len(s.Tags) == 0 {
+			return []string{""}
+		}
+		return s.Tags
+	case GroupByPriority:
+		return []string{s.Priority.String()}
+	case GroupByList:
+		return []string{s.ListName()}
+	default:
+		return []string{s.TaskName}
+	}
+}
+
+// Summarize computes statistics over the given sessions, with PerTask
+// grouped by task name.
+func Summarize(sessions []Session) Stats {
+	var st Stats
+	for _, s := range sessions {
+		if s.Type != SessionWork {
+			if s.Completed {
+				st.Breaks++
+			}
+			continue
+		}
+		if s.Completed {
+			st.WorkSessions++
+		} else {
+			st.Abandoned++
+		}
+		st.FocusTime += s.Actual()
+	}
+	st.PerTask = Group(sessions, GroupByTask)
+	return st
+}
+
+// Group computes per-group statistics of the work sessions, ordered by
+// focus time, descending.
+func Group(sessions []Session, by Grouping) []TaskStats {
+	groups := map[string]*TaskStats{}
+	for _, s := range sessions {
+		if s.Type != SessionWork {
+			continue
+		}
+		for _, k := range by.keys(s) {
+			ts, ok := groups[k]
+			if !ok {
+				ts = &TaskStats{Name: k}
+				groups[k] = ts
+			}
+			if s.Completed {
+				ts.Sessions++
+			}
+			ts.FocusTime += s.Actual()
+		}
+	}
+
+	var result []TaskStats
+	for _, ts := range groups {
+		result = append(result, *ts)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FocusTime != result[j].FocusTime {
+			return result[i].FocusTime > result[j].FocusTime
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Since returns the sessions that started at or after from.
+func Since(sessions []Session, from time.Time) []Session {
+	var result []Session
+	for _, s := range sessions {
+		if !s.Start.Before(from) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// InList returns the sessions recorded in the named task list.
+func InList(sessions []Session, list string) []Session {
+	var result []Session
+	for _, s := range sessions {
+		if s.ListName() == listName(list) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// StartOfDay returns midnight of the day containing t.
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns midnight of the Monday of the week containing t.
+func StartOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // 周一为一周的第一天
+	return StartOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,79 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local) // 周三
+	sessions := []Session{
+		{TaskName: "A", Type: SessionWork, Start: start, End: start.Add(25 * time.Minute), Completed: true},
+		{TaskName: "A", Type: SessionShortBreak, Start: start.Add(25 * time.Minute), End: start.Add(30 * time.Minute), Completed: true},
+		{TaskName: "B", Type: SessionWork, Start: start.Add(30 * time.Minute), End: start.Add(40 * time.Minute), Completed: false},
+		{TaskName: "B", Type: SessionWork, Start: start.Add(time.Hour), End: start.Add(time.Hour + 30*time.Minute), Paused: 300, Completed: true},
+	}
+
+	st := Summarize(sessions)
+	if st.WorkSessions != 2 || st.Abandoned != 1 || st.Breaks != 1 {
+		t.Fatalf("会话计数错误: %+v", st)
+	}
+	if st.FocusTime != 60*time.Minute {
+		t.Errorf("期望专注60分钟，实际%v", st.FocusTime)
+	}
+	if rate := st.CompletionRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("期望完成率约为2/3，实际%v", rate)
+	}
+	if len(st.PerTask) != 2 || st.PerTask[0].Name != "B" || st.PerTask[0].FocusTime != 35*time.Minute {
+		t.Errorf("按任务统计错误: %+v", st.PerTask)
+	}
+
+	if w := StartOfWeek(start); w.Weekday() != time.Monday || w.Day() != 3 {
+		t.Errorf("本周起始日期错误: %v", w)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	now := time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local)
+	tasks := []Task{
+		{Name: "A", Done: true, CompletedAt: now.Add(-time.Hour)},
+		{Name: "B", Done: true, CompletedAt: now.AddDate(0, 0, -3)},
+		{Name: "C"},
+	}
+	if p := Progress(tasks, StartOfDay(now)); p.Completed != 1 || p.Open != 1 || p.Rate() != 0.5 {
+		t.Errorf("今天的任务完成情况错误: %+v", p)
+	}
+	if p := Progress(tasks, time.Time{}); p.Completed != 2 || p.Open != 1 {
+		t.Errorf("全部任务完成情况错误: %+v", p)
+	}
+	if r := (TaskProgress{}).Rate(); r != 0 {
+		t.Errorf("没有任务时完成率应为0，实际%v", r)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	start := time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local)
+	work := func(project string, tags []string, p Priority, minutes int) Session {
+		return Session{Type: SessionWork, Project: project, Tags: tags, Priority: p, Start: start,
+			End: start.Add(time.Duration(minutes) * time.Minute), Completed: true}
+	}
+	sessions := []Session{
+		work("工作", []string{"写作", "紧急"}, PriorityHigh, 25),
+		work("工作", []string{"写作"}, PriorityLow, 25),
+		work("", nil, PriorityNone, 10),
+		{Type: SessionShortBreak, Project: "工作", Start: start, End: start.Add(5 * time.Minute), Completed: true},
+	}
+
+	byProject := Group(sessions, GroupByProject)
+	if len(byProject) != 2 || byProject[0].Name != "工作" || byProject[0].Sessions != 2 || byProject[1].Name != "" {
+		t.Errorf("按项目分组: %+v", byProject)
+	}
+	byTag := Group(sessions, GroupByTag)
+	if len(byTag) != 3 || byTag[0].Name != "写作" || byTag[0].FocusTime != 50*time.Minute {
+		t.Errorf("按标签分组: %+v", byTag)
+	}
+	byPriority := Group(sessions, GroupByPriority)
+	if len(byPriority) != 3 || byPriority[0].Name != "high" {
+		t.Errorf("按优先级分组: %+v", byPriority)
+	}
+}
@@ -1,20 +1,155 @@
 package task
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"gomato/pkg/common"
+	"gomato/pkg/i18n"
+	"gomato/pkg/safefile"
+	"gomato/pkg/timer"
 )
 
 // Task represents a single task in the task list.
 // It implements the list.Item interface.
 type Task struct {
+	ID     string    `json:"id"` // 创建时生成，不随排序或筛选改变
 	Name   string    `json:"title"`
 	Detail string    `json:"description"`
 	Timer  TimeModel `json:"timer"`
+
+	Project  string   `json:"project,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority Priority `json:"priority,omitempty"`
+
+	Checklist []Subtask `json:"checklist,omitempty"` // 按顺序排列的子任务
+
+	Due       time.Time `json:"due,omitempty"`       // 截止日期，只有日期时为当天零点
+	Scheduled time.Time `json:"scheduled,omitempty"` // 计划开始处理的日期
+
+	Recur  Recurrence `json:"repeat"`           // 重复规则，完成后生成下一次；不重复时不保存
+	Series string     `json:"series,omitempty"` // 重复任务第一次的 ID，各次共用
+	Next   string     `json:"next,omitempty"`   // 完成后生成的下一次的 ID
+
+	Estimate  int       `json:"estimate,omitempty"`  // 预估需要的番茄数，0 表示未预估
+	Pomodoros int       `json:"pomodoros,omitempty"` // 已完成的番茄数
+	WorkedAt  time.Time `json:"workedAt,omitempty"`  // 最近一个番茄完成的时间
+
+	Done        bool      `json:"done,omitempty"`
+	CompletedAt time.Time `json:"completedAt,omitempty"` // 标记完成的时间
+}
+
+// taskJSON 是保存到文件中的任务，不重复时省略 repeat 字段。
+// omitzero 需要 Go 1.24，omitempty 对结构体不起作用，所以用指针字段覆盖 Recur。
+type taskJSON struct {
+	plainTask
+	Recur *Recurrence `json:"repeat,omitempty"`
+}
+
+// plainTask 没有 Task 的方法，避免 MarshalJSON 递归调用自己
+type plainTask Task
+
+func (t Task) toJSON() taskJSON {
+	j := taskJSON{plainTask: plainTask(t)}
+	if !t.Recur.IsZero() {
+		j.Recur = &t.Recur
+	}
+	return j
+}
+
+// MarshalJSON 在任务不重复时省略 repeat 字段
+func (t Task) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.toJSON())
+}
+
+// Subtask 是任务清单中的一项
+type Subtask struct {
+	Title     string `json:"title"`
+	Done      bool   `json:"done,omitempty"`
+	Pomodoros int    `json:"pomodoros,omitempty"` // 番茄结束时选择了该项的次数
+}
+
+// ChecklistProgress renders how many checklist items are done, e.g.
+// "☑ 2/5", or "" if the task has no checklist.
+func (t Task) ChecklistProgress() string {
+	if len(t.Checklist) == 0 {
+		return ""
+	}
+	done := 0
+	for _, s := range t.Checklist {
+		if s.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("☑ %d/%d", done, len(t.Checklist))
+}
+
+// OpenSubtasks returns the positions of the checklist items not yet done.
+func (t Task) OpenSubtasks() []int {
+	var open []int
+	for i, s := range t.Checklist {
+		if !s.Done {
+			open = append(open, i)
+		}
+	}
+	return open
+}
+
+// maxPomodoroDots 是进度中最多显示的圆点数，超出时只显示数字
+const maxPomodoroDots = 10
+
+// CountPomodoro records a work session on the task that completed at end.
+func (t *Task) CountPomodoro(end time.Time) {
+	t.Pomodoros++
+	t.WorkedAt = end
+}
+
+// Remaining returns how many of the estimated pomodoros are left, or 0 if
+// the estimate is used up or there is none.
+func (t Task) Remaining() int {
+	if t.Pomodoros >= t.Estimate {
+		return 0
+	}
+	return t.Estimate - t.Pomodoros
+}
+
+// OverEstimate reports whether more pomodoros have been spent on the task
+// than were estimated.
+func (t Task) OverEstimate() bool {
+	return t.Estimate > 0 && t.Pomodoros > t.Estimate
+}
+
+// PomodoroProgress renders the completed pomodoros against the estimate,
+// e.g. "●●○○ 2/4", or "" if the task has neither.
+func (t Task) PomodoroProgress() string {
+	n := t.Estimate
+	if t.Pomodoros > n {
+		n = t.Pomodoros
+	}
+	if n == 0 {
+		return ""
+	}
+	count := fmt.Sprintf("%d", t.Pomodoros)
+	if t.Estimate > 0 {
+		count = fmt.Sprintf("%d/%d", t.Pomodoros, t.Estimate)
+	}
+	if n > maxPomodoroDots {
+		return count
+	}
+	return strings.Repeat("●", t.Pomodoros) + strings.Repeat("○", n-t.Pomodoros) + " " + count
+}
+
+// Archived reports whether a completed task has left the task list, which
+// happens on the day after it was completed.
+func (t Task) Archived(now time.Time) bool {
+	return t.Done && t.CompletedAt.Before(StartOfDay(now))
 }
 
 // TimeModel 用于任务计时器（需导出字段以便持久化）
@@ -23,28 +158,139 @@ type TimeModel struct {
 	TimerRemaining int  `json:"timerRemaining"`
 	TimerIsRunning bool `json:"timerIsRunning"`
 	IsWorkSession  bool `json:"isWorkSession"`
+
+	// 以下字段用于记录当前会话的历史信息
+	SessionType   SessionType `json:"sessionType,omitempty"`
+	StartedAt     time.Time   `json:"startedAt,omitempty"`
+	PausedAt      time.Time   `json:"pausedAt,omitempty"`
+	PausedSeconds int         `json:"pausedSeconds,omitempty"`
+
+	// Deadline 是会话按墙上时钟计算的结束时间，暂停期间会顺延。
+	// 为零值时表示会话尚未开始，剩余时间以 TimerRemaining 为准。
+	Deadline time.Time `json:"deadline,omitempty"`
 }
 
-func (t Task) FilterValue() string { return t.Name }
-func (t Task) Title() string       { return t.Name }
-func (t Task) Description() string { return t.Detail }
+// Snapshot converts the persisted timer into a timer engine snapshot.
+func (t TimeModel) Snapshot(cycle int) timer.Snapshot {
+	s := timer.Snapshot{
+		Kind:      timer.Kind(t.Type()),
+		Planned:   time.Duration(t.TimerDuration) * time.Second,
+		Remaining: time.Duration(t.TimerRemaining) * time.Second,
+		StartedAt: t.StartedAt,
+		PausedAt:  t.PausedAt,
+		Paused:    time.Duration(t.PausedSeconds) * time.Second,
+		Deadline:  t.Deadline,
+		Cycle:     cycle,
+	}
+	switch {
+	case t.StartedAt.IsZero() || t.Deadline.IsZero():
+		// 没有截止时间的旧数据按尚未开始处理
+		s.State = timer.StateIdle
+		s.StartedAt = time.Time{}
+		s.PausedAt = time.Time{}
+		s.Deadline = time.Time{}
+	case t.TimerIsRunning:
+		s.State = timer.StateRunning
+		s.PausedAt = time.Time{}
+	default:
+		s.State = timer.StatePaused
+		if s.PausedAt.IsZero() {
+			s.PausedAt = s.Deadline.Add(-s.Remaining)
+		}
+	}
+	return s
+}
+
+// NewTimeModel converts an engine snapshot into its persisted form, with
+// TimerRemaining rounded up to whole seconds as of now.
+func NewTimeModel(s timer.Snapshot, now time.Time) TimeModel {
+	remaining := s.RemainingAt(now)
+	return TimeModel{
+		TimerDuration:  int(s.Planned / time.Second),
+		TimerRemaining: int((remaining + time.Second - 1) / time.Second),
+		TimerIsRunning: s.State == timer.StateRunning,
+		IsWorkSession:  s.Kind == timer.Work,
+		SessionType:    SessionType(s.Kind),
+		StartedAt:      s.StartedAt,
+		PausedAt:       s.PausedAt,
+		PausedSeconds:  int(s.Paused / time.Second),
+		Deadline:       s.Deadline,
+	}
+}
+
+// Type returns the session type, falling back to IsWorkSession for timers
+// saved before the type was recorded.
+func (t TimeModel) Type() SessionType {
+	if t.SessionType != "" {
+		return t.SessionType
+	}
+	if t.IsWorkSession {
+		return SessionWork
+	}
+	return SessionShortBreak
+}
+
+// FilterValue 以标题开头，筛选时标题中匹配的字符才能正确高亮；
+// 项目、标签和优先级也可用于筛选，如输入 "@工作" 或 "#紧急"
+func (t Task) FilterValue() string {
+	labels := t.Labels()
+	if p := t.Priority.String(); p != "" {
+		labels += " " + p
+	}
+	if labels == "" {
+		return t.Name
+	}
+	return t.Name + " " + labels
+}
+
+func (t Task) Title() string { return t.Name }
+
+// Description 依次显示标签、日期、重复规则、番茄进度、清单进度和任务描述
+func (t Task) Description() string {
+	var parts []string
+	for _, s := range []string{t.Labels(), t.Dates(time.Now()), t.RecurrenceLabel(), t.PomodoroProgress(), t.ChecklistProgress(), t.Detail} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
 
 // Manager handles task loading, saving, and manipulation.
 type Manager struct {
 	Tasks    []Task
+	Trash    []TrashedTask // 已删除的任务，可以恢复
+	list     string        // 列表名称，为空表示默认列表
 	filePath string
+	stamp    fileStamp // 最近一次读取或保存后的任务文件，用于发现其他进程的修改
+}
+
+// fileStamp 记录文件的修改时间和大小
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampOf 返回 path 当前的 fileStamp，文件不存在时返回零值
+func stampOf(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
 }
 
-// NewManager creates a new task manager and loads tasks from the default path.
-func NewManager() (*Manager, error) {
-	home, err := os.UserHomeDir()
+// NewManager creates a task manager for the named task list and loads its
+// tasks. An empty name opens the default list. A list that does not exist
+// yet starts out empty and is created on the first save.
+func NewManager(list string) (*Manager, error) {
+	filePath, err := listPath(list)
 	if err != nil {
 		return nil, err
 	}
-	configDir := filepath.Join(home, ".gomato")
-	filePath := filepath.Join(configDir, "tasks.json")
 
 	m := &Manager{
+		list:     listName(list),
 		filePath: filePath,
 	}
 
@@ -54,91 +300,255 @@ func NewManager() (*Manager, error) {
 			return nil, err
 		}
 	}
+	if err := m.LoadTrash(); err != nil {
+		return nil, err
+	}
 
 	return m, nil
 }
 
-// Load reads tasks from the JSON file.
+// Load reads tasks from the JSON file. Tasks saved before IDs were
+// introduced are given one and the file is rewritten.
 func (m *Manager) Load() error {
 	data, err := os.ReadFile(m.filePath)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &m.Tasks)
+	// 先解析到新的切片，文件损坏时内存中的任务保持不变
+	var tasks []Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return &CorruptError{List: m.List(), Path: m.filePath, Err: err}
+	}
+	m.Tasks = tasks
+	m.stamp = stampOf(m.filePath)
+	if m.assignIDs() {
+		return m.Save()
+	}
+	return nil
 }
 
-// Save writes the current tasks to the JSON file.
-func (m *Manager) Save() error {
-	// Ensure the directory exists.
-	dir := filepath.Dir(m.filePath)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return err
+// assignIDs 为没有 ID 或 ID 重复的任务生成新 ID，返回是否有改动
+func (m *Manager) assignIDs() bool {
+	changed := false
+	seen := make(map[string]bool, len(m.Tasks))
+	for i := range m.Tasks {
+		if id := m.Tasks[i].ID; id == "" || seen[id] {
+			m.Tasks[i].ID = m.newID()
+			changed = true
 		}
+		seen[m.Tasks[i].ID] = true
 	}
+	return changed
+}
 
+// newID 生成一个与现有任务都不同的随机 ID
+func (m *Manager) newID() string {
+	for {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		id := hex.EncodeToString(b)
+		if m.Index(id) < 0 && m.TrashIndex(id) < 0 {
+			return id
+		}
+	}
+}
+
+// Save writes the current tasks to the JSON file.
+func (m *Manager) Save() error {
 	data, err := json.MarshalIndent(m.Tasks, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(m.filePath, data, 0644)
+	// 备份只是为了在文件损坏时恢复，备份失败不影响保存
+	safefile.Backup(m.filePath, validTasks)
+	if err := safefile.WriteFile(m.filePath, data, 0644); err != nil {
+		return err
+	}
+	m.stamp = stampOf(m.filePath)
+	return nil
+}
+
+// Reload reads the task file again if another process, such as the daemon
+// or a CLI command, changed it since it was last loaded or saved, and
+// reports whether it did. Saving without reloading would overwrite those
+// changes.
+func (m *Manager) Reload() (bool, error) {
+	if m.filePath == "" {
+		return false, nil
+	}
+	stamp := stampOf(m.filePath)
+	if stamp == (fileStamp{}) || stamp == m.stamp {
+		return false, nil
+	}
+	return true, m.Load()
 }
 
 // AddItem adds a new task to the list and saves it.
-func (m *Manager) AddItem(title, description string) {
-	m.Tasks = append(m.Tasks, Task{Name: title, Detail: description})
-	m.Save() // Consider handling this error
+func (m *Manager) AddItem(title, description string) error {
+	return m.Add(title, description, nil)
+}
+
+// Add adds a new task to the list like AddItem, letting set fill in the
+// other fields first, so that the list is saved only once. set may be nil.
+func (m *Manager) Add(title, description string, set func(t *Task)) error {
+	// 新任务从一个尚未开始的工作会话开始，时长在开始计时时按设置确定
+	tm := TimeModel{IsWorkSession: true, SessionType: SessionWork}
+	t := Task{ID: m.newID(), Name: title, Detail: description, Timer: tm}
+	if set != nil {
+		set(&t)
+	}
+	m.Tasks = append(m.Tasks, t)
+	return m.Save()
+}
+
+// Index returns the position of the task with the given ID, or -1.
+func (m *Manager) Index(id string) int {
+	for i, t := range m.Tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the task with the given ID.
+func (m *Manager) Get(id string) (Task, bool) {
+	if i := m.Index(id); i >= 0 {
+		return m.Tasks[i], true
+	}
+	return Task{}, false
 }
 
-// DeleteItem deletes a task from the list and saves the changes.
-func (m *Manager) DeleteItem(index int) {
-	if index < 0 || index >= len(m.Tasks) {
-		return // Or return an error
+// Update replaces the task with the same ID as t and saves the changes.
+func (m *Manager) Update(t Task) error {
+	i := m.Index(t.ID)
+	if i < 0 {
+		return fmt.Errorf("task %s not found", t.ID)
 	}
-	m.Tasks = append(m.Tasks[:index], m.Tasks[index+1:]...)
-	m.Save() // Consider handling this error
+	m.Tasks[i] = t
+	return m.Save()
 }
 
-func (t TimeModel) View() string {
-	return t.ViewWithSettings(nil)
+// SetDone marks the task with the given ID as completed at now, or as not
+// completed, and saves the changes. Completing a recurring task adds its
+// next occurrence right after it; the ID is kept in the task's Next field.
+// Marking it not completed again removes that occurrence if it has not
+// been worked on.
+func (m *Manager) SetDone(id string, done bool, now time.Time) error {
+	i := m.Index(id)
+	if i < 0 {
+		return fmt.Errorf("task %s not found", id)
+	}
+	t := &m.Tasks[i]
+	t.Done = done
+	t.CompletedAt = time.Time{}
+	if done {
+		t.CompletedAt = now
+	}
+	switch {
+	case done && !t.Recur.IsZero() && m.Index(t.Next) < 0:
+		next := t.nextOccurrence(m.newID(), now)
+		t.Next = next.ID
+		m.Tasks = append(m.Tasks[:i+1], append([]Task{next}, m.Tasks[i+1:]...)...)
+	case !done && t.Next != "":
+		j := m.Index(t.Next)
+		t.Next = ""
+		if j >= 0 && m.Tasks[j].untouched() {
+			m.Tasks = append(m.Tasks[:j], m.Tasks[j+1:]...)
+		}
+	}
+	return m.Save()
 }
 
-func (t TimeModel) ViewWithSettings(settings *common.Settings) string {
-	min := t.TimerRemaining / 60
-	sec := t.TimerRemaining % 60
-	remainStr := fmt.Sprintf("%02d:%02d", min, sec)
+// Completed returns the completed tasks, most recently completed first.
+func (m *Manager) Completed() []Task {
+	var done []Task
+	for _, t := range m.Tasks {
+		if t.Done {
+			done = append(done, t)
+		}
+	}
+	sort.SliceStable(done, func(i, j int) bool {
+		return done[i].CompletedAt.After(done[j].CompletedAt)
+	})
+	return done
+}
 
-	// 根据设置选择时间显示方式
-	var timeDisplay string
-	if settings != nil && settings.TimeDisplayMode == "normal" {
-		timeDisplay = remainStr
-	} else {
-		timeDisplay = common.TimeToAnsiArt(remainStr)
+// PurgeCompleted moves all completed tasks to the trash and saves the
+// changes.
+func (m *Manager) PurgeCompleted() error {
+	ids := map[string]bool{}
+	for _, t := range m.Tasks {
+		if t.Done {
+			ids[t.ID] = true
+		}
 	}
+	return m.trash(ids, time.Now())
+}
 
-	status := "已暂停"
-	if t.TimerIsRunning {
-		status = "运行中"
+// Delete moves the task with the given ID to the trash and saves the
+// changes. Use Restore to bring it back.
+func (m *Manager) Delete(id string) error {
+	if m.Index(id) < 0 {
+		return fmt.Errorf("task %s not found", id)
 	}
-	controls := "[空格]开始/暂停  [r]重置  [q]返回"
-	if t.IsWorkSession {
-		return common.TitleStyle.Render("番茄钟计时器") + "\n\n" +
-			timeDisplay + "\n\n" +
-			"状态: " + status + "\n\n" +
-			common.StatusMessageStyle(controls) + "\n\n" +
-			"当前是工作时间，请专注！"
-	} else if !t.IsWorkSession && t.TimerIsRunning {
-		return common.TitleStyle.Render("番茄钟计时器") + "\n\n" +
-			timeDisplay + "\n\n" +
-			"状态: " + status + "\n\n" +
-			common.StatusMessageStyle(controls) + "\n\n" +
-			"当前是休息时间，请放松！"
-	} else {
-		return common.TitleStyle.Render("番茄钟计时器") + "\n\n" +
-			timeDisplay + "\n\n" +
-			"状态: " + status + "\n\n" +
-			common.StatusMessageStyle(controls) + "\n\n" +
-			"当前是休息时间，请放松！\n\n" +
-			"按 [空格] 开始计时，或按 [r] 重置计时器。"
+	return m.trash(map[string]bool{id: true}, time.Now())
+}
+
+// Move moves the task with the given ID to position to, shifting the tasks
+// in between, and saves the changes.
+func (m *Manager) Move(id string, to int) error {
+	i := m.Index(id)
+	if i < 0 {
+		return fmt.Errorf("task %s not found", id)
+	}
+	if to < 0 || to >= len(m.Tasks) {
+		return fmt.Errorf("task position %d out of range", to)
+	}
+	t := m.Tasks[i]
+	m.Tasks = append(m.Tasks[:i], m.Tasks[i+1:]...)
+	m.Tasks = append(m.Tasks[:to], append([]Task{t}, m.Tasks[to:]...)...)
+	return m.Save()
+}
+
+// StateTask returns the position of the task a saved timer state belongs
+// to, or -1 if it no longer exists. States saved before task IDs were
+// introduced are matched by position and title.
+func (m *Manager) StateTask(s ActiveState) int {
+	if s.TaskID != "" {
+		return m.Index(s.TaskID)
+	}
+	if s.TaskIndex >= 0 && s.TaskIndex < len(m.Tasks) && m.Tasks[s.TaskIndex].Name == s.TaskName {
+		return s.TaskIndex
+	}
+	return -1
+}
+
+// Find looks a task up by its ID, its 1-based position or its title,
+// ignoring case. Ambiguous titles are rejected.
+func (m *Manager) Find(ref string) (int, error) {
+	if i := m.Index(ref); i >= 0 && ref != "" {
+		return i, nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(m.Tasks) {
+			return -1, errors.New(i18n.T("task.noNumber", n))
+		}
+		return n - 1, nil
+	}
+	found := -1
+	for i, t := range m.Tasks {
+		if strings.EqualFold(t.Name, ref) {
+			if found >= 0 {
+				return -1, errors.New(i18n.T("task.ambiguous", ref))
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, errors.New(i18n.T("task.notFound", ref))
 	}
+	return found, nil
 }
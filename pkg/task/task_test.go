@@ -0,0 +1,262 @@
+package task
+
+import (
+	"gomato/pkg/safefile"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	return &Manager{filePath: filepath.Join(t.TempDir(), "tasks.json")}
+}
+
+func names(m *Manager) string {
+	var s []string
+	for _, t := range m.Tasks {
+		s = append(s, t.Name)
+	}
+	return strings.Join(s, ",")
+}
+
+// TestLoadMigratesIDs 测试旧版本没有 ID 的任务文件在读取时补上 ID 并写回
+func TestLoadMigratesIDs(t *testing.T) {
+	m := newTestManager(t)
+	old := `[{"title": "写报告", "description": ""}, {"id": "dup", "title": "读书"}, {"id": "dup", "title": "跑步"}]`
+	if err := os.WriteFile(m.filePath, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, task := range m.Tasks {
+		if task.ID == "" || seen[task.ID] {
+			t.Fatalf("迁移后 ID 应唯一且非空: %+v", m.Tasks)
+		}
+		seen[task.ID] = true
+	}
+	if m.Tasks[1].ID != "dup" {
+		t.Errorf("已有的 ID 不应改变: %q", m.Tasks[1].ID)
+	}
+
+	reloaded := &Manager{filePath: m.filePath}
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range m.Tasks {
+		if reloaded.Tasks[i].ID != m.Tasks[i].ID {
+			t.Errorf("迁移结果未写回文件: %+v", reloaded.Tasks)
+		}
+	}
+}
+
+func TestManagerByID(t *testing.T) {
+	m := newTestManager(t)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := m.AddItem(name, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b, c := m.Tasks[0].ID, m.Tasks[1].ID, m.Tasks[2].ID
+
+	if err := m.Move(a, 2); err != nil || names(m) != "b,c,a" {
+		t.Fatalf("Move(a, 2): %s, %v", names(m), err)
+	}
+	if err := m.Move(a, 0); err != nil || names(m) != "a,b,c" {
+		t.Fatalf("Move(a, 0): %s, %v", names(m), err)
+	}
+	if err := m.Move(a, 3); err == nil {
+		t.Error("expected error for position out of range")
+	}
+
+	task, ok := m.Get(b)
+	if !ok || task.Name != "b" {
+		t.Fatalf("Get(b) = %+v, %v", task, ok)
+	}
+	task.Name = "B"
+	if err := m.Update(task); err != nil || names(m) != "a,B,c" {
+		t.Fatalf("Update: %s, %v", names(m), err)
+	}
+
+	if err := m.Delete(b); err != nil || names(m) != "a,c" {
+		t.Fatalf("Delete: %s, %v", names(m), err)
+	}
+	if err := m.Delete(b); err == nil {
+		t.Error("expected error deleting a missing task")
+	}
+	if i, err := m.Find(c); err != nil || i != 1 {
+		t.Errorf("Find(id) = %d, %v", i, err)
+	}
+
+	// 状态快照优先按 ID 找回任务，旧快照按位置和标题
+	if i := m.StateTask(ActiveState{TaskID: c, TaskIndex: 0}); i != 1 {
+		t.Errorf("StateTask(id) = %d", i)
+	}
+	if i := m.StateTask(ActiveState{TaskID: b}); i != -1 {
+		t.Errorf("StateTask(deleted) = %d", i)
+	}
+	if i := m.StateTask(ActiveState{TaskIndex: 1, TaskName: "c"}); i != 1 {
+		t.Errorf("StateTask(legacy) = %d", i)
+	}
+	if i := m.StateTask(ActiveState{TaskIndex: 1, TaskName: "x"}); i != -1 {
+		t.Errorf("StateTask(legacy mismatch) = %d", i)
+	}
+}
+
+// TestSetDoneAndArchive 测试完成状态、归档判断和清除已完成任务
+func TestSetDoneAndArchive(t *testing.T) {
+	m := newTestManager(t)
+	for _, name := range []string{"写报告", "读书", "跑步"} {
+		m.AddItem(name, "")
+	}
+	now := time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local)
+	if err := m.SetDone(m.Tasks[0].ID, true, now.AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetDone(m.Tasks[2].ID, true, now); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Tasks[0].Archived(now) || m.Tasks[2].Archived(now) || m.Tasks[1].Archived(now) {
+		t.Errorf("归档判断错误: %+v", m.Tasks)
+	}
+	if done := m.Completed(); len(done) != 2 || done[0].Name != "跑步" || done[1].Name != "写报告" {
+		t.Errorf("已完成任务应按完成时间倒序: %+v", done)
+	}
+
+	if err := m.SetDone(m.Tasks[2].ID, false, now); err != nil {
+		t.Fatal(err)
+	}
+	if m.Tasks[2].Done || !m.Tasks[2].CompletedAt.IsZero() {
+		t.Errorf("恢复后仍为完成状态: %+v", m.Tasks[2])
+	}
+	if err := m.PurgeCompleted(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(m); got != "读书,跑步" {
+		t.Errorf("清除后剩余任务 %q", got)
+	}
+	if err := m.Load(); err != nil || names(m) != "读书,跑步" {
+		t.Errorf("清除结果未保存: %q, %v", names(m), err)
+	}
+}
+
+func TestPomodoroProgress(t *testing.T) {
+	cases := []struct {
+		task Task
+		want string
+		over bool
+	}{
+		{Task{}, "", false},
+		{Task{Estimate: 4, Pomodoros: 2}, "●●○○ 2/4", false},
+		{Task{Estimate: 2, Pomodoros: 3}, "●●● 3/2", true},
+		{Task{Pomodoros: 2}, "●● 2", false},
+		{Task{Estimate: 12, Pomodoros: 1}, "1/12", false},
+	}
+	for _, c := range cases {
+		if got := c.task.PomodoroProgress(); got != c.want {
+			t.Errorf("%+v: PomodoroProgress() = %q, want %q", c.task, got, c.want)
+		}
+		if got := c.task.OverEstimate(); got != c.over {
+			t.Errorf("%+v: OverEstimate() = %v, want %v", c.task, got, c.over)
+		}
+	}
+	if d := (Task{Detail: "周五前", Estimate: 1}).Description(); d != "○ 0/1  周五前" {
+		t.Errorf("Description() = %q", d)
+	}
+}
+
+func TestParsePriorityAndTags(t *testing.T) {
+	for in, want := range map[string]Priority{"": PriorityNone, "High": PriorityHigh, "m": PriorityMedium, "低": PriorityLow, "!!!": PriorityHigh} {
+		if p, err := ParsePriority(in); err != nil || p != want {
+			t.Errorf("ParsePriority(%q) = %v, %v, want %v", in, p, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected error for unknown priority")
+	}
+	if got := strings.Join(ParseTags("#写作, 紧急 写作，,#"), "|"); got != "写作|紧急" {
+		t.Errorf("ParseTags = %q", got)
+	}
+}
+
+// TestLabelsRoundTrip 测试项目、标签和优先级的保存与读取，以及筛选和显示
+func TestLabelsRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+	old := `[{"id": "a", "title": "写报告"}]`
+	if err := os.WriteFile(m.filePath, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	task := m.Tasks[0]
+	if task.Project != "" || task.Tags != nil || task.Priority != PriorityNone {
+		t.Fatalf("旧格式的任务应没有标签: %+v", task)
+	}
+
+	task.Project, task.Tags, task.Priority = "工作", []string{"写作"}, PriorityHigh
+	if err := m.Update(task); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(m.filePath)
+	if !strings.Contains(string(data), `"priority": "high"`) {
+		t.Errorf("优先级应以名称保存: %s", data)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	got := m.Tasks[0]
+	if got.Project != "工作" || len(got.Tags) != 1 || got.Priority != PriorityHigh {
+		t.Errorf("读取后的任务: %+v", got)
+	}
+	if fv := got.FilterValue(); !strings.HasPrefix(fv, "写报告") || !strings.Contains(fv, "@工作") || !strings.Contains(fv, "#写作") || !strings.Contains(fv, "high") {
+		t.Errorf("FilterValue() = %q", fv)
+	}
+	if d := got.Description(); d != "@工作 #写作 !!!" {
+		t.Errorf("Description() = %q", d)
+	}
+}
+
+func TestChecklistProgress(t *testing.T) {
+	task := Task{Checklist: []Subtask{{Title: "列提纲", Done: true}, {Title: "写初稿"}, {Title: "校对"}}}
+	if got := task.ChecklistProgress(); got != "☑ 1/3" {
+		t.Errorf("ChecklistProgress() = %q", got)
+	}
+	if open := task.OpenSubtasks(); len(open) != 2 || open[0] != 1 || open[1] != 2 {
+		t.Errorf("OpenSubtasks() = %v", open)
+	}
+	if got := (Task{}).ChecklistProgress(); got != "" {
+		t.Errorf("没有清单时应为空，实际 %q", got)
+	}
+}
+
+// TestAddSavesOnce 测试 Add 填好字段后只保存一次，不会留下缺少字段的备份
+func TestAddSavesOnce(t *testing.T) {
+	t.Setenv("GOMATO_HOME", t.TempDir())
+	defer func(interval time.Duration) { safefile.Interval = interval }(safefile.Interval)
+	safefile.Interval = 0
+
+	m, err := NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddItem("写报告", "")
+	if err := m.Add("读论文", "第三章", func(t *Task) { t.Project = "论文" }); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Tasks[1]; got.Name != "读论文" || got.Detail != "第三章" || got.Project != "论文" || got.ID == "" {
+		t.Errorf("added task = %+v", got)
+	}
+	backups, err := ListBackups("")
+	if err != nil || len(backups) != 1 || backups[0].Tasks != 1 {
+		t.Errorf("只应备份添加前的版本: %+v, %v", backups, err)
+	}
+	m, _ = NewManager("")
+	if len(m.Tasks) != 2 || m.Tasks[1].Project != "论文" {
+		t.Errorf("saved tasks = %+v", m.Tasks)
+	}
+}
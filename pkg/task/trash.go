@@ -0,0 +1,158 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"gomato/pkg/safefile"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// TrashedTask 是回收站中的任务，保存在 tasks.json 旁的 trash.json 中
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time `json:"deletedAt"`
+	Position  int       `json:"position"` // 删除前在任务列表中的位置，恢复时放回原处
+}
+
+// MarshalJSON 保存任务和删除信息。内嵌的 Task 有自己的 MarshalJSON，
+// 不写这个方法时 deletedAt 和 position 会被丢掉
+func (t TrashedTask) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		taskJSON
+		DeletedAt time.Time `json:"deletedAt"`
+		Position  int       `json:"position"`
+	}{t.Task.toJSON(), t.DeletedAt, t.Position})
+}
+
+// trashPath 返回回收站文件的路径，没有任务文件时为空
+func (m *Manager) trashPath() string {
+	if m.filePath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(m.filePath), "trash.json")
+}
+
+// LoadTrash reads the deleted tasks from the trash file. A missing file
+// means the trash is empty. A damaged trash file is set aside as
+// trash.json.corrupt-<time> and the trash starts out empty, so that the
+// tasks themselves can still be opened.
+func (m *Manager) LoadTrash() error {
+	data, err := os.ReadFile(m.trashPath())
+	if os.IsNotExist(err) {
+		m.Trash = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var trash []TrashedTask
+	if err := json.Unmarshal(data, &trash); err != nil {
+		m.Trash = nil
+		_, err := safefile.SetAside(m.trashPath())
+		return err
+	}
+	m.Trash = trash
+	return nil
+}
+
+// saveTrash 保存回收站
+func (m *Manager) saveTrash() error {
+	data, err := json.MarshalIndent(m.Trash, "", "  ")
+	if err != nil {
+		return err
+	}
+	return safefile.WriteFile(m.trashPath(), data, 0644)
+}
+
+// trash 把任务移到回收站。先保存回收站再保存任务列表，中途出错时文件中的任务不会丢失
+func (m *Manager) trash(ids map[string]bool, now time.Time) error {
+	kept := make([]Task, 0, len(m.Tasks))
+	for i, t := range m.Tasks {
+		if ids[t.ID] {
+			m.Trash = append(m.Trash, TrashedTask{Task: t, DeletedAt: now, Position: i})
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	m.Tasks = kept
+	if err := m.saveTrash(); err != nil {
+		return err
+	}
+	return m.Save()
+}
+
+// TrashIndex returns the position of the task with the given ID in the
+// trash, or -1.
+func (m *Manager) TrashIndex(id string) int {
+	for i, t := range m.Trash {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Trashed returns the deleted tasks, most recently deleted first.
+func (m *Manager) Trashed() []TrashedTask {
+	trashed := append([]TrashedTask(nil), m.Trash...)
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+	return trashed
+}
+
+// Restore moves the task with the given ID out of the trash, back to the
+// position it was deleted from, and saves the changes.
+func (m *Manager) Restore(id string) error {
+	i := m.TrashIndex(id)
+	if i < 0 {
+		return fmt.Errorf("task %s not in trash", id)
+	}
+	t := m.Trash[i]
+	pos := t.Position
+	if pos < 0 || pos > len(m.Tasks) {
+		pos = len(m.Tasks)
+	}
+	m.Tasks = append(m.Tasks[:pos], append([]Task{t.Task}, m.Tasks[pos:]...)...)
+	m.Trash = append(m.Trash[:i], m.Trash[i+1:]...)
+	if err := m.Save(); err != nil {
+		return err
+	}
+	return m.saveTrash()
+}
+
+// Purge permanently deletes the task with the given ID from the trash.
+func (m *Manager) Purge(id string) error {
+	i := m.TrashIndex(id)
+	if i < 0 {
+		return fmt.Errorf("task %s not in trash", id)
+	}
+	m.Trash = append(m.Trash[:i], m.Trash[i+1:]...)
+	return m.saveTrash()
+}
+
+// EmptyTrash permanently deletes all tasks in the trash.
+func (m *Manager) EmptyTrash() error {
+	m.Trash = nil
+	return m.saveTrash()
+}
+
+// PurgeTrash permanently deletes the tasks that were moved to the trash
+// before the given time, and returns how many were deleted.
+func (m *Manager) PurgeTrash(before time.Time) (int, error) {
+	kept := m.Trash[:0]
+	for _, t := range m.Trash {
+		if !t.DeletedAt.Before(before) {
+			kept = append(kept, t)
+		}
+	}
+	n := len(m.Trash) - len(kept)
+	m.Trash = kept
+	if n == 0 {
+		return 0, nil
+	}
+	return n, m.saveTrash()
+}
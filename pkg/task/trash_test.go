@@ -0,0 +1,55 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTrash 测试删除的任务进入回收站、保存到文件、恢复到原位置以及按保留期限清除
+func TestTrash(t *testing.T) {
+	m := newTestManager(t)
+	for _, name := range []string{"写报告", "读书", "跑步"} {
+		m.AddItem(name, "")
+	}
+	read, run := m.Tasks[1].ID, m.Tasks[2].ID
+	if err := m.Delete(read); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(run); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(m); got != "写报告" {
+		t.Fatalf("删除后剩余任务 %q", got)
+	}
+
+	reloaded := &Manager{filePath: m.filePath}
+	if err := reloaded.LoadTrash(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Trash) != 2 || reloaded.Trash[0].Name != "读书" || reloaded.Trash[0].DeletedAt.IsZero() {
+		t.Fatalf("回收站: %+v", reloaded.Trash)
+	}
+
+	if err := m.Restore(read); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(m); got != "写报告,读书" || m.TrashIndex(read) >= 0 {
+		t.Errorf("恢复后: %q, 回收站 %+v", got, m.Trash)
+	}
+	if err := m.Restore(read); err == nil {
+		t.Error("恢复不在回收站中的任务应返回错误")
+	}
+
+	// 只清除保留期限之前删除的任务
+	m.Trash[0].DeletedAt = time.Now().AddDate(0, 0, -40)
+	m.Delete(read)
+	if n, err := m.PurgeTrash(time.Now().AddDate(0, 0, -30)); err != nil || n != 1 {
+		t.Fatalf("PurgeTrash = %d, %v", n, err)
+	}
+	if len(m.Trash) != 1 || m.Trash[0].ID != read {
+		t.Errorf("清除后的回收站: %+v", m.Trash)
+	}
+	if err := m.EmptyTrash(); err != nil || len(m.Trash) != 0 {
+		t.Errorf("清空回收站: %+v, %v", m.Trash, err)
+	}
+}
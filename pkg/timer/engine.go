@@ -0,0 +1,261 @@
+package timer
+
+import "time"
+
+// EventType 表示状态机发出的事件类型
+type EventType int
+
+const (
+	SessionStarted   EventType = iota // 会话开始（手动开始或自动进入下一会话）
+	SessionCompleted                  // 会话按时结束
+	Paused
+	Resumed
+	Skipped // 会话被跳过，直接进入下一会话
+	Reset   // 会话被重置为尚未开始的工作会话
+	Warning // 会话即将结束，见 Config.Warning
+)
+
+func (t EventType) String() string {
+	switch t {
+	case SessionStarted:
+		return "started"
+	case SessionCompleted:
+		return "completed"
+	case Paused:
+		return "paused"
+	case Resumed:
+		return "resumed"
+	case Skipped:
+		return "skipped"
+	case Reset:
+		return "reset"
+	case Warning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 是状态机发出的事件
+type Event struct {
+	Type    EventType
+	Session Session // 事件所涉及的会话
+	Next    Kind    // 会话结束或跳过后进入的会话类型
+	Cycle   int     // 事件发生后的周期计数
+	Time    time.Time
+}
+
+// Engine 是番茄钟状态机。它不是并发安全的，调用方需自行串行化访问。
+type Engine struct {
+	cfg       Config
+	clock     Clock
+	s         Snapshot
+	listeners []func(Event)
+}
+
+// New creates an engine with an idle work session. A nil clock uses the
+// system clock.
+func New(cfg Config, clock Clock) *Engine {
+	if clock == nil {
+		clock = SystemClock()
+	}
+	e := &Engine{cfg: cfg, clock: clock}
+	e.s = Snapshot{Kind: Work, Planned: cfg.Work, Remaining: cfg.Work}
+	return e
+}
+
+// Subscribe registers fn to be called synchronously for every event.
+func (e *Engine) Subscribe(fn func(Event)) {
+	e.listeners = append(e.listeners, fn)
+}
+
+func (e *Engine) emit(t EventType, session Session, next Kind) {
+	ev := Event{Type: t, Session: session, Next: next, Cycle: e.s.Cycle, Time: e.clock.Now()}
+	for _, fn := range e.listeners {
+		fn(ev)
+	}
+}
+
+// Config returns the current configuration.
+func (e *Engine) Config() Config { return e.cfg }
+
+// Snapshot returns the complete current state.
+func (e *Engine) Snapshot() Snapshot { return e.s }
+
+// Kind returns the type of the current session.
+func (e *Engine) Kind() Kind { return e.s.Kind }
+
+// State returns whether the current session is idle, running or paused.
+func (e *Engine) State() State { return e.s.State }
+
+// Cycle returns the number of work sessions finished in the current cycle.
+func (e *Engine) Cycle() int { return e.s.Cycle }
+
+// Remaining returns the time left in the current session.
+func (e *Engine) Remaining() time.Duration {
+	return e.s.RemainingAt(e.clock.Now())
+}
+
+// Restore replaces the engine state with a previously taken snapshot.
+// No events are emitted.
+func (e *Engine) Restore(s Snapshot) {
+	if s.Kind == "" {
+		s.Kind = Work
+	}
+	e.s = s
+}
+
+// SetConfig changes the configuration. An idle session takes the new
+// duration; a started session is shortened if it now exceeds it.
+func (e *Engine) SetConfig(cfg Config) {
+	e.cfg = cfg
+	e.s = cfg.Apply(e.s, e.clock.Now())
+}
+
+// Apply adjusts a snapshot to the durations in cfg, following the same
+// rules as Engine.SetConfig.
+func (c Config) Apply(s Snapshot, now time.Time) Snapshot {
+	d := c.Duration(s.Kind)
+	s.Planned = d
+	switch s.State {
+	case StateIdle:
+		s.Remaining = d
+	case StateRunning, StatePaused:
+		if s.RemainingAt(now) > d {
+			ref := now
+			if s.State == StatePaused {
+				ref = s.PausedAt
+			}
+			s.Deadline = ref.Add(d)
+		}
+	}
+	return s
+}
+
+// Start starts the current session, or resumes it if paused.
+func (e *Engine) Start() {
+	now := e.clock.Now()
+	switch e.s.State {
+	case StateRunning:
+		return
+	case StatePaused:
+		e.s.Paused += now.Sub(e.s.PausedAt)
+		e.s.Deadline = e.s.Deadline.Add(now.Sub(e.s.PausedAt))
+		e.s.PausedAt = time.Time{}
+		e.s.State = StateRunning
+		e.emit(Resumed, e.session(time.Time{}), "")
+	default:
+		e.s.StartedAt = now
+		e.s.Deadline = now.Add(e.s.Remaining)
+		e.s.Paused = 0
+		e.s.State = StateRunning
+		e.emit(SessionStarted, e.session(time.Time{}), "")
+	}
+}
+
+// Pause freezes the countdown of a running session.
+func (e *Engine) Pause() {
+	if e.s.State != StateRunning {
+		return
+	}
+	e.s.PausedAt = e.clock.Now()
+	e.s.State = StatePaused
+	e.emit(Paused, e.session(time.Time{}), "")
+}
+
+// Toggle pauses a running session and starts or resumes any other.
+func (e *Engine) Toggle() {
+	if e.s.State == StateRunning {
+		e.Pause()
+	} else {
+		e.Start()
+	}
+}
+
+// Tick checks the clock and completes the current session once its
+// deadline has passed. It should be called periodically while running;
+// a late tick still ends the session at its deadline.
+func (e *Engine) Tick() {
+	if e.s.State != StateRunning {
+		return
+	}
+	now := e.clock.Now()
+	if !now.Before(e.s.Deadline) {
+		e.finish(SessionCompleted, e.s.Deadline, true)
+		return
+	}
+	if w := e.cfg.Warning; w > 0 && !e.s.Warned && e.s.Planned > w && e.s.Deadline.Sub(now) <= w {
+		e.s.Warned = true
+		e.emit(Warning, e.session(time.Time{}), "")
+	}
+}
+
+// Skip ends the current session early and moves on to the next one. The
+// next session runs if the skipped one was running. A skipped work session
+// does not count toward the cycle, so it is always followed by a short
+// break.
+func (e *Engine) Skip() {
+	e.finish(Skipped, e.endTime(), e.s.State == StateRunning)
+}
+
+// Reset abandons the current session and returns to an idle work session.
+// The cycle count is kept.
+func (e *Engine) Reset() {
+	session := e.session(e.endTime())
+	started := e.s.State != StateIdle
+	e.s = Snapshot{Kind: Work, Planned: e.cfg.Work, Remaining: e.cfg.Work, Cycle: e.s.Cycle}
+	if started {
+		e.emit(Reset, session, Work)
+	}
+}
+
+// endTime returns when the current session ends if stopped now: never
+// later than its deadline.
+func (e *Engine) endTime() time.Time {
+	now := e.clock.Now()
+	if e.s.State == StateRunning && e.s.Deadline.Before(now) {
+		return e.s.Deadline
+	}
+	return now
+}
+
+// session describes the current session, ending at end.
+func (e *Engine) session(end time.Time) Session {
+	paused := e.s.Paused
+	if e.s.State == StatePaused && !end.IsZero() {
+		paused += end.Sub(e.s.PausedAt)
+	}
+	return Session{
+		Kind:    e.s.Kind,
+		Planned: e.s.Planned,
+		Start:   e.s.StartedAt,
+		End:     end,
+		Paused:  paused,
+	}
+}
+
+// finish ends the current session and advances to the next one according
+// to the cycle rules.
+func (e *Engine) finish(t EventType, end time.Time, run bool) {
+	session := e.session(end)
+	next := Work
+	if e.s.Kind == Work {
+		next = ShortBreak
+		// 只有完成的番茄计入周期，跳过的工作不会提前换来长休息
+		if t != Skipped {
+			e.s.Cycle++
+			if e.s.Cycle >= e.cfg.Cycle {
+				next = LongBreak
+				e.s.Cycle = 0
+			}
+		}
+	}
+
+	d := e.cfg.Duration(next)
+	e.s = Snapshot{Kind: next, Planned: d, Remaining: d, Cycle: e.s.Cycle}
+	e.emit(t, session, next)
+
+	if run && (t == Skipped || e.cfg.AutoStart) {
+		e.Start()
+	}
+}
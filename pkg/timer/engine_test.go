@@ -0,0 +1,243 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock 是可手动拨动的时钟
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestEngine(autoStart bool) (*Engine, *fakeClock, *[]Event) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	e := New(Config{
+		Work:       25 * time.Minute,
+		ShortBreak: 5 * time.Minute,
+		LongBreak:  15 * time.Minute,
+		Cycle:      2,
+		AutoStart:  autoStart,
+	}, clock)
+	var events []Event
+	e.Subscribe(func(ev Event) { events = append(events, ev) })
+	return e, clock, &events
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+	}
+	return types
+}
+
+func TestEngineCycle(t *testing.T) {
+	e, clock, events := newTestEngine(true)
+	e.Start()
+
+	// 工作 → 短休息 → 工作 → 长休息 → 工作
+	want := []Kind{ShortBreak, Work, LongBreak, Work}
+	for i, next := range want {
+		clock.Advance(e.Remaining())
+		e.Tick()
+		if e.Kind() != next {
+			t.Fatalf("第%d次结束后期望进入%s，实际%s", i+1, next, e.Kind())
+		}
+		if e.State() != StateRunning {
+			t.Fatalf("自动开始时下一会话应处于运行状态")
+		}
+	}
+	if e.Cycle() != 0 {
+		t.Errorf("长休息后cycle计数应归零，实际%d", e.Cycle())
+	}
+
+	completed := 0
+	for _, ev := range *events {
+		if ev.Type == SessionCompleted {
+			completed++
+			if ev.Session.Actual() != ev.Session.Planned {
+				t.Errorf("会话实际时长%v与计划时长%v不一致", ev.Session.Actual(), ev.Session.Planned)
+			}
+		}
+	}
+	if completed != len(want) {
+		t.Errorf("期望%d个完成事件，实际%d个", len(want), completed)
+	}
+}
+
+func TestEnginePauseResume(t *testing.T) {
+	e, clock, events := newTestEngine(true)
+	e.Start()
+	clock.Advance(10 * time.Minute)
+	e.Pause()
+	clock.Advance(time.Hour)
+	if e.Remaining() != 15*time.Minute {
+		t.Fatalf("暂停期间剩余时间应保持15分钟，实际%v", e.Remaining())
+	}
+	e.Toggle()
+	clock.Advance(15*time.Minute - time.Second)
+	e.Tick()
+	if e.Kind() != Work {
+		t.Fatalf("截止时间未到不应结束会话")
+	}
+	clock.Advance(time.Second)
+	e.Tick()
+
+	got := eventTypes(*events)
+	wantTypes := []EventType{SessionStarted, Paused, Resumed, SessionCompleted, SessionStarted}
+	if len(got) != len(wantTypes) {
+		t.Fatalf("期望事件%v，实际%v", wantTypes, got)
+	}
+	for i := range wantTypes {
+		if got[i] != wantTypes[i] {
+			t.Fatalf("期望事件%v，实际%v", wantTypes, got)
+		}
+	}
+	if paused := (*events)[3].Session.Paused; paused != time.Hour {
+		t.Errorf("期望记录暂停1小时，实际%v", paused)
+	}
+}
+
+func TestEngineLateTick(t *testing.T) {
+	e, clock, events := newTestEngine(false)
+	e.Start()
+	deadline := e.Snapshot().Deadline
+
+	// 挂起两小时后才收到 tick，会话仍按截止时间结束
+	clock.Advance(2 * time.Hour)
+	e.Tick()
+	last := (*events)[len(*events)-1]
+	if last.Type != SessionCompleted || !last.Session.End.Equal(deadline) {
+		t.Fatalf("期望会话在截止时间结束，实际事件: %+v", last)
+	}
+	if e.State() != StateIdle || e.Kind() != ShortBreak {
+		t.Errorf("未开启自动开始时应停在未开始的短休息，实际%s/%s", e.Kind(), e.State())
+	}
+}
+
+func TestEngineSkipAndReset(t *testing.T) {
+	e, clock, events := newTestEngine(true)
+	e.Start()
+	clock.Advance(5 * time.Minute)
+	e.Skip()
+	if e.Kind() != ShortBreak || e.State() != StateRunning || e.Cycle() != 0 {
+		t.Fatalf("跳过工作后应开始短休息且不计入cycle，实际%s/%s cycle=%d", e.Kind(), e.State(), e.Cycle())
+	}
+	clock.Advance(time.Minute)
+	e.Reset()
+	if e.Kind() != Work || e.State() != StateIdle || e.Remaining() != 25*time.Minute {
+		t.Fatalf("重置后应回到未开始的工作会话")
+	}
+
+	got := eventTypes(*events)
+	wantTypes := []EventType{SessionStarted, Skipped, SessionStarted, Reset}
+	for i := range wantTypes {
+		if i >= len(got) || got[i] != wantTypes[i] {
+			t.Fatalf("期望事件%v，实际%v", wantTypes, got)
+		}
+	}
+	if (*events)[1].Session.Actual() != 5*time.Minute {
+		t.Errorf("跳过的会话实际时长应为5分钟")
+	}
+
+	// 未开始的会话重置不产生事件
+	n := len(*events)
+	e.Reset()
+	if len(*events) != n {
+		t.Errorf("重置未开始的会话不应产生事件")
+	}
+}
+
+// TestEngineSkipWorkKeepsCycle 测试跳过的工作不计入cycle，长休息只由完成的番茄换来
+func TestEngineSkipWorkKeepsCycle(t *testing.T) {
+	e, clock, _ := newTestEngine(true)
+	e.Start()
+	// 完成第一个番茄并休息，回到工作
+	for i := 0; i < 2; i++ {
+		clock.Advance(e.Remaining())
+		e.Tick()
+	}
+	if e.Kind() != Work || e.Cycle() != 1 {
+		t.Fatalf("期望第二个番茄，实际%s cycle=%d", e.Kind(), e.Cycle())
+	}
+
+	// cycle 为 2，完成这个番茄本该进入长休息，跳过则只是短休息
+	e.Skip()
+	if e.Kind() != ShortBreak || e.Cycle() != 1 {
+		t.Fatalf("跳过工作后应进入短休息且cycle不变，实际%s cycle=%d", e.Kind(), e.Cycle())
+	}
+	e.Reset()
+	if e.Cycle() != 1 {
+		t.Errorf("重置不应清空cycle计数")
+	}
+	e.Start()
+	clock.Advance(e.Remaining())
+	e.Tick()
+	if e.Kind() != LongBreak || e.Cycle() != 0 {
+		t.Errorf("完成第二个番茄后应进入长休息，实际%s cycle=%d", e.Kind(), e.Cycle())
+	}
+}
+
+func TestEngineSetConfigAndRestore(t *testing.T) {
+	e, clock, _ := newTestEngine(true)
+	cfg := e.Config()
+	cfg.Work = 50 * time.Minute
+	e.SetConfig(cfg)
+	if e.Remaining() != 50*time.Minute {
+		t.Fatalf("未开始的会话应采用新时长，实际%v", e.Remaining())
+	}
+
+	e.Start()
+	clock.Advance(10 * time.Minute)
+	cfg.Work = 20 * time.Minute
+	e.SetConfig(cfg)
+	if e.Remaining() != 20*time.Minute {
+		t.Errorf("新时长更短时应缩短剩余时间，实际%v", e.Remaining())
+	}
+
+	snap := e.Snapshot()
+	other, _, _ := newTestEngine(true)
+	other.clock = clock
+	other.Restore(snap)
+	if other.Remaining() != e.Remaining() || other.State() != StateRunning {
+		t.Errorf("恢复快照后状态不一致")
+	}
+}
+
+func TestEngineWarning(t *testing.T) {
+	e, clock, events := newTestEngine(true)
+	cfg := e.Config()
+	cfg.Warning = 5 * time.Minute
+	e.SetConfig(cfg)
+	e.Start()
+
+	clock.Advance(19 * time.Minute)
+	e.Tick()
+	clock.Advance(time.Minute) // 剩余5分钟
+	e.Tick()
+	clock.Advance(time.Minute)
+	e.Tick()
+
+	warnings := 0
+	for _, ev := range *events {
+		if ev.Type == Warning {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("期望提醒1次，实际%d次: %v", warnings, eventTypes(*events))
+	}
+
+	// 5分钟的短休息不超过提醒时长，不提醒
+	clock.Advance(e.Remaining())
+	e.Tick()
+	clock.Advance(time.Minute)
+	e.Tick()
+	if n := len(*events); (*events)[n-1].Type == Warning {
+		t.Errorf("短休息不应提醒: %v", eventTypes(*events))
+	}
+}
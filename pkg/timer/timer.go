@@ -0,0 +1,119 @@
+// Package timer 实现与界面无关的番茄钟状态机。
+//
+// Engine 负责工作 → 短休息 → 长休息的切换、周期计数以及暂停/继续，
+// 剩余时间始终由截止时间和注入的时钟计算。界面、守护进程或命令行
+// 通过 Subscribe 订阅事件来记录历史、发送通知或刷新显示。
+package timer
+
+import "time"
+
+// Kind 表示会话类型，取值与 task.SessionType 相同
+type Kind string
+
+const (
+	Work       Kind = "work"
+	ShortBreak Kind = "shortBreak"
+	LongBreak  Kind = "longBreak"
+)
+
+// State 表示计时器的运行状态
+type State int
+
+const (
+	StateIdle State = iota // 当前会话尚未开始
+	StateRunning
+	StatePaused
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StatePaused:
+		return "paused"
+	default:
+		return "idle"
+	}
+}
+
+// Clock 提供当前时间，测试中可替换为可手动拨动的时钟
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock 返回使用 time.Now 的时钟
+func SystemClock() Clock { return systemClock{} }
+
+// Config 是状态机的时长与周期配置
+type Config struct {
+	Work       time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	Cycle      int  // 进入长休息前的工作会话数
+	AutoStart  bool // 会话结束后是否自动开始下一个会话
+
+	// Warning 大于零时，在会话结束前这么久发出一次 Warning 事件。
+	// 计划时长不超过 Warning 的会话不提醒。
+	Warning time.Duration
+}
+
+// Duration returns the configured length of a session of the given kind.
+func (c Config) Duration(k Kind) time.Duration {
+	switch k {
+	case ShortBreak:
+		return c.ShortBreak
+	case LongBreak:
+		return c.LongBreak
+	default:
+		return c.Work
+	}
+}
+
+// Snapshot 是状态机在某一时刻的完整状态，可用于持久化和恢复
+type Snapshot struct {
+	Kind      Kind
+	State     State
+	Planned   time.Duration // 当前会话的计划时长
+	Remaining time.Duration // 未运行时的剩余时间
+	StartedAt time.Time     // 会话开始时间，StateIdle 时为零值
+	PausedAt  time.Time     // 暂停开始时间，仅 StatePaused 时有效
+	Paused    time.Duration // 已累计的暂停时长
+	Warned    bool          // 本会话是否已发出结束前提醒
+	Deadline  time.Time     // 会话结束时间，暂停期间会顺延
+	Cycle     int           // 当前周期内已结束的工作会话数
+}
+
+// RemainingAt returns the time left in the session at now.
+func (s Snapshot) RemainingAt(now time.Time) time.Duration {
+	switch s.State {
+	case StateIdle:
+		return s.Remaining
+	case StatePaused:
+		now = s.PausedAt
+	}
+	if r := s.Deadline.Sub(now); r > 0 {
+		return r
+	}
+	return 0
+}
+
+// Session 描述一次会话，用于事件中
+type Session struct {
+	Kind    Kind
+	Planned time.Duration
+	Start   time.Time
+	End     time.Time // 仅在会话结束的事件中有效
+	Paused  time.Duration
+}
+
+// Actual returns the time actually spent in the session, excluding pauses.
+func (s Session) Actual() time.Duration {
+	if d := s.End.Sub(s.Start) - s.Paused; d > 0 {
+		return d
+	}
+	return 0
+}